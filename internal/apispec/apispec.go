@@ -0,0 +1,484 @@
+// Package apispec finds exposed API descriptions on a web host — OpenAPI 3,
+// Swagger 2 (JSON or YAML) and Postman collections — and turns them into an
+// endpoint inventory: method, URL, parameters and the auth schemes each
+// endpoint requires.
+package apispec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Paths are the spec locations tried on each host.
+var Paths = []string{
+	"/swagger.json", "/swagger.yaml", "/swagger/v1/swagger.json", "/swagger/doc.json",
+	"/api/swagger.json", "/api/swagger.yaml", "/api/v1/swagger.json",
+	"/openapi.json", "/openapi.yaml", "/openapi.yml", "/api/openapi.json", "/api/openapi.yaml",
+	"/docs/openapi.json", "/.well-known/openapi.json",
+	"/v2/api-docs", "/v3/api-docs", "/api-docs", "/api-docs/swagger.json", "/api/v2/api-docs",
+	"/postman_collection.json", "/collection.json", "/api/postman_collection.json",
+	"/postman/collection.json",
+}
+
+// Spec formats.
+const (
+	OpenAPI = "openapi"
+	Swagger = "swagger"
+	Postman = "postman"
+)
+
+const maxBody = 10 * 1024 * 1024
+
+// Spec is one parsed API description.
+type Spec struct {
+	URL       string
+	Format    string // OpenAPI, Swagger or Postman
+	Version   string // spec version ("3.0.1", "2.0", Postman schema)
+	Title     string
+	Endpoints []Endpoint
+}
+
+// Endpoint is one operation of a spec.
+type Endpoint struct {
+	Method string
+	Path   string   // as written in the spec ("/users/{id}")
+	URL    string   // absolute, path parameters filled with "1"
+	Params []string // "in:name" (query:page, path:id, header:X-Tenant, body)
+	Auth   []string // schemes required ("bearer", "apiKey:header:X-API-Key"); empty = none
+}
+
+// Unauthenticated reports whether the spec declares no auth for e.
+func (e Endpoint) Unauthenticated() bool { return len(e.Auth) == 0 }
+
+// Discover requests every Paths entry on base (scheme://host[:port]) and
+// returns the specs that parse, one per distinct document.
+func Discover(client *http.Client, base string) []Spec {
+	base = strings.TrimRight(base, "/")
+	var specs []Spec
+	seen := map[string]bool{}
+	for _, p := range Paths {
+		u := base + p
+		body, err := get(client, u)
+		if err != nil {
+			continue
+		}
+		spec, err := Parse(u, body)
+		if err != nil {
+			continue
+		}
+		// /api-docs and /v2/api-docs often serve the same document.
+		key := spec.Format + "|" + spec.Title + "|" + fmt.Sprint(len(spec.Endpoints))
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		specs = append(specs, *spec)
+	}
+	return specs
+}
+
+// Parse parses an OpenAPI, Swagger or Postman document (JSON or YAML)
+// fetched from specURL, which resolves relative server URLs.
+func Parse(specURL string, body []byte) (*Spec, error) {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return nil, fmt.Errorf("empty document")
+	}
+	if body[0] != '{' {
+		// YAML: decode generically and re-encode as JSON so one set of
+		// structs covers both.
+		var doc any
+		if err := yaml.Unmarshal(body, &doc); err != nil {
+			return nil, err
+		}
+		js, err := json.Marshal(doc)
+		if err != nil {
+			return nil, err
+		}
+		body = js
+	}
+
+	var probe struct {
+		OpenAPI string `json:"openapi"`
+		Swagger string `json:"swagger"`
+		Info    struct {
+			Title  string `json:"title"`
+			Name   string `json:"name"`   // Postman
+			Schema string `json:"schema"` // Postman
+		} `json:"info"`
+		Item []json.RawMessage `json:"item"`
+	}
+	if err := json.Unmarshal(body, &probe); err != nil {
+		return nil, err
+	}
+	base, err := url.Parse(specURL)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := &Spec{URL: specURL}
+	switch {
+	case probe.OpenAPI != "":
+		spec.Format, spec.Version, spec.Title = OpenAPI, probe.OpenAPI, probe.Info.Title
+		err = parseOpenAPI(spec, base, body)
+	case probe.Swagger != "":
+		spec.Format, spec.Version, spec.Title = Swagger, probe.Swagger, probe.Info.Title
+		err = parseOpenAPI(spec, base, body)
+	case strings.Contains(probe.Info.Schema, "getpostman.com") || (probe.Info.Name != "" && len(probe.Item) > 0):
+		spec.Format, spec.Version, spec.Title = Postman, probe.Info.Schema, probe.Info.Name
+		err = parsePostman(spec, base, body)
+	default:
+		return nil, fmt.Errorf("not an API description")
+	}
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(spec.Endpoints, func(i, j int) bool {
+		if spec.Endpoints[i].Path != spec.Endpoints[j].Path {
+			return spec.Endpoints[i].Path < spec.Endpoints[j].Path
+		}
+		return spec.Endpoints[i].Method < spec.Endpoints[j].Method
+	})
+	return spec, nil
+}
+
+// ── OpenAPI 3 / Swagger 2 ────────────────────────────────────────────────
+
+type oaParam struct {
+	Name string `json:"name"`
+	In   string `json:"in"`
+	Ref  string `json:"$ref"`
+}
+
+type oaOperation struct {
+	Parameters  []oaParam              `json:"parameters"`
+	RequestBody json.RawMessage        `json:"requestBody"`
+	Security    *[]map[string][]string `json:"security"` // nil = inherit, [] = none
+}
+
+type oaScheme struct {
+	Type   string `json:"type"`   // http, apiKey, oauth2, openIdConnect (3); basic, apiKey, oauth2 (2)
+	Scheme string `json:"scheme"` // bearer, basic (3, type http)
+	In     string `json:"in"`
+	Name   string `json:"name"`
+}
+
+type oaDoc struct {
+	// OpenAPI 3
+	Servers []struct {
+		URL string `json:"url"`
+	} `json:"servers"`
+	Components struct {
+		SecuritySchemes map[string]oaScheme `json:"securitySchemes"`
+		Parameters      map[string]oaParam  `json:"parameters"`
+	} `json:"components"`
+	// Swagger 2
+	Host                string              `json:"host"`
+	BasePath            string              `json:"basePath"`
+	Schemes             []string            `json:"schemes"`
+	SecurityDefinitions map[string]oaScheme `json:"securityDefinitions"`
+	Parameters          map[string]oaParam  `json:"parameters"`
+
+	Security []map[string][]string                 `json:"security"`
+	Paths    map[string]map[string]json.RawMessage `json:"paths"`
+}
+
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+func parseOpenAPI(spec *Spec, base *url.URL, body []byte) error {
+	var doc oaDoc
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return err
+	}
+
+	schemes := doc.Components.SecuritySchemes
+	refs := doc.Components.Parameters
+	if spec.Format == Swagger {
+		schemes, refs = doc.SecurityDefinitions, doc.Parameters
+	}
+	apiBase := serverBase(spec.Format, doc, base)
+
+	for path, item := range doc.Paths {
+		var shared []oaParam
+		if raw, ok := item["parameters"]; ok {
+			_ = json.Unmarshal(raw, &shared)
+		}
+		for _, m := range methods {
+			raw, ok := item[m]
+			if !ok {
+				continue
+			}
+			var op oaOperation
+			if json.Unmarshal(raw, &op) != nil {
+				continue
+			}
+			e := Endpoint{Method: strings.ToUpper(m), Path: path, URL: joinURL(apiBase, path)}
+			for _, p := range append(append([]oaParam{}, shared...), op.Parameters...) {
+				if p.Ref != "" {
+					p = refs[p.Ref[strings.LastIndex(p.Ref, "/")+1:]]
+				}
+				if p.Name == "" {
+					continue
+				}
+				e.Params = appendUnique(e.Params, p.In+":"+p.Name)
+			}
+			if len(op.RequestBody) > 0 {
+				e.Params = appendUnique(e.Params, "body")
+			}
+			requirements := doc.Security
+			if op.Security != nil {
+				requirements = *op.Security
+			}
+			e.Auth = authOf(requirements, schemes)
+			spec.Endpoints = append(spec.Endpoints, e)
+		}
+	}
+	return nil
+}
+
+// serverBase returns the absolute API root: the first OpenAPI server (or
+// Swagger host + basePath), resolved against the spec URL.
+func serverBase(format string, doc oaDoc, spec *url.URL) string {
+	root := spec.Scheme + "://" + spec.Host
+	if format == Swagger {
+		host := doc.Host
+		if host == "" {
+			host = spec.Host
+		}
+		scheme := spec.Scheme
+		if len(doc.Schemes) > 0 && !contains(doc.Schemes, scheme) {
+			scheme = doc.Schemes[0]
+		}
+		return strings.TrimRight(scheme+"://"+host+doc.BasePath, "/")
+	}
+	if len(doc.Servers) == 0 || doc.Servers[0].URL == "" {
+		return root
+	}
+	// Server variables ("https://{region}.api.example.com") cannot be
+	// resolved; fall back to the host serving the spec.
+	server := doc.Servers[0].URL
+	if strings.Contains(server, "{") {
+		return root
+	}
+	u, err := spec.Parse(server)
+	if err != nil {
+		return root
+	}
+	return strings.TrimRight(u.String(), "/")
+}
+
+// authOf lists the schemes of a security requirement set. Any requirement
+// that is empty ({}) makes auth optional, which counts as none.
+func authOf(requirements []map[string][]string, schemes map[string]oaScheme) []string {
+	var out []string
+	for _, req := range requirements {
+		if len(req) == 0 {
+			return nil
+		}
+		for name := range req {
+			out = appendUnique(out, describe(name, schemes[name]))
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func describe(name string, s oaScheme) string {
+	switch strings.ToLower(s.Type) {
+	case "http":
+		return strings.ToLower(s.Scheme)
+	case "basic":
+		return "basic"
+	case "apikey":
+		return "apiKey:" + s.In + ":" + s.Name
+	case "oauth2", "openidconnect":
+		return strings.ToLower(s.Type)
+	}
+	return name
+}
+
+// ── Postman ──────────────────────────────────────────────────────────────
+
+type pmAuth struct {
+	Type string `json:"type"`
+}
+
+type pmItem struct {
+	Name    string          `json:"name"`
+	Item    []pmItem        `json:"item"`
+	Auth    *pmAuth         `json:"auth"`
+	Request json.RawMessage `json:"request"`
+}
+
+type pmRequest struct {
+	Method string          `json:"method"`
+	URL    json.RawMessage `json:"url"`
+	Auth   *pmAuth         `json:"auth"`
+	Header []struct {
+		Key string `json:"key"`
+	} `json:"header"`
+	Body *struct {
+		Mode string `json:"mode"`
+	} `json:"body"`
+}
+
+type pmURL struct {
+	Raw   string `json:"raw"`
+	Query []struct {
+		Key string `json:"key"`
+	} `json:"query"`
+}
+
+// pmVar matches Postman variables ({{baseUrl}}).
+var pmVar = regexp.MustCompile(`\{\{[^}]*\}\}`)
+
+func parsePostman(spec *Spec, base *url.URL, body []byte) error {
+	var doc struct {
+		Item []pmItem `json:"item"`
+		Auth *pmAuth  `json:"auth"`
+	}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return err
+	}
+	var walk func(items []pmItem, auth *pmAuth)
+	walk = func(items []pmItem, auth *pmAuth) {
+		for _, it := range items {
+			a := auth
+			if it.Auth != nil {
+				a = it.Auth
+			}
+			if len(it.Item) > 0 {
+				walk(it.Item, a)
+				continue
+			}
+			if e, ok := postmanEndpoint(it.Request, a, base); ok {
+				spec.Endpoints = append(spec.Endpoints, e)
+			}
+		}
+	}
+	walk(doc.Item, doc.Auth)
+	return nil
+}
+
+func postmanEndpoint(raw json.RawMessage, auth *pmAuth, base *url.URL) (Endpoint, bool) {
+	var req pmRequest
+	if len(raw) == 0 {
+		return Endpoint{}, false
+	}
+	if raw[0] == '"' {
+		// Short form: the request is just its URL.
+		var s string
+		_ = json.Unmarshal(raw, &s)
+		req.URL, _ = json.Marshal(s)
+	} else if json.Unmarshal(raw, &req) != nil {
+		return Endpoint{}, false
+	}
+
+	var pu pmURL
+	if len(req.URL) > 0 && req.URL[0] == '"' {
+		_ = json.Unmarshal(req.URL, &pu.Raw)
+	} else {
+		_ = json.Unmarshal(req.URL, &pu)
+	}
+	if pu.Raw == "" {
+		return Endpoint{}, false
+	}
+
+	// Variables are unknown here: {{baseUrl}}/users → the spec's host.
+	target := pmVar.ReplaceAllString(pu.Raw, "")
+	u, err := url.Parse(target)
+	if err != nil {
+		return Endpoint{}, false
+	}
+	if u.Host == "" {
+		if !strings.HasPrefix(u.Path, "/") {
+			u.Path = "/" + u.Path
+		}
+		u = base.ResolveReference(&url.URL{Path: u.Path, RawQuery: u.RawQuery})
+	}
+
+	method := strings.ToUpper(req.Method)
+	if method == "" {
+		method = "GET"
+	}
+	e := Endpoint{Method: method, Path: u.Path, URL: u.Scheme + "://" + u.Host + u.Path}
+	for _, q := range pu.Query {
+		e.Params = appendUnique(e.Params, "query:"+q.Key)
+	}
+	for k := range u.Query() {
+		e.Params = appendUnique(e.Params, "query:"+k)
+	}
+	for _, h := range req.Header {
+		e.Params = appendUnique(e.Params, "header:"+h.Key)
+	}
+	if req.Body != nil && req.Body.Mode != "" {
+		e.Params = appendUnique(e.Params, "body")
+	}
+	if req.Auth != nil {
+		auth = req.Auth
+	}
+	if auth != nil && auth.Type != "" && auth.Type != "noauth" {
+		e.Auth = []string{auth.Type}
+	}
+	return e, true
+}
+
+// ── Helpers ──────────────────────────────────────────────────────────────
+
+// pathParam matches OpenAPI path templates ({id}).
+var pathParam = regexp.MustCompile(`\{[^}/]+\}`)
+
+// joinURL appends a spec path to the API root, filling path parameters so
+// the URL can be requested.
+func joinURL(root, path string) string {
+	return root + "/" + strings.TrimLeft(pathParam.ReplaceAllString(path, "1"), "/")
+}
+
+func get(client *http.Client, u string) ([]byte, error) {
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
+	req.Header.Set("Accept", "application/json, application/yaml, */*")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	// HTML (Swagger UI page, SPA fallback) is never a spec.
+	if ct := resp.Header.Get("Content-Type"); strings.Contains(ct, "text/html") {
+		return nil, fmt.Errorf("html response")
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxBody))
+}
+
+func appendUnique(list []string, v string) []string {
+	for _, s := range list {
+		if s == v {
+			return list
+		}
+	}
+	return append(list, v)
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,400 @@
+// Package auth holds the session the active workflows scan with: static
+// headers and cookies, a bearer token, or cookies obtained by a scripted form
+// login (GET the login page, lift the CSRF token, POST the credentials).
+// The session is scoped per host: it is only sent to hosts matching its host
+// patterns that are also in scope, so credentials never reach third-party
+// domains, CDNs or redirect targets.
+package auth
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/FOUEN/narmol/internal/scope"
+)
+
+// Options configures the session. The zero value scans unauthenticated.
+type Options struct {
+	Headers []string // "Name: value"
+	Cookies string   // "a=1; b=2"
+	Bearer  string   // sent as "Authorization: Bearer <token>"
+
+	// LoginURL, when set, runs a form login before the scan: the page is
+	// fetched, its CSRF token (CSRFField, or a well-known name when empty)
+	// is added to LoginData (urlencoded) and the form is POSTed back. The
+	// cookies it sets join the session.
+	LoginURL  string
+	LoginData string
+	CSRFField string
+
+	// Hosts limits the session to these hosts ("app.example.com",
+	// "*.example.com" also matching example.com). Empty = the login host,
+	// or the scanned domain and its subdomains.
+	Hosts []string
+}
+
+// Enabled reports whether any credential is configured.
+func (o Options) Enabled() bool {
+	return len(o.Headers) > 0 || o.Cookies != "" || o.Bearer != "" || o.LoginURL != ""
+}
+
+// Validate checks header syntax, the login URL and the host patterns.
+func (o Options) Validate() error {
+	for _, h := range o.Headers {
+		name, _, ok := strings.Cut(h, ":")
+		if !ok || strings.TrimSpace(name) == "" || strings.ContainsAny(strings.TrimSpace(name), " \t") {
+			return fmt.Errorf("invalid header %q: use \"Name: value\"", h)
+		}
+	}
+	if o.LoginURL != "" {
+		u, err := url.Parse(o.LoginURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid login URL %q", o.LoginURL)
+		}
+		if _, err := url.ParseQuery(o.LoginData); err != nil {
+			return fmt.Errorf("invalid login data: %w", err)
+		}
+	} else if o.LoginData != "" || o.CSRFField != "" {
+		return fmt.Errorf("login data needs a login URL")
+	}
+	for _, h := range o.Hosts {
+		if strings.TrimPrefix(h, "*.") == "" || strings.ContainsAny(h, "/: ") {
+			return fmt.Errorf("invalid auth host %q", h)
+		}
+	}
+	return nil
+}
+
+// Session is a resolved set of credentials bound to its hosts. A nil
+// *Session is valid and applies to nothing.
+type Session struct {
+	headers [][2]string
+	hosts   []string
+	s       *scope.Scope
+}
+
+// csrfNames are tried in order when no CSRF field is given.
+var csrfNames = []string{"csrf_token", "csrfmiddlewaretoken", "_csrf", "_token", "authenticity_token", "csrf", "__RequestVerificationToken", "xsrf_token", "_csrf_token"}
+
+var (
+	inputTag  = regexp.MustCompile(`(?is)<input\b[^>]*>`)
+	metaTag   = regexp.MustCompile(`(?is)<meta\b[^>]*>`)
+	attrValue = regexp.MustCompile(`(?is)\b(name|value|content)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))`)
+)
+
+// New resolves o for domain: runs the login when configured and binds the
+// session to its hosts. It returns nil when o is not enabled.
+func New(o Options, domain string, s *scope.Scope) (*Session, error) {
+	if !o.Enabled() {
+		return nil, nil
+	}
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+
+	sess := &Session{s: s}
+	for _, h := range o.Headers {
+		name, value, _ := strings.Cut(h, ":")
+		sess.headers = append(sess.headers, [2]string{http.CanonicalHeaderKey(strings.TrimSpace(name)), strings.TrimSpace(value)})
+	}
+	if o.Bearer != "" {
+		sess.headers = append(sess.headers, [2]string{"Authorization", "Bearer " + o.Bearer})
+	}
+
+	cookies := strings.TrimSpace(o.Cookies)
+	if o.LoginURL != "" {
+		got, err := login(o)
+		if err != nil {
+			return nil, err
+		}
+		cookies = joinCookies(cookies, got)
+	}
+	if cookies != "" {
+		sess.headers = append(sess.headers, [2]string{"Cookie", cookies})
+	}
+
+	for _, h := range o.Hosts {
+		sess.hosts = append(sess.hosts, strings.ToLower(strings.TrimSpace(h)))
+	}
+	if len(sess.hosts) == 0 {
+		if o.LoginURL != "" {
+			u, _ := url.Parse(o.LoginURL)
+			sess.hosts = []string{strings.ToLower(u.Hostname())}
+		} else {
+			sess.hosts = []string{"*." + strings.ToLower(domain)}
+		}
+	}
+	return sess, nil
+}
+
+// login performs the form login and returns the cookies it obtained. The
+// login counts as successful only when the POST sets a new cookie or changes
+// one (session fixation protection, or a fresh session cookie).
+func login(o Options) (string, error) {
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{
+		Timeout: 15 * time.Second,
+		Jar:     jar,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+	loginURL, _ := url.Parse(o.LoginURL)
+
+	// Seed the jar with the static cookies: some logins need them.
+	for _, c := range strings.Split(o.Cookies, ";") {
+		if name, value, ok := strings.Cut(strings.TrimSpace(c), "="); ok {
+			jar.SetCookies(loginURL, []*http.Cookie{{Name: name, Value: value}})
+		}
+	}
+
+	req, _ := http.NewRequest("GET", o.LoginURL, nil)
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("login page: %w", err)
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	resp.Body.Close()
+
+	form, _ := url.ParseQuery(o.LoginData)
+	field, token := csrfToken(string(body), o.CSRFField)
+	if o.CSRFField != "" && token == "" {
+		return "", fmt.Errorf("login page has no CSRF field %q", o.CSRFField)
+	}
+	if token != "" && form.Get(field) == "" {
+		form.Set(field, token)
+	}
+
+	// Cookies before the POST: a failed login that re-renders the form
+	// still leaves the pre-auth cookies in the jar.
+	before := map[string]string{}
+	for _, c := range jar.Cookies(loginURL) {
+		before[c.Name] = c.Value
+	}
+
+	req, _ = http.NewRequest("POST", o.LoginURL, strings.NewReader(form.Encode()))
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Referer", o.LoginURL)
+	if token != "" {
+		req.Header.Set("X-CSRF-Token", token)
+	}
+	resp, err = client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("login: %w", err)
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+	resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return "", fmt.Errorf("login failed: HTTP %d", resp.StatusCode)
+	}
+
+	var parts []string
+	changed := false
+	for _, c := range jar.Cookies(loginURL) {
+		parts = append(parts, c.Name+"="+c.Value)
+		if v, ok := before[c.Name]; !ok || v != c.Value {
+			changed = true
+		}
+	}
+	if !changed {
+		return "", fmt.Errorf("login failed: no new or changed cookie after the POST (wrong credentials?)")
+	}
+	return strings.Join(parts, "; "), nil
+}
+
+// csrfToken finds the CSRF token in a login page: a hidden input named
+// field (or one of csrfNames), else a <meta name="csrf-token"> tag.
+func csrfToken(page, field string) (string, string) {
+	names := csrfNames
+	if field != "" {
+		names = []string{field}
+	}
+	inputs := map[string]string{}
+	for _, tag := range inputTag.FindAllString(page, -1) {
+		attrs := attributes(tag)
+		if name, ok := attrs["name"]; ok {
+			inputs[name] = attrs["value"]
+		}
+	}
+	for _, n := range names {
+		if v, ok := inputs[n]; ok && v != "" {
+			return n, v
+		}
+	}
+	if field != "" {
+		return field, ""
+	}
+	for _, tag := range metaTag.FindAllString(page, -1) {
+		attrs := attributes(tag)
+		if n := strings.ToLower(attrs["name"]); n == "csrf-token" || n == "_csrf" || n == "csrf_token" {
+			if attrs["content"] != "" {
+				return "_csrf", attrs["content"]
+			}
+		}
+	}
+	return "", ""
+}
+
+func attributes(tag string) map[string]string {
+	attrs := map[string]string{}
+	for _, m := range attrValue.FindAllStringSubmatch(tag, -1) {
+		attrs[strings.ToLower(m[1])] = m[2] + m[3] + m[4]
+	}
+	return attrs
+}
+
+// joinCookies merges two Cookie header values, b winning on name clashes.
+func joinCookies(a, b string) string {
+	seen := map[string]bool{}
+	var out []string
+	for _, part := range strings.Split(b+";"+a, ";") {
+		part = strings.TrimSpace(part)
+		name, _, _ := strings.Cut(part, "=")
+		if part == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		out = append(out, part)
+	}
+	return strings.Join(out, "; ")
+}
+
+// Applies reports whether the session is sent to target (a host or URL):
+// it must match one of the session hosts and be in scope.
+func (sess *Session) Applies(target string) bool {
+	if sess == nil {
+		return false
+	}
+	host := target
+	if u, err := url.Parse(target); err == nil && u.Host != "" {
+		host = u.Hostname()
+	} else if h, _, ok := strings.Cut(target, ":"); ok {
+		host = h
+	}
+	host = strings.ToLower(host)
+	if sess.s != nil && !sess.s.IsInScope(host) {
+		return false
+	}
+	for _, p := range sess.hosts {
+		if root, ok := strings.CutPrefix(p, "*."); ok {
+			if host == root || strings.HasSuffix(host, "."+root) {
+				return true
+			}
+		} else if host == p {
+			return true
+		}
+	}
+	return false
+}
+
+// Split separates targets the session applies to from the rest.
+func (sess *Session) Split(targets []string) (authed, other []string) {
+	for _, t := range targets {
+		if sess.Applies(t) {
+			authed = append(authed, t)
+		} else {
+			other = append(other, t)
+		}
+	}
+	return authed, other
+}
+
+// HeaderLines returns the session as "Name: value" lines, the format
+// katana, httpx and nuclei take.
+func (sess *Session) HeaderLines() []string {
+	if sess == nil {
+		return nil
+	}
+	lines := make([]string, len(sess.headers))
+	for i, h := range sess.headers {
+		lines[i] = h[0] + ": " + h[1]
+	}
+	return lines
+}
+
+// HeaderNames returns the names of the session headers, for evidence
+// redaction.
+func (sess *Session) HeaderNames() []string {
+	if sess == nil {
+		return nil
+	}
+	names := make([]string, len(sess.headers))
+	for i, h := range sess.headers {
+		names[i] = h[0]
+	}
+	return names
+}
+
+// ScopeRegex returns URL regexes matching the session hosts, to keep an
+// authenticated katana crawl on them.
+func (sess *Session) ScopeRegex() []string {
+	if sess == nil {
+		return nil
+	}
+	var out []string
+	for _, p := range sess.hosts {
+		if root, ok := strings.CutPrefix(p, "*."); ok {
+			out = append(out, `^https?://([^/?#]+\.)?`+regexp.QuoteMeta(root)+`(:[0-9]+)?([/?#]|$)`)
+		} else {
+			out = append(out, `^https?://`+regexp.QuoteMeta(p)+`(:[0-9]+)?([/?#]|$)`)
+		}
+	}
+	return out
+}
+
+// Apply sets the session headers on req when the session applies to its
+// host. Headers already set on req are kept.
+func (sess *Session) Apply(req *http.Request) {
+	if !sess.Applies(req.URL.String()) {
+		return
+	}
+	for _, h := range sess.headers {
+		if req.Header.Get(h[0]) == "" {
+			req.Header.Set(h[0], h[1])
+		}
+	}
+}
+
+// Transport wraps base so that every request, redirects included, carries
+// the session only when it goes to a session host. A nil session returns
+// base unchanged.
+func (sess *Session) Transport(base http.RoundTripper) http.RoundTripper {
+	if sess == nil {
+		return base
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{base: base, sess: sess}
+}
+
+type transport struct {
+	base http.RoundTripper
+	sess *Session
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.sess.Applies(req.URL.String()) {
+		return t.base.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	t.sess.Apply(req)
+	return t.base.RoundTrip(req)
+}
+
+// String describes the session for the console, without secrets.
+func (sess *Session) String() string {
+	if sess == nil {
+		return "none"
+	}
+	return fmt.Sprintf("%s on %s", strings.Join(sess.HeaderNames(), ", "), strings.Join(sess.hosts, ", "))
+}
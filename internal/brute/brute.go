@@ -0,0 +1,309 @@
+// Package brute resolves candidate subdomains with the dnsx library:
+// wordlist labels under wildcard scope roots (Run) and alterations of names
+// already discovered (Permutations). Parents that answer for any name
+// (wildcard DNS) are detected first, and candidates resolving only to the
+// wildcard answers are discarded.
+package brute
+
+import (
+	"bufio"
+	"crypto/rand"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/FOUEN/narmol/internal/scope"
+
+	"github.com/projectdiscovery/dnsx/libs/dnsx"
+)
+
+const (
+	// DefaultThreads is the number of concurrent lookups.
+	DefaultThreads = 50
+	// DefaultRateLimit caps queries per second across all workers.
+	DefaultRateLimit = 200
+
+	// wildcardProbes is how many random names are resolved per parent to
+	// learn its wildcard answers.
+	wildcardProbes = 5
+)
+
+//go:embed wordlist.txt
+var defaultWordlist string
+
+// Options tunes a brute-force run. Zero values use the defaults.
+type Options struct {
+	Wordlist  string   // one label per line; empty = built-in list
+	Resolvers []string // dnsx resolvers ("1.1.1.1", "udp:8.8.8.8:53"); empty = dnsx defaults
+	RateLimit int      // queries per second
+	Threads   int
+}
+
+// Result is a candidate that resolved to something other than the
+// wildcard answers of its parent domain.
+type Result struct {
+	Subdomain string
+	IPs       []string
+}
+
+// Stats summarises a run.
+type Stats struct {
+	Candidates int
+	Found      int64
+	Wildcard   int64 // answers dropped because they match the parent's wildcard
+	OutOfScope int
+}
+
+// NewClient returns a dnsx client for A lookups using resolvers (dnsx
+// defaults when empty). The hosts file is ignored so local entries never
+// show up as discovered subdomains.
+func NewClient(resolvers []string) (*dnsx.DNSX, error) {
+	opts := dnsx.DefaultOptions
+	opts.MaxRetries = 2
+	opts.Hostsfile = false
+	if len(resolvers) > 0 {
+		opts.BaseResolvers = resolvers
+	}
+	client, err := dnsx.New(opts)
+	if err != nil {
+		return nil, fmt.Errorf("could not create dnsx client: %w", err)
+	}
+	return client, nil
+}
+
+// ParseResolvers reads resolvers from a file (one per line) or, when value
+// is not a file, from a comma-separated list.
+func ParseResolvers(value string) ([]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if info, err := os.Stat(value); err == nil && !info.IsDir() {
+		return readLines(value)
+	}
+	var resolvers []string
+	for _, r := range strings.Split(value, ",") {
+		if r = strings.TrimSpace(r); r != "" {
+			resolvers = append(resolvers, r)
+		}
+	}
+	return resolvers, nil
+}
+
+// LoadWordlist returns the labels in file, or the built-in list when file
+// is empty. Labels are lowercased and deduplicated.
+func LoadWordlist(file string) ([]string, error) {
+	var lines []string
+	if file == "" {
+		lines = strings.Split(defaultWordlist, "\n")
+	} else {
+		var err error
+		if lines, err = readLines(file); err != nil {
+			return nil, err
+		}
+	}
+
+	seen := map[string]bool{}
+	var words []string
+	for _, line := range lines {
+		w := strings.ToLower(strings.Trim(strings.TrimSpace(line), "."))
+		if w == "" || strings.HasPrefix(w, "#") || strings.ContainsAny(w, " \t*/:") || seen[w] {
+			continue
+		}
+		seen[w] = true
+		words = append(words, w)
+	}
+	return words, nil
+}
+
+func readLines(file string) ([]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("could not open %s: %w", file, err)
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", file, err)
+	}
+	return lines, nil
+}
+
+// Run brute-forces every word under every root, calling found for each
+// in-scope name that resolves to non-wildcard addresses. found may be
+// called concurrently.
+func Run(roots []string, s *scope.Scope, opts Options, found func(Result)) (Stats, error) {
+	words, err := LoadWordlist(opts.Wordlist)
+	if err != nil {
+		return Stats{}, err
+	}
+
+	var candidates []string
+	outOfScope := 0
+	for _, root := range roots {
+		for _, word := range words {
+			host := word + "." + root
+			if !s.IsInScope(host) {
+				outOfScope++
+				continue
+			}
+			candidates = append(candidates, host)
+		}
+	}
+
+	stats, err := Resolve(candidates, opts, found)
+	stats.OutOfScope = outOfScope
+	return stats, err
+}
+
+// Resolve looks up every candidate (rate limited), drops names answering
+// only with their parent's wildcard addresses and calls found for the rest.
+// Candidates must already be scope-checked. found may be called
+// concurrently.
+func Resolve(candidates []string, opts Options, found func(Result)) (Stats, error) {
+	stats := Stats{Candidates: len(candidates)}
+	if len(candidates) == 0 {
+		return stats, nil
+	}
+
+	client, err := NewClient(opts.Resolvers)
+	if err != nil {
+		return stats, err
+	}
+
+	threads := opts.Threads
+	if threads <= 0 {
+		threads = DefaultThreads
+	}
+	rate := opts.RateLimit
+	if rate <= 0 {
+		rate = DefaultRateLimit
+	}
+
+	ticker := time.NewTicker(time.Second / time.Duration(rate))
+	defer ticker.Stop()
+
+	wildcards := &wildcardCache{client: client, entries: map[string]*wildcardEntry{}}
+	// Fixed pool of workers: candidate lists reach millions of names.
+	work := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < threads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for host := range work {
+				<-ticker.C
+
+				ips, err := client.Lookup(host)
+				if err != nil || len(ips) == 0 {
+					continue
+				}
+				if matchesWildcard(ips, wildcards.get(parent(host))) {
+					atomic.AddInt64(&stats.Wildcard, 1)
+					continue
+				}
+				atomic.AddInt64(&stats.Found, 1)
+				found(Result{Subdomain: host, IPs: ips})
+			}
+		}()
+	}
+
+	for _, host := range candidates {
+		work <- host
+	}
+	close(work)
+	wg.Wait()
+	return stats, nil
+}
+
+// wildcardCache detects wildcard DNS once per parent domain.
+type wildcardCache struct {
+	client  *dnsx.DNSX
+	mu      sync.Mutex
+	entries map[string]*wildcardEntry
+}
+
+type wildcardEntry struct {
+	once sync.Once
+	ips  map[string]bool
+}
+
+func (c *wildcardCache) get(domain string) map[string]bool {
+	c.mu.Lock()
+	e, ok := c.entries[domain]
+	if !ok {
+		e = &wildcardEntry{}
+		c.entries[domain] = e
+	}
+	c.mu.Unlock()
+
+	e.once.Do(func() {
+		e.ips = Wildcard(c.client, domain)
+		if len(e.ips) > 0 {
+			fmt.Printf("[!] Wildcard DNS on *.%s (%s) — filtering matching answers\n", domain, strings.Join(keys(e.ips), ", "))
+		}
+	})
+	return e.ips
+}
+
+func parent(host string) string {
+	if _, rest, ok := strings.Cut(host, "."); ok {
+		return rest
+	}
+	return host
+}
+
+// Wildcard resolves random names under root and returns every address they
+// answered with. An empty set means root has no wildcard record.
+func Wildcard(client *dnsx.DNSX, root string) map[string]bool {
+	ips := map[string]bool{}
+	for i := 0; i < wildcardProbes; i++ {
+		answers, err := client.Lookup(randomLabel() + "." + root)
+		if err != nil {
+			continue
+		}
+		for _, ip := range answers {
+			ips[ip] = true
+		}
+	}
+	return ips
+}
+
+// matchesWildcard reports whether every address in ips is a wildcard answer.
+func matchesWildcard(ips []string, wildcard map[string]bool) bool {
+	if len(wildcard) == 0 {
+		return false
+	}
+	for _, ip := range ips {
+		if !wildcard[ip] {
+			return false
+		}
+	}
+	return true
+}
+
+func randomLabel() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return "narmol-" + hex.EncodeToString(b)
+}
+
+func keys(m map[string]bool) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	return out
+}
@@ -0,0 +1,236 @@
+package brute
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/FOUEN/narmol/internal/scope"
+)
+
+// DefaultMaxPermutations caps how many candidates Permutations generates.
+const DefaultMaxPermutations = 5000
+
+// maxLearned bounds how many tokens learned from discovered labels are added
+// to the built-in alteration words.
+const maxLearned = 30
+
+// alterationWords are environment and role tokens commonly spliced into
+// subdomain names.
+var alterationWords = []string{
+	"dev", "staging", "stage", "test", "qa", "uat", "prod", "preprod",
+	"beta", "internal", "admin", "api", "old", "new", "v2", "backup",
+}
+
+var (
+	numberRe = regexp.MustCompile(`\d+`)
+	labelRe  = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+)
+
+// Permutations generates alterations of the known subdomains under roots,
+// cheapest and most productive first:
+//  1. number increments   api2 → api1, api3; api → api1, api2
+//  2. token swaps         dev-api → staging-api
+//  3. dash joins          api → dev-api, api-dev
+//  4. dot joins           api.example.com → dev.api.example.com, api.dev.example.com
+//
+// Words come from a built-in list plus tokens learned from the known labels.
+// Candidates are scope-checked, never repeat a known name and stop at max
+// (DefaultMaxPermutations when <= 0).
+func Permutations(known []string, roots []string, s *scope.Scope, max int) []string {
+	if max <= 0 {
+		max = DefaultMaxPermutations
+	}
+
+	knownSet := map[string]bool{}
+	type name struct{ first, suffix string }
+	var names []name
+	for _, host := range known {
+		host = strings.ToLower(strings.TrimSpace(host))
+		if knownSet[host] {
+			continue
+		}
+		knownSet[host] = true
+		root := rootOf(host, roots)
+		if root == "" || host == root {
+			continue
+		}
+		first, suffix, _ := strings.Cut(host, ".")
+		names = append(names, name{first, suffix})
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return names[i].first+"."+names[i].suffix < names[j].first+"."+names[j].suffix
+	})
+
+	words := append([]string{}, alterationWords...)
+	wordSet := map[string]bool{}
+	for _, w := range words {
+		wordSet[w] = true
+	}
+	for _, t := range learnTokens(known, roots) {
+		if !wordSet[t] {
+			wordSet[t] = true
+			words = append(words, t)
+		}
+	}
+
+	seen := map[string]bool{}
+	var out []string
+	// add records a candidate and reports whether there is room for more.
+	add := func(label, suffix string) bool {
+		if !labelRe.MatchString(label) {
+			return true
+		}
+		host := label + "." + suffix
+		if seen[host] || knownSet[host] || !s.IsInScope(host) {
+			return true
+		}
+		seen[host] = true
+		out = append(out, host)
+		return len(out) < max
+	}
+
+	tiers := []func(n name) bool{
+		// 1. Number increments
+		func(n name) bool {
+			for _, label := range renumber(n.first) {
+				if !add(label, n.suffix) {
+					return false
+				}
+			}
+			return true
+		},
+		// 2. Token swaps
+		func(n name) bool {
+			tokens := strings.Split(n.first, "-")
+			if len(tokens) < 2 {
+				return true // a lone word swapped for another is plain brute-force
+			}
+			for i, t := range tokens {
+				if !wordSet[t] {
+					continue
+				}
+				for _, w := range words {
+					if hasToken(tokens, w) {
+						continue
+					}
+					swapped := append([]string{}, tokens...)
+					swapped[i] = w
+					if !add(strings.Join(swapped, "-"), n.suffix) {
+						return false
+					}
+				}
+			}
+			return true
+		},
+		// 3. Dash joins
+		func(n name) bool {
+			tokens := strings.Split(n.first, "-")
+			for _, w := range words {
+				if hasToken(tokens, w) {
+					continue
+				}
+				if !add(w+"-"+n.first, n.suffix) || !add(n.first+"-"+w, n.suffix) {
+					return false
+				}
+			}
+			return true
+		},
+		// 4. Dot joins: a new level above the name, or between it and its parent
+		func(n name) bool {
+			for _, w := range words {
+				if !add(w, n.first+"."+n.suffix) || !add(n.first, w+"."+n.suffix) {
+					return false
+				}
+			}
+			return true
+		},
+	}
+
+	for _, tier := range tiers {
+		for _, n := range names {
+			if !tier(n) {
+				return out
+			}
+		}
+	}
+	return out
+}
+
+func hasToken(tokens []string, w string) bool {
+	for _, t := range tokens {
+		if t == w {
+			return true
+		}
+	}
+	return false
+}
+
+// renumber returns the label with its last number moved one up and one
+// down, or with 1 and 2 appended when it has no number.
+func renumber(label string) []string {
+	locs := numberRe.FindAllStringIndex(label, -1)
+	if len(locs) == 0 {
+		return []string{label + "1", label + "2"}
+	}
+	loc := locs[len(locs)-1]
+	n, err := strconv.Atoi(label[loc[0]:loc[1]])
+	if err != nil {
+		return nil
+	}
+	var out []string
+	for _, m := range []int{n + 1, n - 1} {
+		if m >= 0 {
+			out = append(out, label[:loc[0]]+strconv.Itoa(m)+label[loc[1]:])
+		}
+	}
+	return out
+}
+
+// learnTokens returns the most frequent alphabetic tokens found in the
+// labels of known names below their root (e.g. "payments", "eu" from
+// "payments-eu.api.example.com").
+func learnTokens(known []string, roots []string) []string {
+	counts := map[string]int{}
+	for _, host := range known {
+		host = strings.ToLower(host)
+		root := rootOf(host, roots)
+		if root == "" || host == root {
+			continue
+		}
+		sub := strings.TrimSuffix(host, "."+root)
+		for _, t := range strings.FieldsFunc(sub, func(r rune) bool { return r == '.' || r == '-' }) {
+			t = strings.Trim(numberRe.ReplaceAllString(t, ""), "-")
+			if len(t) >= 2 {
+				counts[t]++
+			}
+		}
+	}
+
+	tokens := make([]string, 0, len(counts))
+	for t := range counts {
+		tokens = append(tokens, t)
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		if counts[tokens[i]] != counts[tokens[j]] {
+			return counts[tokens[i]] > counts[tokens[j]]
+		}
+		return tokens[i] < tokens[j]
+	})
+	if len(tokens) > maxLearned {
+		tokens = tokens[:maxLearned]
+	}
+	return tokens
+}
+
+// rootOf returns the longest root that host belongs to, or "".
+func rootOf(host string, roots []string) string {
+	best := ""
+	for _, r := range roots {
+		if (host == r || strings.HasSuffix(host, "."+r)) && len(r) > len(best) {
+			best = r
+		}
+	}
+	return best
+}
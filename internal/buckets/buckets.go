@@ -0,0 +1,345 @@
+// Package buckets checks cloud storage buckets for existence and anonymous
+// access. S3, GCS and DigitalOcean Spaces speak the S3 XML listing API;
+// Azure Blob is checked per storage account and container. Endpoints are
+// URL templates, so any S3/Azure-compatible stand-in (MinIO, Azurite) can
+// replace the public services.
+package buckets
+
+import (
+	"crypto/tls"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/FOUEN/narmol/internal/evidence"
+)
+
+// Providers, in the order they are checked.
+const (
+	S3     = "s3"
+	GCS    = "gcs"
+	Azure  = "azure"
+	Spaces = "spaces"
+)
+
+// Providers lists every supported provider.
+var Providers = []string{S3, GCS, Azure, Spaces}
+
+// DefaultEndpoints are the public endpoint templates. {bucket} is replaced
+// by the bucket (Azure: storage account) name and {region} by each of
+// SpacesRegions.
+var DefaultEndpoints = map[string]string{
+	S3:     "https://{bucket}.s3.amazonaws.com",
+	GCS:    "https://storage.googleapis.com/{bucket}",
+	Azure:  "https://{bucket}.blob.core.windows.net",
+	Spaces: "https://{bucket}.{region}.digitaloceanspaces.com",
+}
+
+// SpacesRegions are the DigitalOcean regions a Space can live in.
+var SpacesRegions = []string{"nyc3", "sfo3", "ams3", "sgp1", "fra1", "syd1", "blr1"}
+
+// AzureContainers are the container names tried on each existing storage
+// account: anonymous access is granted per container.
+var AzureContainers = []string{"$web", "public", "assets", "static", "images", "media", "uploads", "files", "data", "backup", "backups", "logs", "content", "documents"}
+
+// readProbes are object names tried when a bucket exists but cannot be
+// listed, to detect public-read without a listing.
+var readProbes = []string{"index.html", "robots.txt", "favicon.ico"}
+
+// Access levels, from most to least exposed.
+const (
+	AccessList    = "public-list" // anonymous listing
+	AccessRead    = "public-read" // objects readable, listing denied
+	AccessPrivate = "private"     // exists, no anonymous access
+)
+
+const maxBody = 1024 * 1024
+
+var (
+	s3NameRe    = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+	gcsNameRe   = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{1,61}[a-z0-9]$`)
+	azureNameRe = regexp.MustCompile(`^[a-z0-9]{3,24}$`)
+)
+
+// Valid reports whether name is a legal bucket (or storage account) name
+// for provider.
+func Valid(provider, name string) bool {
+	switch provider {
+	case S3, Spaces:
+		return s3NameRe.MatchString(name) && !strings.Contains(name, "..")
+	case GCS:
+		return gcsNameRe.MatchString(name) && !strings.Contains(name, "..")
+	case Azure:
+		return azureNameRe.MatchString(name)
+	}
+	return false
+}
+
+// Result is one existing bucket (or Azure container).
+type Result struct {
+	Provider string
+	Bucket   string // bucket, or account/container for Azure
+	URL      string
+	Access   string   // AccessList, AccessRead or AccessPrivate
+	Objects  int      // objects on the first listing page
+	Sample   []string // a few object keys
+	Readable string   // URL of an object read anonymously
+	Evidence *evidence.Record
+}
+
+// Checker runs the checks against a set of endpoints.
+type Checker struct {
+	client    *http.Client
+	endpoints map[string]string
+}
+
+// NewChecker returns a checker using endpoints, falling back to
+// DefaultEndpoints for providers it does not set. Templates without
+// {bucket} are treated as path-style ("http://127.0.0.1:9000" →
+// "http://127.0.0.1:9000/{bucket}").
+func NewChecker(endpoints map[string]string) *Checker {
+	c := &Checker{
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+			Transport: &http.Transport{
+				TLSClientConfig:     &tls.Config{InsecureSkipVerify: true},
+				MaxIdleConnsPerHost: 20,
+				DialContext: (&net.Dialer{
+					Timeout: 5 * time.Second,
+				}).DialContext,
+			},
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		endpoints: map[string]string{},
+	}
+	for p, tmpl := range DefaultEndpoints {
+		c.endpoints[p] = tmpl
+	}
+	for p, tmpl := range endpoints {
+		tmpl = strings.TrimRight(tmpl, "/")
+		if !strings.Contains(tmpl, "{bucket}") {
+			tmpl += "/{bucket}"
+		}
+		c.endpoints[p] = tmpl
+	}
+	return c
+}
+
+// ParseEndpoints parses "provider=template" pairs (as given on the command
+// line) into an endpoint map.
+func ParseEndpoints(pairs []string) (map[string]string, error) {
+	out := map[string]string{}
+	for _, pair := range pairs {
+		p, tmpl, ok := strings.Cut(pair, "=")
+		p = strings.ToLower(strings.TrimSpace(p))
+		if !ok || tmpl == "" {
+			return nil, fmt.Errorf("invalid endpoint %q (want provider=url)", pair)
+		}
+		if _, known := DefaultEndpoints[p]; !known {
+			return nil, fmt.Errorf("unknown provider %q (want %s)", p, strings.Join(Providers, ", "))
+		}
+		out[p] = strings.TrimSpace(tmpl)
+	}
+	return out, nil
+}
+
+// Check looks for name on provider and returns every existing bucket or
+// container found (nil when none exists).
+func (c *Checker) Check(provider, name string) []Result {
+	if !Valid(provider, name) {
+		return nil
+	}
+	tmpl := strings.ReplaceAll(c.endpoints[provider], "{bucket}", name)
+	switch provider {
+	case Azure:
+		return c.checkAzure(name, tmpl)
+	case Spaces:
+		if !strings.Contains(tmpl, "{region}") {
+			return c.checkS3(provider, name, tmpl)
+		}
+		for _, region := range SpacesRegions {
+			if r := c.checkS3(provider, name, strings.ReplaceAll(tmpl, "{region}", region)); r != nil {
+				return r
+			}
+		}
+		return nil
+	default:
+		return c.checkS3(provider, name, tmpl)
+	}
+}
+
+// s3List is the S3/GCS ListObjects answer (or error document).
+type s3List struct {
+	XMLName  xml.Name
+	Code     string // error code: NoSuchBucket, AccessDenied…
+	Endpoint string // region endpoint of a PermanentRedirect
+	Contents []struct {
+		Key string
+	}
+}
+
+// checkS3 lists base (an S3 API bucket URL). 404 NoSuchBucket means the
+// bucket does not exist; any other answer from the storage API means it
+// does, and only a ListBucketResult means anonymous listing.
+func (c *Checker) checkS3(provider, name, base string) []Result {
+	resp, body, req, err := c.get(base + "/")
+	if err != nil {
+		return nil
+	}
+	var doc s3List
+	_ = xml.Unmarshal(body, &doc)
+
+	// Legacy global S3 endpoint: follow the region redirect once.
+	if (resp.StatusCode == http.StatusMovedPermanently || resp.StatusCode == http.StatusTemporaryRedirect) && doc.Endpoint != "" {
+		base = "https://" + doc.Endpoint
+		if resp, body, req, err = c.get(base + "/"); err != nil {
+			return nil
+		}
+		doc = s3List{}
+		_ = xml.Unmarshal(body, &doc)
+	}
+
+	res := Result{Provider: provider, Bucket: name, URL: base + "/"}
+	switch {
+	case resp.StatusCode == http.StatusOK && doc.XMLName.Local == "ListBucketResult":
+		res.Access = AccessList
+		res.Objects = len(doc.Contents)
+		res.Evidence = evidence.HTTP(req, resp, body)
+		for _, o := range doc.Contents {
+			if len(res.Sample) < 5 {
+				res.Sample = append(res.Sample, o.Key)
+			}
+		}
+		tries := 0
+		for _, o := range doc.Contents {
+			if strings.HasSuffix(o.Key, "/") || tries == len(readProbes) {
+				continue
+			}
+			tries++
+			if u := base + "/" + escapeKey(o.Key); c.readable(u) {
+				res.Readable = u
+				break
+			}
+		}
+	case doc.XMLName.Local == "Error" && (doc.Code == "NoSuchBucket" || doc.Code == "InvalidBucketName"):
+		return nil
+	case doc.XMLName.Local == "Error" || resp.Header.Get("x-amz-bucket-region") != "" || resp.Header.Get("x-guploader-uploadid") != "":
+		res.Access = AccessPrivate
+		for _, key := range readProbes {
+			if u := base + "/" + key; c.readable(u) {
+				res.Access = AccessRead
+				res.Readable = u
+				break
+			}
+		}
+	default:
+		// Not a storage API answer (custom domain, proxy, stand-in quirk).
+		return nil
+	}
+	return []Result{res}
+}
+
+// azureList is the Azure container listing (EnumerationResults).
+type azureList struct {
+	XMLName xml.Name
+	Blobs   struct {
+		Blob []struct {
+			Name string
+		}
+	}
+}
+
+// checkAzure checks storage account name: the account exists when its
+// endpoint answers at all (unknown accounts do not resolve), then each of
+// AzureContainers is listed anonymously.
+func (c *Checker) checkAzure(name, base string) []Result {
+	resp, _, _, err := c.get(base + "/?comp=list")
+	if err != nil || (resp.StatusCode == http.StatusNotFound && resp.Header.Get("x-ms-request-id") == "") {
+		return nil
+	}
+	var out []Result
+	for _, container := range AzureContainers {
+		u := base + "/" + container
+		resp, body, req, err := c.get(u + "?restype=container&comp=list")
+		if err != nil || resp.StatusCode != http.StatusOK {
+			continue
+		}
+		var doc azureList
+		if xml.Unmarshal(body, &doc) != nil || doc.XMLName.Local != "EnumerationResults" {
+			continue
+		}
+		res := Result{
+			Provider: Azure,
+			Bucket:   name + "/" + container,
+			URL:      u,
+			Access:   AccessList,
+			Objects:  len(doc.Blobs.Blob),
+			Evidence: evidence.HTTP(req, resp, body),
+		}
+		for _, b := range doc.Blobs.Blob {
+			if len(res.Sample) < 5 {
+				res.Sample = append(res.Sample, b.Name)
+			}
+		}
+		for _, b := range doc.Blobs.Blob[:min(len(doc.Blobs.Blob), len(readProbes))] {
+			if c.readable(u + "/" + escapeKey(b.Name)) {
+				res.Readable = u + "/" + escapeKey(b.Name)
+				break
+			}
+		}
+		out = append(out, res)
+	}
+	if len(out) == 0 {
+		// The account exists; its containers are private or unguessed.
+		out = append(out, Result{Provider: Azure, Bucket: name, URL: base + "/", Access: AccessPrivate})
+	}
+	return out
+}
+
+// readable reports whether u can be downloaded anonymously.
+func (c *Checker) readable(u string) bool {
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return false
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
+	req.Header.Set("Range", "bytes=0-0")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusPartialContent
+}
+
+func (c *Checker) get(u string) (*http.Response, []byte, *http.Request, error) {
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxBody))
+	return resp, body, req, nil
+}
+
+// escapeKey escapes an object key for use in a URL path, keeping slashes.
+func escapeKey(key string) string {
+	parts := strings.Split(key, "/")
+	for i, p := range parts {
+		parts[i] = url.PathEscape(p)
+	}
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,86 @@
+package buckets
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeS3 answers like the S3 API for a few path-style buckets.
+func fakeS3(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		switch r.URL.Path {
+		case "/acme-public/":
+			w.Write([]byte(`<ListBucketResult><Contents><Key>logs/</Key></Contents><Contents><Key>db.sql</Key></Contents></ListBucketResult>`))
+		case "/acme-public/db.sql":
+			w.Write([]byte("-- dump"))
+		case "/acme-site/":
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`<Error><Code>AccessDenied</Code></Error>`))
+		case "/acme-site/index.html":
+			w.Write([]byte("<html></html>"))
+		case "/acme-private/":
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`<Error><Code>AccessDenied</Code></Error>`))
+		default:
+			if strings.HasSuffix(r.URL.Path, "/") && strings.Count(r.URL.Path, "/") == 2 {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`<Error><Code>NoSuchBucket</Code></Error>`))
+				return
+			}
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`<Error><Code>AccessDenied</Code></Error>`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestNewCheckerCustomEndpoints(t *testing.T) {
+	srv := fakeS3(t)
+	c := NewChecker(map[string]string{S3: srv.URL + "/", GCS: srv.URL + "/{bucket}"})
+
+	if got := c.endpoints[S3]; got != srv.URL+"/{bucket}" {
+		t.Errorf("s3 endpoint = %q, want path-style %q", got, srv.URL+"/{bucket}")
+	}
+	if got := c.endpoints[Azure]; got != DefaultEndpoints[Azure] {
+		t.Errorf("azure endpoint = %q, want default %q", got, DefaultEndpoints[Azure])
+	}
+
+	tests := []struct {
+		provider, name string
+		access         string // "" = no bucket
+		readable       string
+		objects        int
+	}{
+		{S3, "acme-public", AccessList, srv.URL + "/acme-public/db.sql", 2},
+		{S3, "acme-site", AccessRead, srv.URL + "/acme-site/index.html", 0},
+		{S3, "acme-private", AccessPrivate, "", 0},
+		{S3, "acme-missing", "", "", 0},
+		{GCS, "acme-public", AccessList, srv.URL + "/acme-public/db.sql", 2},
+	}
+	for _, tt := range tests {
+		results := c.Check(tt.provider, tt.name)
+		if tt.access == "" {
+			if len(results) != 0 {
+				t.Errorf("%s %s: got %+v, want no bucket", tt.provider, tt.name, results)
+			}
+			continue
+		}
+		if len(results) != 1 {
+			t.Errorf("%s %s: got %d results, want 1", tt.provider, tt.name, len(results))
+			continue
+		}
+		r := results[0]
+		if r.Provider != tt.provider || r.Bucket != tt.name || r.URL != srv.URL+"/"+tt.name+"/" {
+			t.Errorf("%s %s: got %s %s %s", tt.provider, tt.name, r.Provider, r.Bucket, r.URL)
+		}
+		if r.Access != tt.access || r.Readable != tt.readable || r.Objects != tt.objects {
+			t.Errorf("%s %s: access %q readable %q objects %d, want %q %q %d",
+				tt.provider, tt.name, r.Access, r.Readable, r.Objects, tt.access, tt.readable, tt.objects)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package buckets
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// DefaultMaxNames caps the candidates generated per target.
+const DefaultMaxNames = 1500
+
+// defaultWords are the environment and content words combined with each
+// base name ("acme-backup", "dev-acme", "acmeassets").
+var defaultWords = []string{
+	"backup", "backups", "bak", "dev", "development", "staging", "stage", "stg", "prod",
+	"production", "test", "qa", "uat", "demo", "sandbox", "internal", "private", "public",
+	"assets", "static", "media", "images", "img", "uploads", "upload", "files", "docs",
+	"data", "db", "database", "dump", "logs", "log", "archive", "cdn", "content", "web",
+	"www", "app", "api", "mobile", "storage", "store", "s3", "bucket", "export", "reports",
+	"config", "secrets", "terraform", "tfstate", "builds", "artifacts", "releases", "deploy",
+}
+
+// secondLevel are the labels that form a public suffix with the TLD.
+var secondLevel = map[string]bool{"co": true, "com": true, "net": true, "org": true, "gov": true, "edu": true, "ac": true, "gob": true, "ne": true, "or": true}
+
+// LoadWords returns the words in file (one per line), or the built-in list
+// when file is empty.
+func LoadWords(file string) ([]string, error) {
+	if file == "" {
+		return defaultWords, nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", file, err)
+	}
+	var words []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if w := strings.ToLower(strings.TrimSpace(line)); w != "" && !strings.HasPrefix(w, "#") {
+			words = append(words, w)
+		}
+	}
+	return words, nil
+}
+
+// Bases returns the names a target is likely to use for its buckets:
+// "shop.acme.co.uk" → acme, shop-acme, acme.co.uk, acme-co-uk, acmecouk,
+// shop.acme.co.uk, shop-acme-co-uk... followed by the keywords.
+func Bases(domain string, keywords []string) []string {
+	domain = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(domain), "."))
+	labels := strings.Split(domain, ".")
+
+	// Registrable name: drop the TLD and a second-level suffix (co.uk,
+	// com.br).
+	suffix := 1
+	if len(labels) > 2 && secondLevel[labels[len(labels)-2]] {
+		suffix = 2
+	}
+	if len(labels) <= suffix {
+		return dedup(append([]string{domain}, keywords...))
+	}
+	name := labels[len(labels)-suffix-1]
+	root := strings.Join(labels[len(labels)-suffix-1:], ".")
+
+	bases := []string{name, root, strings.ReplaceAll(root, ".", "-"), strings.ReplaceAll(root, ".", "")}
+	if sub := labels[:len(labels)-suffix-1]; len(sub) > 0 {
+		bases = append(bases,
+			strings.Join(append(append([]string{}, sub...), name), "-"),
+			domain,
+			strings.ReplaceAll(domain, ".", "-"),
+		)
+	}
+	for _, k := range keywords {
+		if k = strings.ToLower(strings.TrimSpace(k)); k != "" {
+			bases = append(bases, k)
+		}
+	}
+	return dedup(bases)
+}
+
+// Candidates combines every base with words ("base", "base-word",
+// "word-base", "baseword", "base.word"), bases first, capped at max
+// (0 = DefaultMaxNames).
+func Candidates(bases, words []string, max int) []string {
+	if max <= 0 {
+		max = DefaultMaxNames
+	}
+	out := append([]string{}, bases...)
+	for _, w := range words {
+		for _, b := range bases {
+			out = append(out, b+"-"+w, w+"-"+b, b+w, b+"."+w)
+		}
+	}
+	out = dedup(out)
+	if len(out) > max {
+		out = out[:max]
+	}
+	return out
+}
+
+func dedup(in []string) []string {
+	seen := map[string]bool{}
+	out := in[:0]
+	for _, s := range in {
+		if s != "" && !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -24,6 +24,8 @@ func Run() {
 		RunWorkflow(os.Args[2:])
 	case "update":
 		RunUpdate()
+	case "export":
+		RunExport(os.Args[2:])
 	default:
 		RunTool(command)
 	}
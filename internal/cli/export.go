@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/FOUEN/narmol/internal/export"
+	"github.com/FOUEN/narmol/internal/findings"
+)
+
+// RunExport handles the "narmol export <target> [flags]" subcommand.
+func RunExport(args []string) {
+	if len(args) == 0 || args[0] != "issues" {
+		printExportUsage()
+		os.Exit(1)
+	}
+
+	opts := parseExportFlags(args[1:])
+
+	var all []findings.Finding
+	for _, path := range opts.inputs {
+		list, err := findings.Load(path)
+		if err != nil {
+			fmt.Printf("[!] %s\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("[*] Loaded %d findings from %s\n", len(list), path)
+		all = append(all, list...)
+	}
+
+	if ignore := loadIgnore(opts.ignoreFile); ignore != nil {
+		var suppressed []findings.Finding
+		all, suppressed = ignore.Filter(all)
+		if len(suppressed) > 0 {
+			fmt.Printf("[*] %d findings suppressed by %s\n", len(suppressed), ignore.Path)
+		}
+	}
+
+	tracker, err := export.NewTracker(opts.cfg)
+	if err != nil {
+		fmt.Printf("[!] %s\n", err)
+		os.Exit(1)
+	}
+
+	res, err := export.Issues(context.Background(), tracker, all, export.Options{
+		DryRun:      opts.dryRun,
+		MinSeverity: opts.minSeverity,
+	})
+	if err != nil {
+		fmt.Printf("[!] Export failed: %s\n", err)
+		os.Exit(1)
+	}
+
+	verb := "created"
+	if opts.dryRun {
+		verb = "would be created"
+	}
+	fmt.Printf("[+] Export to %s completed — %d issues %s, %d already filed, %d below severity, %d failed\n",
+		tracker.Name(), len(res.Created), verb, res.Duplicates, res.Skipped, res.Failed)
+}
+
+// exportFlags holds the parsed flags for an export invocation.
+type exportFlags struct {
+	inputs      []string
+	cfg         export.Config
+	dryRun      bool
+	minSeverity string
+	ignoreFile  string
+}
+
+func parseExportFlags(args []string) exportFlags {
+	var f exportFlags
+
+	next := func(i *int) string {
+		if *i+1 < len(args) {
+			*i++
+			return args[*i]
+		}
+		return ""
+	}
+
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "-i", "--input":
+			f.inputs = append(f.inputs, next(&i))
+		case "-t", "--tracker":
+			f.cfg.Tracker = next(&i)
+		case "-p", "--project":
+			f.cfg.Project = next(&i)
+		case "--url":
+			f.cfg.BaseURL = next(&i)
+		case "--token":
+			f.cfg.Token = next(&i)
+		case "--user":
+			f.cfg.User = next(&i)
+		case "--min-severity":
+			f.minSeverity = next(&i)
+		case "--ignore":
+			f.ignoreFile = next(&i)
+		case "--dry-run":
+			f.dryRun = true
+		}
+	}
+
+	// Tokens can come from the environment so they stay out of shell history.
+	if f.cfg.Token == "" {
+		f.cfg.Token = os.Getenv("NARMOL_" + strings.ToUpper(f.cfg.Tracker) + "_TOKEN")
+	}
+
+	if len(f.inputs) == 0 || f.cfg.Tracker == "" {
+		printExportUsage()
+		os.Exit(1)
+	}
+	return f
+}
+
+func printExportUsage() {
+	fmt.Println("Usage: narmol export issues -i <results.json> -t <github|gitlab|jira> -p <project> [flags]")
+	fmt.Println()
+	fmt.Println("Creates one issue per unique finding. Findings already filed are skipped.")
+	fmt.Println()
+	fmt.Println("Flags:")
+	fmt.Println("  -i, --input <file>      narmol JSON output (repeatable)")
+	fmt.Println("  -t, --tracker <name>    github, gitlab or jira")
+	fmt.Println("  -p, --project <id>      owner/repo (github), id or group/project (gitlab), key (jira)")
+	fmt.Println("  --url <url>             API base URL (required for jira, optional for self-hosted)")
+	fmt.Println("  --token <token>         API token (default: $NARMOL_<TRACKER>_TOKEN)")
+	fmt.Println("  --user <email>          Jira Cloud account e-mail (basic auth)")
+	fmt.Println("  --min-severity <sev>    skip findings below severity (info, low, medium, high, critical)")
+	fmt.Println("  --ignore <file>         suppression file (default: ./.narmolignore if present)")
+	fmt.Println("  --dry-run               show what would be filed without creating issues")
+}
@@ -1,9 +1,18 @@
 package cli
 
-import "github.com/FOUEN/narmol/internal/updater"
+import (
+	"fmt"
+
+	"github.com/FOUEN/narmol/internal/takeover"
+	"github.com/FOUEN/narmol/internal/updater"
+)
 
 // RunUpdate handles the "narmol update" subcommand.
-// It updates all tool sources, patches them, and rebuilds the binary automatically.
+// It refreshes the takeover service list, updates all tool sources, patches
+// them, and rebuilds the binary automatically.
 func RunUpdate() {
+	if err := takeover.UpdateFingerprints(); err != nil {
+		fmt.Printf("[!] %s (keeping the current list)\n", err)
+	}
 	updater.SelfUpdate()
 }
@@ -21,7 +21,8 @@ func PrintUsage() {
 
 	fmt.Println("Commands:")
 	fmt.Println("  workflow     Run a predefined workflow (requires --scope)")
-	fmt.Println("  update       Update all tools to latest version")
+	fmt.Println("  update       Update all tools and data files to latest version")
+	fmt.Println("  export       Export findings (export issues → GitHub/GitLab/Jira)")
 	fmt.Println()
 	fmt.Println("Run 'narmol workflow' to see available workflows.")
 }
@@ -3,9 +3,17 @@ package cli
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 
+	"github.com/FOUEN/narmol/internal/auth"
+	"github.com/FOUEN/narmol/internal/brute"
+	"github.com/FOUEN/narmol/internal/buckets"
+	"github.com/FOUEN/narmol/internal/gauconf"
+	"github.com/FOUEN/narmol/internal/report"
 	"github.com/FOUEN/narmol/internal/scope"
+	"github.com/FOUEN/narmol/internal/suppress"
 	"github.com/FOUEN/narmol/internal/workflows"
 )
 
@@ -46,27 +54,193 @@ func RunWorkflow(args []string) {
 
 	fmt.Printf("[*] Running workflow '%s'\n", name)
 
+	// IP workflows run once per IP/CIDR rule instead of once per domain.
+	targets, label := domains, "domain"
+	if iw, ok := w.(workflows.IPWorkflow); ok && iw.TargetsIPs() {
+		targets, label = ips, "range"
+	}
+
 	out := workflows.OutputOptions{
-		TextFile: opts.textFile,
-		JSONFile: opts.jsonFile,
+		TextFile:         opts.textFile,
+		JSONFile:         opts.jsonFile,
+		EvidenceDir:      opts.evidenceDir,
+		EvidenceMaxBytes: opts.evidenceMax,
+		ArtifactDir:      artifactDir(opts),
+		Brute:            opts.brute,
+		Wordlist:         opts.wordlist,
+		RateLimit:        opts.rateLimit,
+		Permute:          opts.permute,
+		PermuteMax:       opts.permuteMax,
+		Concurrency:      opts.concurrency,
+		Extensions:       splitList(opts.extensions),
+		Recursion:        opts.recursion,
+		FilterStatus:     parseInts(opts.filterCode),
+		FilterSize:       parseInts(opts.filterSize),
+		FilterWords:      parseInts(opts.filterWords),
+		WAFBackoff:       opts.wafBackoff,
+		Keywords:         splitList(opts.keywords),
+		BucketMax:        opts.bucketMax,
+		Ports:            opts.ports,
+		SynScan:          opts.syn,
+		IncludeCDN:       opts.includeCDN,
+		Headless:         opts.headless,
+		Gau: gauconf.Options{
+			Providers:          splitList(opts.gauProviders),
+			From:               opts.gauFrom,
+			To:                 opts.gauTo,
+			MatchStatus:        splitList(opts.gauMatchStatus),
+			FilterStatus:       splitList(opts.gauFilterStatus),
+			MatchMIME:          splitList(opts.gauMatchMIME),
+			FilterMIME:         splitList(opts.gauFilterMIME),
+			Blacklist:          splitList(opts.gauBlacklist),
+			NoDefaultBlacklist: opts.gauNoBlacklist,
+			URLScanKey:         opts.urlscanKey,
+		},
+		Auth: auth.Options{
+			Headers:   opts.headers,
+			Cookies:   opts.cookies,
+			Bearer:    opts.bearer,
+			LoginURL:  opts.loginURL,
+			LoginData: opts.loginData,
+			CSRFField: opts.loginCSRF,
+			Hosts:     splitList(opts.authHosts),
+		},
+	}
+	if err := out.Gau.Validate(); err != nil {
+		fmt.Printf("[!] %s\n", err)
+		os.Exit(1)
+	}
+	if err := out.Auth.Validate(); err != nil {
+		fmt.Printf("[!] Auth: %s\n", err)
+		os.Exit(1)
+	}
+	if out.Resolvers, err = brute.ParseResolvers(opts.resolvers); err != nil {
+		fmt.Printf("[!] Resolvers: %s\n", err)
+		os.Exit(1)
+	}
+	if out.BucketEndpoints, err = buckets.ParseEndpoints(opts.bucketEndpoints); err != nil {
+		fmt.Printf("[!] Bucket endpoints: %s\n", err)
+		os.Exit(1)
+	}
+
+	// Without output files there is nothing to consolidate: results go
+	// straight to the console as before. Workflows print as they find, so
+	// suppression rules cannot be applied there.
+	if out.TextFile == "" && out.JSONFile == "" && opts.htmlFile == "" {
+		if opts.ignoreFile != "" {
+			fmt.Println("[!] --ignore needs an output file (-o, -oj or -oh): console output is not filtered")
+			os.Exit(1)
+		}
+		if _, err := os.Stat(suppress.DefaultFile); err == nil {
+			fmt.Printf("[!] %s is only applied to output files (-o, -oj, -oh), console output is not filtered\n", suppress.DefaultFile)
+		}
+		for _, domain := range targets {
+			fmt.Printf("\n[+] Processing %s: %s\n", label, domain)
+			if err := w.Run(domain, s, out); err != nil {
+				fmt.Printf("[!] Workflow failed for %s: %s\n", domain, err)
+			}
+		}
+		return
+	}
+
+	// Each domain writes to its own scratch files; the run report merges
+	// them into one text report and one JSON document.
+	tmpDir, err := os.MkdirTemp("", "narmol-run-")
+	if err != nil {
+		fmt.Printf("[!] Could not create run directory: %s\n", err)
+		os.Exit(1)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	run := report.NewRun(name, s)
+	run.Ignore = loadIgnore(opts.ignoreFile)
+	fmt.Printf("[*] Run ID: %s\n", run.ID)
+
+	for i, domain := range targets {
+		fmt.Printf("\n[+] Processing %s: %s\n", label, domain)
+		domOut := out
+		domOut.TextFile = filepath.Join(tmpDir, fmt.Sprintf("%d.txt", i))
+		domOut.JSONFile = filepath.Join(tmpDir, fmt.Sprintf("%d.json", i))
+
+		runErr := w.Run(domain, s, domOut)
+		if runErr != nil {
+			fmt.Printf("[!] Workflow failed for %s: %s\n", domain, runErr)
+		}
+		run.AddDomain(domain, domOut.TextFile, domOut.JSONFile, runErr)
 	}
 
-	for _, domain := range domains {
-		fmt.Printf("\n[+] Processing domain: %s\n", domain)
-		if err := w.Run(domain, s, out); err != nil {
-			fmt.Printf("[!] Workflow failed for %s: %s\n", domain, err)
+	if err := run.Write(out.TextFile, out.JSONFile, opts.htmlFile); err != nil {
+		fmt.Printf("[!] %s\n", err)
+		os.Exit(1)
+	}
+}
+
+// artifactDir picks OutputOptions.ArtifactDir: the evidence directory, else
+// next to the first report file, else the working directory.
+func artifactDir(opts workflowFlags) string {
+	if opts.evidenceDir != "" {
+		return opts.evidenceDir
+	}
+	for _, f := range []string{opts.jsonFile, opts.textFile, opts.htmlFile} {
+		if f != "" {
+			return filepath.Dir(f)
 		}
 	}
+	return "."
 }
 
 // workflowFlags holds the parsed flags for a workflow invocation.
 type workflowFlags struct {
-	scopeFile string
-	textFile  string
-	jsonFile  string
+	scopeFile   string
+	textFile    string
+	jsonFile    string
+	htmlFile    string
+	evidenceDir string
+	evidenceMax int
+	ignoreFile  string
+	brute       bool
+	wordlist    string
+	resolvers   string
+	rateLimit   int
+	permute     bool
+	permuteMax  int
+	concurrency int
+	extensions  string
+	recursion   int
+	filterCode  string
+	filterSize  string
+	filterWords string
+	wafBackoff  bool
+	keywords    string
+	ports       string
+	syn         bool
+	includeCDN  bool
+	headless    bool
+
+	gauProviders    string
+	gauFrom         string
+	gauTo           string
+	gauMatchStatus  string
+	gauFilterStatus string
+	gauMatchMIME    string
+	gauFilterMIME   string
+	gauBlacklist    string
+	gauNoBlacklist  bool
+	urlscanKey      string
+
+	headers   []string
+	cookies   string
+	bearer    string
+	loginURL  string
+	loginData string
+	loginCSRF string
+	authHosts string
+
+	bucketEndpoints []string
+	bucketMax       int
 }
 
-// parseWorkflowFlags does manual arg parsing to support optional values for -o and -oj.
+// parseWorkflowFlags does manual arg parsing to support optional values for -o, -oj, -oh and -oe.
 func parseWorkflowFlags(workflowName string, args []string) workflowFlags {
 	var f workflowFlags
 
@@ -92,6 +266,194 @@ func parseWorkflowFlags(workflowName string, args []string) workflowFlags {
 			} else {
 				f.jsonFile = workflowName + ".json"
 			}
+		case arg == "-oh":
+			if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+				f.htmlFile = args[i+1]
+				i++
+			} else {
+				f.htmlFile = workflowName + ".html"
+			}
+		case arg == "-oe":
+			if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+				f.evidenceDir = args[i+1]
+				i++
+			} else {
+				f.evidenceDir = workflowName + "-evidence"
+			}
+		case arg == "--evidence-max":
+			if i+1 < len(args) {
+				f.evidenceMax = parseCount(arg, args[i+1])
+				i++
+			}
+		case arg == "--brute":
+			f.brute = true
+		case arg == "-w" || arg == "--wordlist":
+			if i+1 < len(args) {
+				f.wordlist = args[i+1]
+				i++
+			}
+		case arg == "--resolvers":
+			if i+1 < len(args) {
+				f.resolvers = args[i+1]
+				i++
+			}
+		case arg == "--rate-limit":
+			if i+1 < len(args) {
+				f.rateLimit = parseCount(arg, args[i+1])
+				i++
+			}
+		case arg == "--permute":
+			f.permute = true
+		case arg == "--permute-max":
+			if i+1 < len(args) {
+				f.permuteMax, _ = strconv.Atoi(args[i+1])
+				i++
+			}
+		case arg == "-c" || arg == "--concurrency":
+			if i+1 < len(args) {
+				f.concurrency = parseCount(arg, args[i+1])
+				i++
+			}
+		case arg == "-e" || arg == "--extensions":
+			if i+1 < len(args) {
+				f.extensions = args[i+1]
+				i++
+			}
+		case arg == "--recursion":
+			if i+1 < len(args) {
+				f.recursion, _ = strconv.Atoi(args[i+1])
+				i++
+			}
+		case arg == "--fc":
+			if i+1 < len(args) {
+				f.filterCode = args[i+1]
+				i++
+			}
+		case arg == "--fs":
+			if i+1 < len(args) {
+				f.filterSize = args[i+1]
+				i++
+			}
+		case arg == "--fw":
+			if i+1 < len(args) {
+				f.filterWords = args[i+1]
+				i++
+			}
+		case arg == "--waf-backoff":
+			f.wafBackoff = true
+		case arg == "-k" || arg == "--keywords":
+			if i+1 < len(args) {
+				f.keywords = args[i+1]
+				i++
+			}
+		case arg == "--bucket-endpoint":
+			if i+1 < len(args) {
+				f.bucketEndpoints = append(f.bucketEndpoints, args[i+1])
+				i++
+			}
+		case arg == "--bucket-max":
+			if i+1 < len(args) {
+				f.bucketMax, _ = strconv.Atoi(args[i+1])
+				i++
+			}
+		case arg == "-p" || arg == "--ports":
+			if i+1 < len(args) {
+				f.ports = args[i+1]
+				i++
+			}
+		case arg == "--syn":
+			f.syn = true
+		case arg == "--include-cdn":
+			f.includeCDN = true
+		case arg == "--headless":
+			f.headless = true
+		case arg == "--gau-providers":
+			if i+1 < len(args) {
+				f.gauProviders = args[i+1]
+				i++
+			}
+		case arg == "--gau-from":
+			if i+1 < len(args) {
+				f.gauFrom = args[i+1]
+				i++
+			}
+		case arg == "--gau-to":
+			if i+1 < len(args) {
+				f.gauTo = args[i+1]
+				i++
+			}
+		case arg == "--gau-mc":
+			if i+1 < len(args) {
+				f.gauMatchStatus = args[i+1]
+				i++
+			}
+		case arg == "--gau-fc":
+			if i+1 < len(args) {
+				f.gauFilterStatus = args[i+1]
+				i++
+			}
+		case arg == "--gau-mt":
+			if i+1 < len(args) {
+				f.gauMatchMIME = args[i+1]
+				i++
+			}
+		case arg == "--gau-ft":
+			if i+1 < len(args) {
+				f.gauFilterMIME = args[i+1]
+				i++
+			}
+		case arg == "--gau-blacklist":
+			if i+1 < len(args) {
+				f.gauBlacklist = args[i+1]
+				i++
+			}
+		case arg == "--gau-no-blacklist":
+			f.gauNoBlacklist = true
+		case arg == "--urlscan-key":
+			if i+1 < len(args) {
+				f.urlscanKey = args[i+1]
+				i++
+			}
+		case arg == "-H" || arg == "--header":
+			if i+1 < len(args) {
+				f.headers = append(f.headers, args[i+1])
+				i++
+			}
+		case arg == "--cookie":
+			if i+1 < len(args) {
+				f.cookies = args[i+1]
+				i++
+			}
+		case arg == "--bearer":
+			if i+1 < len(args) {
+				f.bearer = args[i+1]
+				i++
+			}
+		case arg == "--login-url":
+			if i+1 < len(args) {
+				f.loginURL = args[i+1]
+				i++
+			}
+		case arg == "--login-data":
+			if i+1 < len(args) {
+				f.loginData = args[i+1]
+				i++
+			}
+		case arg == "--login-csrf":
+			if i+1 < len(args) {
+				f.loginCSRF = args[i+1]
+				i++
+			}
+		case arg == "--auth-hosts":
+			if i+1 < len(args) {
+				f.authHosts = args[i+1]
+				i++
+			}
+		case arg == "--ignore":
+			if i+1 < len(args) {
+				f.ignoreFile = args[i+1]
+				i++
+			}
 		}
 	}
 
@@ -102,7 +464,7 @@ func parseWorkflowFlags(workflowName string, args []string) workflowFlags {
 		fmt.Println("  *.example.com          # all subdomains")
 		fmt.Println("  -admin.example.com     # exclude admin")
 		fmt.Println()
-		fmt.Printf("Usage: narmol workflow %s --scope <scope.txt> [-o [file]] [-oj [file]]\n", workflowName)
+		fmt.Printf("Usage: narmol workflow %s --scope <scope.txt> [-o [file]] [-oj [file]] [-oh [file]] [-oe [dir]] [--ignore <file>]\n", workflowName)
 		os.Exit(1)
 	}
 
@@ -115,5 +477,98 @@ func printWorkflows() {
 		fmt.Printf("  - %-12s %s\n", w.Name(), w.Description())
 	}
 	fmt.Println()
-	fmt.Println("Usage: narmol workflow <name> --scope <scope.txt> [-o [file]] [-oj [file]]")
+	fmt.Println("Usage: narmol workflow <name> --scope <scope.txt> [-o [file]] [-oj [file]] [-oh [file]] [-oe [dir]] [flags]")
+	fmt.Println()
+	fmt.Println("  -oh [file]             HTML run report with risk-ranked \"fix first\" list")
+	fmt.Println("  -oe [dir]              save raw request/response evidence per finding (default: <name>-evidence)")
+	fmt.Println("  --evidence-max <bytes> cap per request/response section (default: 32768)")
+	fmt.Println("  --ignore <file>        suppression file (default: ./.narmolignore if present); needs -o, -oj or -oh")
+	fmt.Println("  --brute                add DNS brute-force to subdomains/active/takeover/ports")
+	fmt.Println("  -w, --wordlist <file>  brute-force wordlist, or fuzz path list: small|medium|<file>, params name list, or buckets word list (default: built-in list)")
+	fmt.Println("  --resolvers <list>     resolvers file or comma-separated list (default: dnsx resolvers)")
+	fmt.Println("  --rate-limit <n>       DNS queries per second (default: 200); fuzz: requests per second per host (default: 50); ports: packets per second (default: 1500)")
+	fmt.Println("  --permute              add resolved subdomain permutations to recon (always on in subdomains/full)")
+	fmt.Println("  --permute-max <n>      cap on generated permutations (default: 5000)")
+	fmt.Println("  -c, --concurrency <n>  parallel workers for workflows that support it (ipsweep, vhosts, fuzz, jsanalyze, params, waf, buckets, apispec, ports, urls)")
+	fmt.Println("  -e, --extensions <l>   fuzz: extensions appended to each word (e.g. php,bak,zip)")
+	fmt.Println("  --recursion <n>        fuzz: directory levels to descend into (default: 0)")
+	fmt.Println("  --fc, --fs, --fw <l>   fuzz: drop responses with these status codes / sizes / word counts")
+	fmt.Println("  --waf-backoff          web/full/fuzz: on hosts behind a WAF, lower the rate and skip noisy nuclei tags")
+	fmt.Println("  -k, --keywords <l>     buckets: extra base names (e.g. brand,product)")
+	fmt.Println("  --bucket-endpoint <p=url>  buckets: override s3|gcs|azure|spaces endpoint, {bucket} placeholder (repeatable)")
+	fmt.Println("  --bucket-max <n>       buckets: cap on candidate names (default: 1500)")
+	fmt.Println("  -p, --ports <spec>     ports: top-100|top-1000|full or a list like 80,443,8000-8100 (default: top-1000)")
+	fmt.Println("  --syn                  ports: SYN scan when running as root/CAP_NET_RAW (default: connect scan)")
+	fmt.Println("  --include-cdn          ports: also scan IPs of CDN/WAF providers")
+	fmt.Println("  --headless             crawl: headless Chrome with XHR capture and form filling (standard crawl if no browser)")
+	fmt.Println()
+	fmt.Println("  gau (recon, urls, full, params, jsanalyze):")
+	fmt.Println("  --gau-providers <l>    wayback,commoncrawl,otx,urlscan (default: wayback,otx,urlscan)")
+	fmt.Println("  --gau-from, --gau-to <YYYYMM>  archive date range (wayback, commoncrawl)")
+	fmt.Println("  --gau-mc, --gau-fc <l> keep only / drop these archived status codes (wayback, commoncrawl)")
+	fmt.Println("  --gau-mt, --gau-ft <l> keep only / drop these MIME types (wayback, commoncrawl)")
+	fmt.Println("  --gau-blacklist <l>    extra extensions to drop (default list: images, fonts, media)")
+	fmt.Println("  --gau-no-blacklist     drop only --gau-blacklist extensions, keep images and fonts")
+	fmt.Println("  --urlscan-key <key>    urlscan.io API key (default: $URLSCAN_API_KEY)")
+	fmt.Println()
+	fmt.Println("  Authenticated scanning (web, full, crawl):")
+	fmt.Println("  -H, --header <h>       \"Name: value\" sent to the session hosts (repeatable)")
+	fmt.Println("  --cookie <c>           Cookie header, e.g. \"session=abc; lang=en\"")
+	fmt.Println("  --bearer <token>       Authorization: Bearer token")
+	fmt.Println("  --login-url <url>      form login before the scan (GET for the CSRF token, then POST)")
+	fmt.Println("  --login-data <form>    urlencoded login form, e.g. \"user=bob&pass=secret\"")
+	fmt.Println("  --login-csrf <field>   CSRF field name (default: csrf_token, _token, authenticity_token… or <meta name=csrf-token>)")
+	fmt.Println("  --auth-hosts <l>       hosts the session is sent to, *.example.com allowed (default: login host, or the scanned domain and subdomains)")
+}
+
+// splitList splits a comma-separated flag value, dropping empty items.
+func splitList(value string) []string {
+	var out []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// parseInts parses a comma-separated list of integers, skipping invalid items.
+// parseCount parses the non-negative integer value of flag and exits with
+// a usage error otherwise: a typo must not fall back to the default.
+func parseCount(flag, value string) int {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		fmt.Printf("Error: %s needs a non-negative integer, got %q\n", flag, value)
+		os.Exit(1)
+	}
+	return n
+}
+
+func parseInts(value string) []int {
+	var out []int
+	for _, v := range splitList(value) {
+		if n, err := strconv.Atoi(v); err == nil {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// loadIgnore loads the suppression file (an empty name falls back to
+// ./.narmolignore when present) and warns about rules past their expiry.
+func loadIgnore(file string) *suppress.List {
+	list, err := suppress.Load(file)
+	if err != nil {
+		fmt.Printf("[!] %s\n", err)
+		os.Exit(1)
+	}
+	if list == nil {
+		return nil
+	}
+	fmt.Printf("[*] Suppression rules: %d (%s)\n", len(list.Rules), list.Path)
+	for _, r := range list.Expired() {
+		fmt.Printf("[!] Suppression rule %s:%d expired on %s, findings it covered are reported again\n",
+			list.Path, r.Line, r.Expires.Format("2006-01-02"))
+	}
+	return list
 }
@@ -0,0 +1,224 @@
+// Package cluster groups live hosts that run the same application: same
+// favicon hash, near-identical page body (simhash) or same TLS stack (JARM).
+// Clusters turn a flat host list into "same login portal on 14 hosts", so
+// one finding or one credential can be tried everywhere it applies.
+package cluster
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/FOUEN/narmol/internal/simhash"
+)
+
+// Cluster kinds, in the order they are built. A host set already reported
+// under an earlier kind is not repeated.
+const (
+	Favicon = "favicon"
+	Body    = "body"
+	JARM    = "jarm"
+)
+
+// MinHosts is the smallest cluster reported.
+const MinHosts = 2
+
+// emptyJARM is what JARM returns when the host does not speak TLS.
+const emptyJARM = "00000000000000000000000000000000000000000000000000000000000000"
+
+// Fingerprint is what a probe (httpx) recorded about one live URL. Its JSON
+// names are the ones active/alive write, so any probe output can be fed back.
+type Fingerprint struct {
+	URL        string `json:"url"`
+	Title      string `json:"title,omitempty"`
+	StatusCode int    `json:"status_code"`
+	Favicon    string `json:"favicon,omitempty"`      // mmh3 of the favicon
+	JARM       string `json:"jarm,omitempty"`         // TLS JARM fingerprint
+	BodyHash   string `json:"body_simhash,omitempty"` // simhash of the body, hex
+}
+
+// BodySimhash returns the hex simhash stored in Fingerprint.BodyHash for a
+// response body ("" for an empty body).
+func BodySimhash(body string) string {
+	h := simhash.Hash(body)
+	if h == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%016x", h)
+}
+
+// FromMap reads a fingerprint from a decoded JSON result; ok is false when
+// the result carries none of the fingerprint fields.
+func FromMap(m map[string]any) (Fingerprint, bool) {
+	str := func(k string) string {
+		v, _ := m[k].(string)
+		return v
+	}
+	fp := Fingerprint{
+		URL:      str("url"),
+		Title:    str("title"),
+		Favicon:  str("favicon"),
+		JARM:     str("jarm"),
+		BodyHash: str("body_simhash"),
+	}
+	if code, ok := m["status_code"].(float64); ok {
+		fp.StatusCode = int(code)
+	}
+	return fp, fp.URL != "" && (fp.Favicon != "" || fp.JARM != "" || fp.BodyHash != "")
+}
+
+// Cluster is a set of hosts sharing one fingerprint.
+type Cluster struct {
+	Kind  string   `json:"kind"`            // Favicon, Body or JARM
+	Key   string   `json:"key"`             // the shared value
+	Title string   `json:"title,omitempty"` // most common page title
+	Hosts []string `json:"hosts"`           // hostnames, sorted
+	URLs  []string `json:"urls"`            // probed URLs, sorted
+}
+
+// Summary is the human-readable line for c:
+// `Same login portal "Acme SSO" on 14 hosts (favicon 116323821)`.
+func (c Cluster) Summary() string {
+	what := "application"
+	switch {
+	case c.Kind == JARM:
+		what = "TLS stack"
+	case isLogin(c.Title):
+		what = "login portal"
+	}
+	title := ""
+	if c.Title != "" && c.Kind != JARM {
+		title = fmt.Sprintf(" %q", c.Title)
+	}
+	key := c.Key
+	if len(key) > 16 {
+		key = key[:16] + "…"
+	}
+	return fmt.Sprintf("Same %s%s on %d hosts (%s %s): %s", what, title, len(c.Hosts), c.Kind, key, strings.Join(c.Hosts, ", "))
+}
+
+var loginWords = []string{"login", "log in", "sign in", "signin", "sso", "auth", "portal", "webmail", "vpn", "admin"}
+
+func isLogin(title string) bool {
+	t := strings.ToLower(title)
+	for _, w := range loginWords {
+		if strings.Contains(t, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// Group clusters fps by favicon, then body simhash (same status, within
+// simhash.NearDuplicate), then JARM, keeping clusters of at least MinHosts
+// distinct hostnames. Largest clusters come first.
+func Group(fps []Fingerprint) []Cluster {
+	var out []Cluster
+	seen := map[string]bool{} // host sets already reported
+
+	add := func(kind, key string, members []Fingerprint) {
+		c := build(kind, key, members)
+		if len(c.Hosts) < MinHosts {
+			return
+		}
+		set := strings.Join(c.Hosts, ",")
+		if seen[set] {
+			return
+		}
+		seen[set] = true
+		out = append(out, c)
+	}
+
+	byFavicon := map[string][]Fingerprint{}
+	byJARM := map[string][]Fingerprint{}
+	for _, fp := range fps {
+		if fp.Favicon != "" && fp.Favicon != "0" {
+			byFavicon[fp.Favicon] = append(byFavicon[fp.Favicon], fp)
+		}
+		if fp.JARM != "" && fp.JARM != emptyJARM {
+			byJARM[fp.JARM] = append(byJARM[fp.JARM], fp)
+		}
+	}
+
+	for _, key := range sortedKeys(byFavicon) {
+		add(Favicon, key, byFavicon[key])
+	}
+	for _, group := range bodyGroups(fps) {
+		add(Body, group[0].BodyHash, group)
+	}
+	for _, key := range sortedKeys(byJARM) {
+		add(JARM, key, byJARM[key])
+	}
+
+	sort.SliceStable(out, func(i, j int) bool { return len(out[i].Hosts) > len(out[j].Hosts) })
+	return out
+}
+
+// bodyGroups groups fingerprints whose bodies are near-duplicates and whose
+// status codes match; the first member of a group is its representative.
+func bodyGroups(fps []Fingerprint) [][]Fingerprint {
+	type group struct {
+		hash    uint64
+		status  int
+		members []Fingerprint
+	}
+	var groups []*group
+	for _, fp := range fps {
+		h, err := strconv.ParseUint(fp.BodyHash, 16, 64)
+		if err != nil || h == 0 {
+			continue
+		}
+		placed := false
+		for _, g := range groups {
+			if g.status == fp.StatusCode && simhash.Distance(g.hash, h) <= simhash.NearDuplicate {
+				g.members = append(g.members, fp)
+				placed = true
+				break
+			}
+		}
+		if !placed {
+			groups = append(groups, &group{hash: h, status: fp.StatusCode, members: []Fingerprint{fp}})
+		}
+	}
+	out := make([][]Fingerprint, 0, len(groups))
+	for _, g := range groups {
+		out = append(out, g.members)
+	}
+	return out
+}
+
+func build(kind, key string, members []Fingerprint) Cluster {
+	c := Cluster{Kind: kind, Key: key}
+	hosts := map[string]bool{}
+	urls := map[string]bool{}
+	titles := map[string]int{}
+	for _, fp := range members {
+		urls[fp.URL] = true
+		if u, err := url.Parse(fp.URL); err == nil && u.Hostname() != "" {
+			hosts[u.Hostname()] = true
+		}
+		if fp.Title != "" {
+			titles[fp.Title]++
+		}
+	}
+	c.Hosts = sortedKeys(hosts)
+	c.URLs = sortedKeys(urls)
+	best := 0
+	for _, t := range sortedKeys(titles) {
+		if titles[t] > best {
+			c.Title, best = t, titles[t]
+		}
+	}
+	return c
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
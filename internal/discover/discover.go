@@ -0,0 +1,112 @@
+// Package discover holds the host discovery steps shared by the per-host
+// workflows: subfinder enumeration and httpx liveness.
+package discover
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/FOUEN/narmol/internal/scope"
+
+	"github.com/projectdiscovery/goflags"
+	httpx_runner "github.com/projectdiscovery/httpx/runner"
+	"github.com/projectdiscovery/subfinder/v2/pkg/resolve"
+	subfinder_runner "github.com/projectdiscovery/subfinder/v2/pkg/runner"
+)
+
+// Subdomains enumerates domain with subfinder and returns the in-scope
+// subdomains found (domain itself excluded).
+func Subdomains(domain string, s *scope.Scope) []string {
+	fmt.Println("[*] Running subfinder...")
+
+	var mu sync.Mutex
+	var hosts []string
+	sfOptions := &subfinder_runner.Options{
+		Domain:             goflags.StringSlice{domain},
+		Silent:             true,
+		Timeout:            30,
+		MaxEnumerationTime: 10,
+		Threads:            10,
+		DisableUpdateCheck: true,
+		Output:             io.Discard,
+		ResultCallback: func(result *resolve.HostEntry) {
+			host := strings.TrimSpace(result.Host)
+			if host == "" || host == domain || !s.IsInScope(host) {
+				return
+			}
+			mu.Lock()
+			hosts = append(hosts, host)
+			mu.Unlock()
+		},
+	}
+	sfRunner, err := subfinder_runner.NewRunner(sfOptions)
+	if err != nil {
+		fmt.Printf("[!] Could not create subfinder runner: %s\n", err)
+		return nil
+	}
+	_ = sfRunner.RunEnumerationWithCtx(context.Background())
+
+	fmt.Printf("[+] Subfinder found %d in-scope subdomains\n", len(hosts))
+	return hosts
+}
+
+// Live returns the live scheme://host[:port] bases among hosts, sorted.
+// Redirects are not followed: callers work on the host that was asked for.
+func Live(hosts []string, s *scope.Scope) []string {
+	fmt.Printf("[*] Probing %d hosts with httpx...\n", len(hosts))
+
+	var mu sync.Mutex
+	seen := map[string]bool{}
+	var bases []string
+
+	hxOptions := &httpx_runner.Options{
+		InputTargetHost:    goflags.StringSlice(hosts),
+		Silent:             true,
+		DisableStdout:      true,
+		Threads:            50,
+		Timeout:            10,
+		DisableUpdateCheck: true,
+		DisableStdin:       true,
+		NoColor:            true,
+		RateLimit:          150,
+		Retries:            0,
+		HostMaxErrors:      30,
+		RandomAgent:        true,
+		OnResult: func(r httpx_runner.Result) {
+			if r.Err != nil {
+				return
+			}
+			u, err := url.Parse(r.URL)
+			if err != nil || !s.IsInScope(u.Hostname()) {
+				return
+			}
+			base := u.Scheme + "://" + u.Host
+			mu.Lock()
+			if !seen[base] {
+				seen[base] = true
+				bases = append(bases, base)
+			}
+			mu.Unlock()
+		},
+	}
+	if err := hxOptions.ValidateOptions(); err != nil {
+		fmt.Printf("[!] httpx options error: %s\n", err)
+		return nil
+	}
+	hxRunner, err := httpx_runner.New(hxOptions)
+	if err != nil {
+		fmt.Printf("[!] Could not create httpx runner: %s\n", err)
+		return nil
+	}
+	hxRunner.RunEnumeration()
+	hxRunner.Close()
+
+	sort.Strings(bases)
+	fmt.Printf("[+] %d live hosts\n", len(bases))
+	return bases
+}
@@ -0,0 +1,215 @@
+// Package evidence records the raw proof behind a finding: the exact request
+// that was sent and the response status, headers and (truncated) body.
+// Records are written to an evidence directory and referenced from the JSON
+// finding by path. Credentials in headers are redacted before anything
+// touches the disk.
+package evidence
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMaxBytes caps each section (request, response) of an evidence file.
+const DefaultMaxBytes = 32 * 1024
+
+// readLimit bounds how much of a response body is read for evidence,
+// regardless of the store cap, so a huge page never sits in memory.
+const readLimit = 256 * 1024
+
+// Record is the raw request/response pair behind a finding.
+type Record struct {
+	Request  string
+	Response string
+}
+
+// HTTP builds a record from a request sent with net/http and its response.
+// body is whatever part of the response body the check already read; pass
+// nil to have HTTP read it (bounded) from resp.Body.
+func HTTP(req *http.Request, resp *http.Response, body []byte) *Record {
+	rec := &Record{}
+	if req != nil {
+		if dump, err := httputil.DumpRequestOut(req, false); err == nil {
+			rec.Request = string(dump)
+		} else {
+			rec.Request = fmt.Sprintf("%s %s HTTP/1.1\r\n", req.Method, req.URL)
+		}
+	}
+	if resp != nil {
+		if body == nil && resp.Body != nil {
+			body, _ = io.ReadAll(io.LimitReader(resp.Body, readLimit))
+		}
+		if dump, err := httputil.DumpResponse(resp, false); err == nil {
+			rec.Response = string(dump) + string(body)
+		}
+	}
+	return rec.redact()
+}
+
+// Raw builds a record from a raw request and raw response, as produced by
+// hand-crafted socket checks or reported by nuclei.
+func Raw(request, response string) *Record {
+	return (&Record{Request: request, Response: response}).redact()
+}
+
+// TLS builds a record describing a completed TLS handshake: negotiated
+// protocol, cipher suite and the leaf certificate.
+func TLS(addr string, state tls.ConnectionState) *Record {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Protocol: %s\n", tls.VersionName(state.Version))
+	fmt.Fprintf(&b, "Cipher:   %s\n", tls.CipherSuiteName(state.CipherSuite))
+	if len(state.PeerCertificates) > 0 {
+		cert := state.PeerCertificates[0]
+		fmt.Fprintf(&b, "Subject:  %s\n", cert.Subject)
+		fmt.Fprintf(&b, "Issuer:   %s\n", cert.Issuer)
+		fmt.Fprintf(&b, "Valid:    %s → %s\n", cert.NotBefore.Format(time.RFC3339), cert.NotAfter.Format(time.RFC3339))
+		if len(cert.DNSNames) > 0 {
+			fmt.Fprintf(&b, "SANs:     %s\n", strings.Join(cert.DNSNames, ", "))
+		}
+	}
+	return &Record{
+		Request:  fmt.Sprintf("TLS handshake with %s", addr),
+		Response: b.String(),
+	}
+}
+
+// ─── Redaction ──────────────────────────────────────────────────────────
+
+const redacted = "[REDACTED]"
+
+// credentialHeaderRe matches headers whose whole value is a credential.
+var credentialHeaderRe = regexp.MustCompile(
+	`(?im)^(authorization|proxy-authorization|cookie|x-api-key|x-auth-token|x-access-token|x-csrf-token)[ \t]*:[^\r\n]*`)
+
+// extraHeaderRe matches the credential headers registered with
+// RedactHeaders (session headers with custom names); nil when none.
+var (
+	extraMu       sync.RWMutex
+	extraNames    = map[string]bool{}
+	extraHeaderRe *regexp.Regexp
+)
+
+// RedactHeaders adds header names whose values are masked like
+// Authorization, e.g. the custom headers of an authenticated session.
+func RedactHeaders(names ...string) {
+	extraMu.Lock()
+	defer extraMu.Unlock()
+	for _, n := range names {
+		extraNames[strings.ToLower(strings.TrimSpace(n))] = true
+	}
+	quoted := make([]string, 0, len(extraNames))
+	for n := range extraNames {
+		if n != "" {
+			quoted = append(quoted, regexp.QuoteMeta(n))
+		}
+	}
+	if len(quoted) == 0 {
+		return
+	}
+	extraHeaderRe = regexp.MustCompile(`(?im)^(` + strings.Join(quoted, "|") + `)[ \t]*:[^\r\n]*`)
+}
+
+// setCookieRe matches the value of a Set-Cookie header, keeping the cookie
+// name and attributes (Secure, HttpOnly, SameSite) which are the evidence
+// for cookie findings.
+var setCookieRe = regexp.MustCompile(`(?im)^(set-cookie[ \t]*:[ \t]*[^=;\r\n]+)=[^;\r\n]*`)
+
+func (r *Record) redact() *Record {
+	r.Request = Redact(r.Request)
+	r.Response = Redact(r.Response)
+	return r
+}
+
+// Redact masks credentials in a raw HTTP message. Every header line is
+// checked, so pipelined messages (smuggling probes) are covered too.
+func Redact(raw string) string {
+	raw = credentialHeaderRe.ReplaceAllString(raw, "$1: "+redacted)
+	extraMu.RLock()
+	if extraHeaderRe != nil {
+		raw = extraHeaderRe.ReplaceAllString(raw, "$1: "+redacted)
+	}
+	extraMu.RUnlock()
+	return setCookieRe.ReplaceAllString(raw, "$1="+redacted)
+}
+
+// ─── Store ──────────────────────────────────────────────────────────────
+
+// Store writes evidence records to a directory, one file per finding.
+// A nil *Store is valid and discards everything, so checks can attach
+// evidence unconditionally.
+type Store struct {
+	dir      string
+	maxBytes int
+}
+
+// NewStore creates the evidence directory. An empty dir disables evidence
+// capture and returns a nil store. maxBytes <= 0 uses DefaultMaxBytes.
+func NewStore(dir string, maxBytes int) (*Store, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBytes
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create evidence directory: %w", err)
+	}
+	return &Store{dir: dir, maxBytes: maxBytes}, nil
+}
+
+// Save writes rec for the finding identified by phase, target and detail and
+// returns the file path to reference from the finding ("" if nothing was
+// written). The file name is derived from the identity, so re-runs overwrite
+// the previous evidence instead of piling up copies.
+func (s *Store) Save(phase, target, detail string, rec *Record) string {
+	if s == nil || rec == nil {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(phase + "|" + target + "|" + detail))
+	name := fmt.Sprintf("%s-%s.txt", sanitize(phase), hex.EncodeToString(sum[:6]))
+	path := filepath.Join(s.dir, name)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# narmol evidence\n# phase:    %s\n# target:   %s\n# detail:   %s\n# captured: %s\n\n",
+		phase, target, detail, time.Now().UTC().Format(time.RFC3339))
+	b.WriteString("=== REQUEST ===\n")
+	b.WriteString(s.truncate(rec.Request))
+	b.WriteString("\n\n=== RESPONSE ===\n")
+	b.WriteString(s.truncate(rec.Response))
+	b.WriteString("\n")
+
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		fmt.Printf("[!] Could not write evidence %s: %s\n", path, err)
+		return ""
+	}
+	return path
+}
+
+func (s *Store) truncate(v string) string {
+	if len(v) <= s.maxBytes {
+		return v
+	}
+	return v[:s.maxBytes] + fmt.Sprintf("\n[... truncated %d bytes]", len(v)-s.maxBytes)
+}
+
+var unsafeName = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+func sanitize(s string) string {
+	s = unsafeName.ReplaceAllString(strings.ToLower(s), "_")
+	if s == "" {
+		return "finding"
+	}
+	return s
+}
@@ -0,0 +1,195 @@
+// Package export files narmol findings into external issue trackers.
+// Each unique finding fingerprint becomes exactly one issue; fingerprints
+// already present in the tracker are skipped so exports can be re-run safely.
+package export
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/FOUEN/narmol/internal/findings"
+)
+
+// Label is attached to every issue narmol creates. Trackers use it to list
+// previously filed issues when deduplicating.
+const Label = "narmol"
+
+// Issue is a tracker-agnostic issue ready to be filed.
+type Issue struct {
+	Title       string
+	Body        string
+	Severity    string
+	Labels      []string
+	Fingerprint string
+}
+
+// Tracker is an issue tracker backend (GitHub, GitLab, Jira).
+type Tracker interface {
+	// Name returns the tracker identifier.
+	Name() string
+	// Existing returns the fingerprints of issues already filed by narmol.
+	Existing(ctx context.Context) (map[string]bool, error)
+	// Create files a new issue and returns its URL or key.
+	Create(ctx context.Context, issue Issue) (string, error)
+}
+
+// Config holds the connection settings shared by all trackers.
+type Config struct {
+	Tracker string // "github", "gitlab", "jira"
+	BaseURL string // API base URL; empty = public default (github/gitlab)
+	Project string // "owner/repo" (github), project ID or path (gitlab), project key (jira)
+	Token   string
+	User    string // Jira only: account e-mail for basic auth
+	Client  *http.Client
+}
+
+// NewTracker builds the tracker selected by cfg.Tracker.
+func NewTracker(cfg Config) (Tracker, error) {
+	if cfg.Project == "" {
+		return nil, fmt.Errorf("--project is required")
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: 30 * time.Second}
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+
+	switch strings.ToLower(cfg.Tracker) {
+	case "github":
+		return newGitHub(cfg), nil
+	case "gitlab":
+		return newGitLab(cfg), nil
+	case "jira":
+		if cfg.BaseURL == "" {
+			return nil, fmt.Errorf("jira requires --url (e.g. https://yourorg.atlassian.net)")
+		}
+		return newJira(cfg), nil
+	default:
+		return nil, fmt.Errorf("unknown tracker %q (use github, gitlab or jira)", cfg.Tracker)
+	}
+}
+
+// Options controls an export run.
+type Options struct {
+	DryRun      bool
+	MinSeverity string // skip findings below this severity
+}
+
+// Result summarises an export run.
+type Result struct {
+	Created    []string // URLs/keys of created issues (or titles in dry-run)
+	Duplicates int      // findings already filed in the tracker
+	Skipped    int      // findings below the minimum severity
+	Failed     int
+}
+
+// Issues files one issue per unique finding fingerprint into t.
+func Issues(ctx context.Context, t Tracker, list []findings.Finding, opts Options) (Result, error) {
+	var res Result
+
+	list = findings.Dedup(list)
+	sort.SliceStable(list, func(i, j int) bool {
+		return findings.SeverityOrder(list[i].Severity) > findings.SeverityOrder(list[j].Severity)
+	})
+
+	existing, err := t.Existing(ctx)
+	if err != nil {
+		if !opts.DryRun {
+			return res, fmt.Errorf("could not list existing %s issues: %w", t.Name(), err)
+		}
+		fmt.Printf("[!] Could not list existing %s issues (dry-run continues): %s\n", t.Name(), err)
+		existing = map[string]bool{}
+	}
+
+	min := findings.SeverityOrder(opts.MinSeverity)
+	for _, f := range list {
+		if findings.SeverityOrder(f.Severity) < min {
+			res.Skipped++
+			continue
+		}
+		issue := NewIssue(f)
+		if existing[issue.Fingerprint] {
+			res.Duplicates++
+			continue
+		}
+
+		if opts.DryRun {
+			fmt.Printf("[dry-run] %s: %s\n", t.Name(), issue.Title)
+			res.Created = append(res.Created, issue.Title)
+			continue
+		}
+
+		ref, err := t.Create(ctx, issue)
+		if err != nil {
+			fmt.Printf("[!] Failed to create issue for %s: %s\n", f.Target, err)
+			res.Failed++
+			continue
+		}
+		existing[issue.Fingerprint] = true
+		fmt.Printf("[+] Created %s\n", ref)
+		res.Created = append(res.Created, ref)
+	}
+
+	return res, nil
+}
+
+// NewIssue renders a finding as a tracker issue.
+func NewIssue(f findings.Finding) Issue {
+	fp := f.Fingerprint()
+
+	title := fmt.Sprintf("[%s] %s on %s", strings.ToUpper(f.Severity), f.Title, f.Host)
+	if len(title) > 200 {
+		// Cut on a rune boundary: trackers reject invalid UTF-8.
+		n := 200
+		for n > 0 && !utf8.RuneStart(title[n]) {
+			n--
+		}
+		title = title[:n]
+	}
+
+	var b strings.Builder
+	b.WriteString("Finding reported by narmol.\n\n")
+	fmt.Fprintf(&b, "- Severity: %s\n", f.Severity)
+	fmt.Fprintf(&b, "- Phase: %s\n", f.Phase)
+	fmt.Fprintf(&b, "- Host: %s\n", f.Host)
+	fmt.Fprintf(&b, "- Target: %s\n", f.Target)
+	if f.TemplateID != "" {
+		fmt.Fprintf(&b, "- Nuclei template: %s\n", f.TemplateID)
+	}
+	if f.Evidence != "" {
+		fmt.Fprintf(&b, "- Evidence: %s\n", f.Evidence)
+	}
+	if f.Detail != "" {
+		fmt.Fprintf(&b, "\n%s\n", f.Detail)
+	}
+	fmt.Fprintf(&b, "\n%s\n", marker(fp))
+
+	return Issue{
+		Title:       title,
+		Body:        b.String(),
+		Severity:    f.Severity,
+		Labels:      []string{Label, "severity:" + f.Severity},
+		Fingerprint: fp,
+	}
+}
+
+// marker is embedded in issue bodies so fingerprints can be recovered later.
+func marker(fp string) string {
+	return "narmol:fingerprint=" + fp
+}
+
+var markerRe = regexp.MustCompile(`narmol:fingerprint=([0-9a-f]{16})`)
+
+// fingerprintsIn extracts all narmol fingerprints from an issue body.
+func fingerprintsIn(body string) []string {
+	var out []string
+	for _, m := range markerRe.FindAllStringSubmatch(body, -1) {
+		out = append(out, m[1])
+	}
+	return out
+}
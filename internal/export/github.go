@@ -0,0 +1,75 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// githubTracker files issues through the GitHub REST API.
+type githubTracker struct {
+	cfg Config
+}
+
+func newGitHub(cfg Config) *githubTracker {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = "https://api.github.com"
+	}
+	return &githubTracker{cfg: cfg}
+}
+
+func (t *githubTracker) Name() string { return "github" }
+
+func (t *githubTracker) auth(req *http.Request) {
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if t.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+t.cfg.Token)
+	}
+}
+
+// Existing lists every issue (open and closed) carrying the narmol label.
+func (t *githubTracker) Existing(ctx context.Context) (map[string]bool, error) {
+	seen := map[string]bool{}
+	for page := 1; ; page++ {
+		var issues []struct {
+			Body string `json:"body"`
+		}
+		url := fmt.Sprintf("%s/repos/%s/issues?labels=%s&state=all&per_page=100&page=%d",
+			t.cfg.BaseURL, t.cfg.Project, Label, page)
+		if _, err := doJSON(ctx, t.cfg.Client, "GET", url, nil, &issues, t.auth); err != nil {
+			return nil, err
+		}
+		for _, is := range issues {
+			for _, fp := range fingerprintsIn(is.Body) {
+				seen[fp] = true
+			}
+		}
+		if len(issues) < 100 {
+			return seen, nil
+		}
+	}
+}
+
+func (t *githubTracker) Create(ctx context.Context, issue Issue) (string, error) {
+	if !strings.Contains(t.cfg.Project, "/") {
+		return "", fmt.Errorf("github project must be owner/repo, got %q", t.cfg.Project)
+	}
+	payload := map[string]any{
+		"title":  issue.Title,
+		"body":   issue.Body,
+		"labels": issue.Labels,
+	}
+	var created struct {
+		HTMLURL string `json:"html_url"`
+		Number  int    `json:"number"`
+	}
+	url := fmt.Sprintf("%s/repos/%s/issues", t.cfg.BaseURL, t.cfg.Project)
+	if _, err := doJSON(ctx, t.cfg.Client, "POST", url, payload, &created, t.auth); err != nil {
+		return "", err
+	}
+	if created.HTMLURL != "" {
+		return created.HTMLURL, nil
+	}
+	return fmt.Sprintf("%s#%d", t.cfg.Project, created.Number), nil
+}
@@ -0,0 +1,77 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// gitlabTracker files issues through the GitLab REST API (v4).
+type gitlabTracker struct {
+	cfg Config
+}
+
+func newGitLab(cfg Config) *gitlabTracker {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = "https://gitlab.com/api/v4"
+	}
+	return &gitlabTracker{cfg: cfg}
+}
+
+func (t *gitlabTracker) Name() string { return "gitlab" }
+
+func (t *gitlabTracker) auth(req *http.Request) {
+	if t.cfg.Token != "" {
+		req.Header.Set("PRIVATE-TOKEN", t.cfg.Token)
+	}
+}
+
+// projectPath returns the URL-encoded project ID or "group/project" path.
+func (t *gitlabTracker) projectPath() string {
+	return url.PathEscape(t.cfg.Project)
+}
+
+// Existing lists every issue (open and closed) carrying the narmol label.
+func (t *gitlabTracker) Existing(ctx context.Context) (map[string]bool, error) {
+	seen := map[string]bool{}
+	for page := 1; ; page++ {
+		var issues []struct {
+			Description string `json:"description"`
+		}
+		u := fmt.Sprintf("%s/projects/%s/issues?labels=%s&scope=all&per_page=100&page=%d",
+			t.cfg.BaseURL, t.projectPath(), Label, page)
+		if _, err := doJSON(ctx, t.cfg.Client, "GET", u, nil, &issues, t.auth); err != nil {
+			return nil, err
+		}
+		for _, is := range issues {
+			for _, fp := range fingerprintsIn(is.Description) {
+				seen[fp] = true
+			}
+		}
+		if len(issues) < 100 {
+			return seen, nil
+		}
+	}
+}
+
+func (t *gitlabTracker) Create(ctx context.Context, issue Issue) (string, error) {
+	payload := map[string]any{
+		"title":       issue.Title,
+		"description": issue.Body,
+		"labels":      strings.Join(issue.Labels, ","),
+	}
+	var created struct {
+		WebURL string `json:"web_url"`
+		IID    int    `json:"iid"`
+	}
+	u := fmt.Sprintf("%s/projects/%s/issues", t.cfg.BaseURL, t.projectPath())
+	if _, err := doJSON(ctx, t.cfg.Client, "POST", u, payload, &created, t.auth); err != nil {
+		return "", err
+	}
+	if created.WebURL != "" {
+		return created.WebURL, nil
+	}
+	return fmt.Sprintf("%s#%d", t.cfg.Project, created.IID), nil
+}
@@ -0,0 +1,94 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// jiraTracker files issues through the Jira REST API (v2).
+// Jira has no free-form body search that is reliable across versions, so the
+// fingerprint is stored as a label ("narmol-<fingerprint>") as well as in the
+// description.
+type jiraTracker struct {
+	cfg Config
+}
+
+func newJira(cfg Config) *jiraTracker {
+	return &jiraTracker{cfg: cfg}
+}
+
+func (t *jiraTracker) Name() string { return "jira" }
+
+// auth uses basic auth (e-mail + API token) for Jira Cloud, or a bearer
+// personal access token for Jira Server/Data Center when no user is set.
+func (t *jiraTracker) auth(req *http.Request) {
+	if t.cfg.Token == "" {
+		return
+	}
+	if t.cfg.User != "" {
+		req.SetBasicAuth(t.cfg.User, t.cfg.Token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+t.cfg.Token)
+	}
+}
+
+// Existing lists every issue in the project carrying the narmol label.
+func (t *jiraTracker) Existing(ctx context.Context) (map[string]bool, error) {
+	seen := map[string]bool{}
+	// Quote the key as a JQL string literal.
+	project := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(t.cfg.Project)
+	jql := fmt.Sprintf(`project = "%s" AND labels = %s`, project, Label)
+
+	for startAt := 0; ; {
+		var page struct {
+			Total  int `json:"total"`
+			Issues []struct {
+				Fields struct {
+					Labels []string `json:"labels"`
+				} `json:"fields"`
+			} `json:"issues"`
+		}
+		u := fmt.Sprintf("%s/rest/api/2/search?jql=%s&fields=labels&startAt=%d&maxResults=100",
+			t.cfg.BaseURL, url.QueryEscape(jql), startAt)
+		if _, err := doJSON(ctx, t.cfg.Client, "GET", u, nil, &page, t.auth); err != nil {
+			return nil, err
+		}
+		for _, is := range page.Issues {
+			for _, l := range is.Fields.Labels {
+				if fp, ok := strings.CutPrefix(l, Label+"-"); ok {
+					seen[fp] = true
+				}
+			}
+		}
+		startAt += len(page.Issues)
+		if len(page.Issues) == 0 || startAt >= page.Total {
+			return seen, nil
+		}
+	}
+}
+
+func (t *jiraTracker) Create(ctx context.Context, issue Issue) (string, error) {
+	// Jira labels cannot contain spaces or colons in some versions.
+	labels := []string{Label, Label + "-" + issue.Fingerprint, "severity-" + issue.Severity}
+
+	payload := map[string]any{
+		"fields": map[string]any{
+			"project":     map[string]string{"key": t.cfg.Project},
+			"summary":     issue.Title,
+			"description": issue.Body,
+			"issuetype":   map[string]string{"name": "Bug"},
+			"labels":      labels,
+		},
+	}
+	var created struct {
+		Key string `json:"key"`
+	}
+	u := t.cfg.BaseURL + "/rest/api/2/issue"
+	if _, err := doJSON(ctx, t.cfg.Client, "POST", u, payload, &created, t.auth); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/browse/%s", t.cfg.BaseURL, created.Key), nil
+}
@@ -0,0 +1,272 @@
+// Package findings normalises the output of narmol workflows into a single
+// finding model so it can be deduplicated, exported and reported on.
+//
+// Workflows emit different JSON shapes (report objects with "phases" for
+// web/full, one JSON object per line for the mini-workflows). Load accepts
+// all of them and returns only actionable findings — entries that carry a
+// severity, or secrets. Pure discovery output (subdomains, URLs, live hosts,
+// open ports) is skipped.
+package findings
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Finding is a normalised, workflow-independent security finding.
+type Finding struct {
+	Phase      string `json:"phase"`                 // "vuln", "header", "tls", "secret", "takeover", ...
+	Host       string `json:"host"`                  // bare hostname (no scheme/port/path)
+	Target     string `json:"target"`                // URL or value the finding was reported on
+	Severity   string `json:"severity"`              // critical/high/medium/low/info
+	Title      string `json:"title"`                 // short human title
+	Detail     string `json:"detail,omitempty"`      // extra detail from the check
+	TemplateID string `json:"template_id,omitempty"` // nuclei template (vuln findings only)
+	Evidence   string `json:"evidence,omitempty"`    // path to the raw request/response proof
+	Source     string `json:"source,omitempty"`      // file the finding was loaded from
+
+	CVE  string  `json:"cve,omitempty"`  // nuclei classification
+	CVSS float64 `json:"cvss,omitempty"` // nuclei classification CVSS score
+	EPSS float64 `json:"epss,omitempty"` // nuclei classification EPSS score
+	Risk float64 `json:"risk,omitempty"` // 0–100 risk score, set by the run report
+}
+
+// Fingerprint returns a stable identifier for the finding. Two findings with
+// the same phase, host, target path, template and detail are considered the
+// same issue, regardless of which run or workflow produced them.
+func (f Finding) Fingerprint() string {
+	key := strings.Join([]string{
+		strings.ToLower(f.Phase),
+		strings.ToLower(f.Host),
+		targetKey(f.Target, f.Host),
+		strings.ToLower(f.TemplateID),
+		strings.TrimSpace(f.Detail),
+	}, "|")
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:8])
+}
+
+// targetKey normalises a target for fingerprinting: the path of a URL (so
+// http/https and query strings do not split an issue), "/" for the bare
+// host, otherwise the lowercased target.
+func targetKey(target, host string) string {
+	target = strings.TrimSpace(target)
+	if strings.EqualFold(target, host) {
+		return "/"
+	}
+	if u, err := url.Parse(target); err == nil && u.Scheme != "" && u.Host != "" {
+		if u.Path == "" {
+			return "/"
+		}
+		return u.Path
+	}
+	return strings.ToLower(target)
+}
+
+// Summary returns a one-line description of the finding.
+func (f Finding) Summary() string {
+	return fmt.Sprintf("[%s] %s — %s", strings.ToUpper(f.Severity), f.Target, f.Title)
+}
+
+// Load reads a narmol JSON output file (report object or JSON lines) and
+// returns the actionable findings it contains.
+func Load(path string) ([]Finding, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", path, err)
+	}
+	list, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", path, err)
+	}
+	for i := range list {
+		list[i].Source = path
+	}
+	return list, nil
+}
+
+// Parse extracts findings from raw narmol JSON output. It accepts a single
+// report object, a stream of concatenated objects, or JSON lines.
+func Parse(data []byte) ([]Finding, error) {
+	var out []Finding
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for dec.More() {
+		var obj map[string]any
+		if err := dec.Decode(&obj); err != nil {
+			return out, err
+		}
+		out = append(out, fromObject(obj)...)
+	}
+	return out, nil
+}
+
+// fromObject handles one decoded JSON value: a run report (already
+// normalised findings), a report with phases (web/full) or a single result line.
+func fromObject(obj map[string]any) []Finding {
+	if _, ok := obj["run_id"]; ok {
+		var out []Finding
+		if raw, err := json.Marshal(obj["findings"]); err == nil {
+			_ = json.Unmarshal(raw, &out)
+		}
+		return out
+	}
+
+	if phases, ok := obj["phases"].(map[string]any); ok {
+		// Iterate phases in a stable order so output is deterministic.
+		names := make([]string, 0, len(phases))
+		for name := range phases {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		var out []Finding
+		for _, name := range names {
+			list, ok := phases[name].([]any)
+			if !ok {
+				continue
+			}
+			for _, item := range list {
+				if m, ok := item.(map[string]any); ok {
+					if f, ok := FromMap(m); ok {
+						out = append(out, f)
+					}
+				}
+			}
+		}
+		return out
+	}
+
+	if f, ok := FromMap(obj); ok {
+		return []Finding{f}
+	}
+	return nil
+}
+
+// FromMap converts a single workflow result object into a Finding.
+// It returns false for entries that are not actionable findings.
+func FromMap(m map[string]any) (Finding, bool) {
+	get := func(keys ...string) string {
+		for _, k := range keys {
+			if v, ok := m[k].(string); ok && v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+
+	f := Finding{
+		Phase:      get("phase", "category"),
+		Target:     get("value", "url", "subdomain", "target"),
+		Severity:   strings.ToLower(get("severity")),
+		Detail:     get("detail"),
+		TemplateID: get("template_id"),
+		Evidence:   get("evidence"),
+		Host:       get("host"),
+		CVE:        get("cve"),
+	}
+	f.CVSS, _ = m["cvss"].(float64)
+	f.EPSS, _ = m["epss"].(float64)
+
+	// Takeover results carry a CNAME and service instead of a phase.
+	if cname := get("cname"); cname != "" && f.Phase == "" {
+		f.Phase = "takeover"
+		f.Title = fmt.Sprintf("Subdomain takeover (%s → %s)", get("service"), cname)
+		if get("method") == "dangling" {
+			f.Title = fmt.Sprintf("Dangling CNAME (→ %s)", cname)
+		}
+	}
+
+	// TruffleHog results from the secrets workflow have no severity.
+	if get("type") == "secret" && f.Phase == "" {
+		f.Phase = "secret"
+		f.Severity = "high"
+		if v, ok := m["verified"].(bool); ok && v {
+			f.Severity = "critical"
+		}
+		f.Detail = fmt.Sprintf("[%s] %s", get("detector_type"), get("redacted"))
+	}
+
+	if f.Severity == "" || f.Phase == "" || f.Target == "" {
+		return Finding{}, false
+	}
+
+	if f.Title == "" {
+		f.Title = get("vuln_name")
+	}
+	if f.Title == "" {
+		f.Title = f.Detail
+	}
+	if f.Title == "" {
+		f.Title = f.TemplateID
+	}
+
+	f.Host = Hostname(firstNonEmpty(f.Host, f.Target))
+	return f, true
+}
+
+// Hostname strips scheme, port and path from a URL or host string.
+func Hostname(target string) string {
+	target = strings.TrimSpace(target)
+	if strings.Contains(target, "://") {
+		if u, err := url.Parse(target); err == nil && u.Hostname() != "" {
+			return strings.ToLower(u.Hostname())
+		}
+	}
+	if idx := strings.Index(target, "/"); idx != -1 {
+		target = target[:idx]
+	}
+	if idx := strings.LastIndex(target, ":"); idx != -1 && !strings.Contains(target[idx:], "]") {
+		target = target[:idx]
+	}
+	return strings.ToLower(strings.Trim(target, "[]"))
+}
+
+// Dedup removes findings with a duplicate fingerprint, keeping the first.
+func Dedup(list []Finding) []Finding {
+	seen := make(map[string]bool, len(list))
+	out := make([]Finding, 0, len(list))
+	for _, f := range list {
+		fp := f.Fingerprint()
+		if seen[fp] {
+			continue
+		}
+		seen[fp] = true
+		out = append(out, f)
+	}
+	return out
+}
+
+// SeverityOrder ranks severities for sorting (critical highest).
+func SeverityOrder(s string) int {
+	switch strings.ToLower(s) {
+	case "critical":
+		return 5
+	case "high":
+		return 4
+	case "medium":
+		return 3
+	case "low":
+		return 2
+	case "info":
+		return 1
+	default:
+		return 0
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,397 @@
+// Package fuzz is an in-process content discoverer: it requests wordlist
+// paths (plus extensions) under a base URL, rate limited per host, and keeps
+// the answers that differ from the host's soft-404 behaviour. Each directory
+// is calibrated first with random paths, so servers answering 200 or a
+// redirect for anything do not flood the results.
+package fuzz
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/tls"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/FOUEN/narmol/internal/evidence"
+)
+
+const (
+	// DefaultThreads is the number of concurrent requests per host.
+	DefaultThreads = 20
+	// DefaultRateLimit caps requests per second per host.
+	DefaultRateLimit = 50
+
+	// maxBody is how much of each response is read for the comparison.
+	maxBody = 256 * 1024
+)
+
+var (
+	//go:embed small.txt
+	smallList string
+	//go:embed medium.txt
+	mediumList string
+)
+
+// Options tunes a fuzzing run. Zero values use the defaults.
+type Options struct {
+	Wordlist   string   // "small" (default), "medium" or a file with one path per line
+	Extensions []string // appended to every word without a dot ("php" → admin.php)
+	Recursion  int      // directory levels to descend into (0 = base only)
+	RateLimit  int      // requests per second per host
+	Threads    int
+
+	// Responses matching any of these are dropped.
+	FilterStatus []int
+	FilterSize   []int
+	FilterWords  []int
+}
+
+// Result is a path whose response differs from the soft-404 baseline.
+type Result struct {
+	URL      string
+	Path     string
+	Status   int
+	Size     int
+	Words    int
+	Location string
+	Dir      bool // redirects to itself with a trailing slash, or a listed "dir/" answering 2xx
+	Evidence *evidence.Record
+}
+
+// Stats summarises a run.
+type Stats struct {
+	Requests   int64
+	Found      int64
+	Calibrated int64 // answers dropped because they match the soft-404 baseline
+	Filtered   int64 // answers dropped by the status/size/word filters
+}
+
+// interesting are the statuses worth reporting; everything else (404, 400,
+// 429, most 5xx) is noise for content discovery.
+var interesting = map[int]bool{
+	200: true, 201: true, 204: true, 206: true,
+	301: true, 302: true, 307: true, 308: true,
+	401: true, 403: true, 405: true, 500: true,
+}
+
+// LoadWordlist returns the paths of the named built-in list ("small",
+// "medium"; empty = small) or of a file. Leading slashes are stripped.
+func LoadWordlist(name string) ([]string, error) {
+	var lines []string
+	switch name {
+	case "", "small":
+		lines = strings.Split(smallList, "\n")
+	case "medium":
+		lines = strings.Split(mediumList, "\n")
+	default:
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, fmt.Errorf("could not open %s: %w", name, err)
+		}
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", name, err)
+		}
+	}
+
+	seen := map[string]bool{}
+	var words []string
+	for _, line := range lines {
+		w := strings.TrimLeft(strings.TrimSpace(line), "/")
+		if w == "" || strings.HasPrefix(w, "#") || strings.ContainsAny(w, " \t") || seen[w] {
+			continue
+		}
+		seen[w] = true
+		words = append(words, w)
+	}
+	return words, nil
+}
+
+// Signature is what a response looks like for the soft-404 comparison. The
+// requested word is masked out of the body and the Location header so pages
+// echoing the path still compare equal.
+type Signature struct {
+	status   int
+	size     int
+	words    int
+	location string
+}
+
+// NewSignature builds the Signature of a response to a request for word.
+func NewSignature(status int, location string, body []byte, word string) Signature {
+	word = strings.TrimSuffix(word, "/")
+	masked := string(body)
+	if word != "" {
+		masked = strings.ReplaceAll(masked, word, "")
+		location = strings.ReplaceAll(location, word, "{word}")
+	}
+	return Signature{
+		status:   status,
+		size:     len(masked),
+		words:    len(strings.Fields(masked)),
+		location: location,
+	}
+}
+
+// Matches reports whether two responses look the same (b is a soft-404 when
+// a is a calibration response): same status, Location and word count, and
+// sizes within sizeTolerance of each other (dynamic tokens, timestamps).
+func (a Signature) Matches(b Signature) bool {
+	if a.status != b.status || a.location != b.location || a.words != b.words {
+		return false
+	}
+	diff := a.size - b.size
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff <= sizeTolerance(a.size)
+}
+
+// sizeTolerance is how far a soft-404 may drift from its calibration size:
+// 2% of the page, at least 16 bytes.
+func sizeTolerance(size int) int {
+	if t := size / 50; t > 16 {
+		return t
+	}
+	return 16
+}
+
+// fuzzer holds the per-host state of a run.
+type fuzzer struct {
+	base   string
+	opts   Options
+	words  []string
+	client *http.Client
+	ticker *time.Ticker
+	stats  Stats
+}
+
+// Run fuzzes base (scheme://host[:port]) and calls found for every path
+// that survives calibration and filters. found may be called concurrently.
+func Run(base string, opts Options, found func(Result)) (Stats, error) {
+	words, err := LoadWordlist(opts.Wordlist)
+	if err != nil {
+		return Stats{}, err
+	}
+	if opts.Threads <= 0 {
+		opts.Threads = DefaultThreads
+	}
+	if opts.RateLimit <= 0 {
+		opts.RateLimit = DefaultRateLimit
+	}
+
+	f := &fuzzer{
+		base:  strings.TrimRight(base, "/"),
+		opts:  opts,
+		words: words,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+			Transport: &http.Transport{
+				TLSClientConfig:     &tls.Config{InsecureSkipVerify: true},
+				MaxIdleConnsPerHost: opts.Threads,
+				DialContext: (&net.Dialer{
+					Timeout: 5 * time.Second,
+				}).DialContext,
+			},
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse // redirects are results, not hops
+			},
+		},
+		ticker: time.NewTicker(time.Second / time.Duration(opts.RateLimit)),
+	}
+	defer f.ticker.Stop()
+
+	// Breadth-first over directories, one calibration per directory.
+	type dir struct {
+		path  string
+		depth int
+	}
+	queue := []dir{{"/", 0}}
+	visited := map[string]bool{"/": true}
+	for len(queue) > 0 {
+		d := queue[0]
+		queue = queue[1:]
+
+		var mu sync.Mutex
+		var subdirs []string
+		f.fuzzDir(d.path, func(r Result) {
+			if r.Dir && d.depth < opts.Recursion {
+				mu.Lock()
+				subdirs = append(subdirs, r.Path+"/")
+				mu.Unlock()
+			}
+			found(r)
+		})
+		for _, p := range subdirs {
+			if !visited[p] {
+				visited[p] = true
+				queue = append(queue, dir{p, d.depth + 1})
+			}
+		}
+	}
+	return f.stats, nil
+}
+
+// fuzzDir calibrates dir and requests every candidate below it.
+func (f *fuzzer) fuzzDir(dir string, found func(Result)) {
+	baseline := f.calibrate(dir)
+
+	work := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < f.opts.Threads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for word := range work {
+				f.try(dir, word, baseline, found)
+			}
+		}()
+	}
+	for _, word := range f.candidates() {
+		work <- word
+	}
+	close(work)
+	wg.Wait()
+}
+
+// try requests dir+word and reports it unless it is a soft-404 or filtered.
+func (f *fuzzer) try(dir, word string, baseline []Signature, found func(Result)) {
+	path := dir + word
+	resp, sig, body, req, err := f.request(path, word)
+	if err != nil || !interesting[sig.status] {
+		return
+	}
+	for _, b := range baseline {
+		if b.Matches(sig) {
+			atomic.AddInt64(&f.stats.Calibrated, 1)
+			return
+		}
+	}
+	if f.filtered(sig, len(body)) {
+		atomic.AddInt64(&f.stats.Filtered, 1)
+		return
+	}
+
+	location := resp.Header.Get("Location")
+	atomic.AddInt64(&f.stats.Found, 1)
+	found(Result{
+		URL:      f.base + path,
+		Path:     strings.TrimSuffix(path, "/"),
+		Status:   sig.status,
+		Size:     len(body),
+		Words:    sig.words,
+		Location: location,
+		Dir:      isDirRedirect(path, location) || (strings.HasSuffix(path, "/") && sig.status < 300),
+		Evidence: evidence.HTTP(req, resp, body),
+	})
+}
+
+// candidates returns every word, plus word.ext for words without a dot.
+func (f *fuzzer) candidates() []string {
+	seen := map[string]bool{}
+	out := make([]string, 0, len(f.words)*(1+len(f.opts.Extensions)))
+	add := func(c string) {
+		if !seen[c] {
+			seen[c] = true
+			out = append(out, c)
+		}
+	}
+	for _, w := range f.words {
+		add(w)
+		if strings.Contains(w, ".") || strings.HasSuffix(w, "/") {
+			continue
+		}
+		for _, ext := range f.opts.Extensions {
+			add(w + "." + strings.TrimPrefix(ext, "."))
+		}
+	}
+	return out
+}
+
+// calibrate requests random paths shaped like the candidates (bare word,
+// dotfile, directory, each extension) and returns their signatures.
+func (f *fuzzer) calibrate(dir string) []Signature {
+	probes := []string{randomWord(), "." + randomWord(), randomWord() + "/", randomWord() + ".html"}
+	for _, ext := range f.opts.Extensions {
+		probes = append(probes, randomWord()+"."+strings.TrimPrefix(ext, "."))
+	}
+
+	var out []Signature
+	for _, word := range probes {
+		if _, sig, _, _, err := f.request(dir+word, word); err == nil {
+			out = append(out, sig)
+		}
+	}
+	return out
+}
+
+func (f *fuzzer) request(path, word string) (*http.Response, Signature, []byte, *http.Request, error) {
+	<-f.ticker.C
+	atomic.AddInt64(&f.stats.Requests, 1)
+
+	req, err := http.NewRequest("GET", f.base+path, nil)
+	if err != nil {
+		return nil, Signature{}, nil, nil, err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, Signature{}, nil, nil, err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxBody))
+
+	return resp, NewSignature(resp.StatusCode, resp.Header.Get("Location"), body, word), body, req, nil
+}
+
+func (f *fuzzer) filtered(sig Signature, size int) bool {
+	for _, s := range f.opts.FilterStatus {
+		if sig.status == s {
+			return true
+		}
+	}
+	for _, s := range f.opts.FilterSize {
+		if size == s {
+			return true
+		}
+	}
+	for _, w := range f.opts.FilterWords {
+		if sig.words == w {
+			return true
+		}
+	}
+	return false
+}
+
+// isDirRedirect reports whether location points at path with a trailing
+// slash (how servers answer a directory requested without one).
+func isDirRedirect(path, location string) bool {
+	if location == "" || strings.HasSuffix(path, "/") {
+		return false
+	}
+	u, err := url.Parse(location)
+	if err != nil {
+		return false
+	}
+	return u.Path == path+"/"
+}
+
+func randomWord() string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return "narmol" + hex.EncodeToString(b)
+}
@@ -0,0 +1,158 @@
+// Package gauconf holds the gau settings shared by every workflow that pulls
+// historical URLs (recon, urls, full, and params/jsanalyze through
+// urls.Collect): provider selection, date range, status-code and MIME
+// filters, the urlscan API key and an extension blacklist. gau applies its
+// blacklist in its CLI output writer, not in the providers, so Keep does it
+// here. A built-in blacklist drops images, fonts and media by default.
+package gauconf
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	mapset "github.com/deckarep/golang-set/v2"
+	gau_providers "github.com/lc/gau/v2/pkg/providers"
+	"github.com/valyala/fasthttp"
+)
+
+// Providers gau knows about.
+var Providers = []string{"wayback", "commoncrawl", "otx", "urlscan"}
+
+// DefaultProviders are used when none are selected. commoncrawl is left out:
+// its index is slow and often unreachable.
+var DefaultProviders = []string{"wayback", "otx", "urlscan"}
+
+// DefaultBlacklist is the noise dropped from gau output unless
+// NoDefaultBlacklist is set: images, fonts and media.
+var DefaultBlacklist = []string{
+	"png", "jpg", "jpeg", "gif", "svg", "ico", "webp", "bmp", "tif", "tiff",
+	"woff", "woff2", "ttf", "eot", "otf",
+	"mp4", "mp3", "avi", "mov", "webm", "wav",
+}
+
+// Options configures gau. The zero value is the default setup.
+type Options struct {
+	Providers    []string // empty = DefaultProviders
+	From, To     string   // YYYYMM[DD…], wayback and commoncrawl only
+	MatchStatus  []string // keep only these status codes (wayback, commoncrawl)
+	FilterStatus []string // drop these status codes (wayback, commoncrawl)
+	MatchMIME    []string // keep only these MIME types (wayback, commoncrawl)
+	FilterMIME   []string // drop these MIME types (wayback, commoncrawl)
+
+	// Blacklist adds extensions (without the dot) to DefaultBlacklist;
+	// NoDefaultBlacklist keeps only these.
+	Blacklist          []string
+	NoDefaultBlacklist bool
+
+	// URLScanKey is the urlscan.io API key (default: $URLSCAN_API_KEY).
+	URLScanKey string
+}
+
+// Validate checks provider names and dates.
+func (o Options) Validate() error {
+	for _, p := range o.Providers {
+		known := false
+		for _, k := range Providers {
+			if p == k {
+				known = true
+			}
+		}
+		if !known {
+			return fmt.Errorf("unknown gau provider %q (use %s)", p, strings.Join(Providers, ", "))
+		}
+	}
+	for _, d := range []string{o.From, o.To} {
+		if d == "" {
+			continue
+		}
+		if len(d) < 4 || len(d) > 14 || strings.Trim(d, "0123456789") != "" {
+			return fmt.Errorf("invalid gau date %q: use YYYYMM (e.g. 202301)", d)
+		}
+	}
+	return nil
+}
+
+// ProviderNames returns the providers to run.
+func (o Options) ProviderNames() []string {
+	if len(o.Providers) == 0 {
+		return DefaultProviders
+	}
+	return o.Providers
+}
+
+// Config returns gau's provider config.
+func (o Options) Config() *gau_providers.Config {
+	key := o.URLScanKey
+	if key == "" {
+		key = os.Getenv("URLSCAN_API_KEY")
+	}
+	return &gau_providers.Config{
+		Threads:           5,
+		Timeout:           45,
+		MaxRetries:        3,
+		IncludeSubdomains: true,
+		RemoveParameters:  false,
+		Client: &fasthttp.Client{
+			TLSConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+		Blacklist: mapset.NewThreadUnsafeSet(""),
+		URLScan:   gau_providers.URLScan{APIKey: key},
+	}
+}
+
+// Filters returns gau's archive filters.
+func (o Options) Filters() gau_providers.Filters {
+	return gau_providers.Filters{
+		From:              o.From,
+		To:                o.To,
+		MatchStatusCodes:  o.MatchStatus,
+		FilterStatusCodes: o.FilterStatus,
+		MatchMimeTypes:    o.MatchMIME,
+		FilterMimeTypes:   o.FilterMIME,
+	}
+}
+
+// Describe is the one-line summary printed when gau starts.
+func (o Options) Describe() string {
+	out := strings.Join(o.ProviderNames(), ", ")
+	if o.From != "" || o.To != "" {
+		out += fmt.Sprintf(", %s–%s", or(o.From, "…"), or(o.To, "…"))
+	}
+	return out
+}
+
+func or(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
+
+// Keep reports whether a gau URL passes the extension blacklist.
+func (o Options) Keep(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	ext := strings.TrimPrefix(strings.ToLower(path.Ext(u.Path)), ".")
+	if ext == "" {
+		return true
+	}
+	for _, b := range o.Blacklist {
+		if strings.TrimPrefix(strings.ToLower(b), ".") == ext {
+			return false
+		}
+	}
+	if !o.NoDefaultBlacklist {
+		for _, b := range DefaultBlacklist {
+			if b == ext {
+				return false
+			}
+		}
+	}
+	return true
+}
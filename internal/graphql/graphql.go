@@ -0,0 +1,259 @@
+// Package graphql finds GraphQL endpoints on a web host and audits them:
+// introspection (with the schema dumped), field suggestions, query
+// batching, mutations over GET and the absence of a query depth limit.
+package graphql
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/FOUEN/narmol/internal/evidence"
+)
+
+// Paths are the endpoint locations tried on each host, most common first.
+var Paths = []string{
+	"/graphql", "/api/graphql", "/v1/graphql", "/v2/graphql", "/graphql/v1",
+	"/api/v1/graphql", "/query", "/gql", "/api/gql", "/graphql/api", "/graph",
+}
+
+// Check names.
+const (
+	CheckIntrospection = "introspection"
+	CheckSuggestions   = "field-suggestions"
+	CheckBatching      = "batching"
+	CheckGETMutation   = "get-mutation"
+	CheckDepth         = "no-depth-limit"
+)
+
+// depthProbe nests ofType selections 15 levels deep; servers with a depth
+// limit reject it before execution.
+const depthProbe = "{__schema{queryType{fields{type{ofType{ofType{ofType{ofType{ofType{ofType{ofType{ofType{ofType{ofType{ofType{name}}}}}}}}}}}}}}}}"
+
+// introspectionQuery is the standard introspection query, trimmed to what
+// rebuilds a schema.
+const introspectionQuery = `query IntrospectionQuery{__schema{queryType{name}mutationType{name}subscriptionType{name}types{...FullType}directives{name description locations args{...InputValue}}}}
+fragment FullType on __Type{kind name description fields(includeDeprecated:true){name description args{...InputValue}type{...TypeRef}isDeprecated deprecationReason}inputFields{...InputValue}interfaces{...TypeRef}enumValues(includeDeprecated:true){name description isDeprecated deprecationReason}possibleTypes{...TypeRef}}
+fragment InputValue on __InputValue{name description type{...TypeRef}defaultValue}
+fragment TypeRef on __Type{kind name ofType{kind name ofType{kind name ofType{kind name ofType{kind name ofType{kind name ofType{kind name ofType{kind name}}}}}}}}`
+
+const maxBody = 8 * 1024 * 1024
+
+// Issue is one problem found on an endpoint.
+type Issue struct {
+	Check    string
+	Severity string
+	Detail   string
+	Evidence *evidence.Record
+}
+
+// Audit is the result for one endpoint.
+type Audit struct {
+	Endpoint string
+	Issues   []Issue
+	Schema   []byte // introspection response, when introspection is enabled
+	Types    int    // number of types in the schema
+}
+
+// response is a GraphQL answer.
+type response struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func (r response) hasData() bool {
+	return len(r.Data) > 0 && string(r.Data) != "null"
+}
+
+func (r response) messages() string {
+	var b strings.Builder
+	for _, e := range r.Errors {
+		b.WriteString(e.Message + "\n")
+	}
+	return b.String()
+}
+
+// Discover returns the first GraphQL endpoint among Paths on base
+// (scheme://host[:port]), or "" when none answers like GraphQL.
+func Discover(client *http.Client, base string) string {
+	base = strings.TrimRight(base, "/")
+	for _, p := range Paths {
+		endpoint := base + p
+		r, _, _, err := post(client, endpoint, map[string]string{"query": "{__typename}"})
+		if err != nil {
+			continue
+		}
+		if r.hasData() && strings.Contains(string(r.Data), "__typename") {
+			return endpoint
+		}
+		// Servers that reject the probe still answer with GraphQL errors.
+		if len(r.Errors) > 0 && !r.hasData() && looksGraphQL(r.messages()) {
+			return endpoint
+		}
+	}
+	return ""
+}
+
+// Run audits a GraphQL endpoint.
+func Run(client *http.Client, endpoint string) Audit {
+	a := Audit{Endpoint: endpoint}
+
+	// Introspection
+	r, ev, body, err := post(client, endpoint, map[string]string{"query": introspectionQuery})
+	if err == nil && r.hasData() && strings.Contains(string(r.Data), "__schema") {
+		var schema struct {
+			Schema struct {
+				Types        []json.RawMessage `json:"types"`
+				MutationType *struct {
+					Name string `json:"name"`
+				} `json:"mutationType"`
+			} `json:"__schema"`
+		}
+		_ = json.Unmarshal(r.Data, &schema)
+		a.Schema = body
+		a.Types = len(schema.Schema.Types)
+		detail := "Introspection enabled — full schema exposed"
+		if a.Types > 0 {
+			detail += " (" + strconv.Itoa(a.Types) + " types"
+			if schema.Schema.MutationType != nil {
+				detail += ", mutations"
+			}
+			detail += ")"
+		}
+		a.Issues = append(a.Issues, Issue{Check: CheckIntrospection, Severity: "medium", Detail: detail, Evidence: ev})
+	}
+
+	// Field suggestions ("Did you mean …") leak the schema even with
+	// introspection off.
+	if r, ev, _, err := post(client, endpoint, map[string]string{"query": "{__typenam}"}); err == nil {
+		if strings.Contains(strings.ToLower(r.messages()), "did you mean") {
+			a.Issues = append(a.Issues, Issue{Check: CheckSuggestions, Severity: "low",
+				Detail: "Field suggestions enabled — schema can be recovered without introspection", Evidence: ev})
+		}
+	}
+
+	// Batching: an array of operations answered as an array.
+	batch := []map[string]string{{"query": "{__typename}"}, {"query": "{__typename}"}, {"query": "{__typename}"}}
+	if body, ev, err := send(client, "POST", endpoint, batch); err == nil {
+		var answers []response
+		if json.Unmarshal(body, &answers) == nil && len(answers) == len(batch) {
+			a.Issues = append(a.Issues, Issue{Check: CheckBatching, Severity: "low",
+				Detail:   "Query batching enabled — rate limits and brute-force protections can be bypassed in one request",
+				Evidence: ev})
+		}
+	}
+
+	// Mutations over GET are CSRF-able.
+	getURL := endpoint + "?query=" + url.QueryEscape("mutation{__typename}")
+	if body, ev, err := send(client, "GET", getURL, nil); err == nil {
+		var r response
+		if json.Unmarshal(body, &r) == nil && r.hasData() && strings.Contains(string(r.Data), "__typename") {
+			a.Issues = append(a.Issues, Issue{Check: CheckGETMutation, Severity: "medium",
+				Detail:   "Mutations accepted over GET — state-changing operations are exposed to CSRF",
+				Evidence: ev})
+		}
+	}
+
+	// Depth limit. The probe nests introspection fields, the only ones
+	// known without a schema, so it cannot run with introspection off.
+	if a.Schema == nil {
+		a.Issues = append(a.Issues, Issue{Check: CheckDepth, Severity: "info",
+			Detail: "Query depth limit not tested — the probe needs introspection, which is disabled"})
+		return a
+	}
+	if r, ev, _, err := post(client, endpoint, map[string]string{"query": depthProbe}); err == nil {
+		if r.hasData() && len(r.Errors) == 0 && strings.Contains(string(r.Data), "queryType") {
+			a.Issues = append(a.Issues, Issue{Check: CheckDepth, Severity: "low",
+				Detail: "No query depth limit — a 15-level nested introspection query was executed", Evidence: ev})
+		}
+	}
+	return a
+}
+
+// looksGraphQL reports whether error messages come from a GraphQL server.
+func looksGraphQL(messages string) bool {
+	m := strings.ToLower(messages)
+	for _, s := range []string{"graphql", "cannot query field", "syntax error", "must provide query", "__typename", "unknown operation"} {
+		if strings.Contains(m, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// SaveSchema writes the introspection answer for endpoint to dir as
+// graphql-<host>-<hash>.json and returns its path.
+func SaveSchema(dir, endpoint string, schema []byte) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	host := "unknown"
+	if u, err := url.Parse(endpoint); err == nil && u.Host != "" {
+		host = unsafeName.ReplaceAllString(strings.ToLower(u.Host), "_")
+	}
+	sum := sha256.Sum256([]byte(endpoint))
+	path := filepath.Join(dir, fmt.Sprintf("graphql-%s-%s.json", host, hex.EncodeToString(sum[:4])))
+	var pretty bytes.Buffer
+	if json.Indent(&pretty, schema, "", "  ") == nil {
+		schema = pretty.Bytes()
+	}
+	return path, os.WriteFile(path, schema, 0644)
+}
+
+var unsafeName = regexp.MustCompile(`[^a-z0-9.-]+`)
+
+func post(client *http.Client, endpoint string, payload any) (response, *evidence.Record, []byte, error) {
+	body, ev, err := send(client, "POST", endpoint, payload)
+	if err != nil {
+		return response{}, nil, nil, err
+	}
+	var r response
+	if err := json.Unmarshal(body, &r); err != nil {
+		return response{}, nil, nil, err
+	}
+	return r, ev, body, nil
+}
+
+// send issues a GraphQL request (payload as a JSON body, none for GET) and
+// returns the response body and the exchange as evidence, query included.
+func send(client *http.Client, method, u string, payload any) ([]byte, *evidence.Record, error) {
+	var reader io.Reader
+	var raw []byte
+	if payload != nil {
+		raw, _ = json.Marshal(payload)
+		reader = bytes.NewReader(raw)
+	}
+	req, err := http.NewRequest(method, u, reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBody))
+	if err != nil {
+		return nil, nil, err
+	}
+	ev := evidence.HTTP(req, resp, body)
+	ev.Request += string(raw)
+	return body, ev, nil
+}
@@ -0,0 +1,90 @@
+package params
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	formRe  = regexp.MustCompile(`(?is)<form\b([^>]*)>(.*?)</form>`)
+	fieldRe = regexp.MustCompile(`(?is)<(?:input|select|textarea|button)\b([^>]*)>`)
+	attrRe  = regexp.MustCompile(`(?is)\b(action|method|name)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))`)
+	hrefRe  = regexp.MustCompile(`(?is)\b(?:href|src|action)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+
+	// JavaScript sources of parameter names: query strings in literals,
+	// URLSearchParams/FormData accessors and object keys passed as params.
+	jsQueryRe  = regexp.MustCompile(`[?&]([A-Za-z_][A-Za-z0-9_.\-\[\]]{0,63})=`)
+	jsAccessRe = regexp.MustCompile(`\.(?:get|set|append|has|getAll)\(\s*["']([A-Za-z_][A-Za-z0-9_.\-\[\]]{0,63})["']`)
+	jsParamsRe = regexp.MustCompile(`(?s)\b(?:params|data|query|body|searchParams)\s*:\s*\{([^{}]{0,1000})\}`)
+	jsKeyRe    = regexp.MustCompile(`(?:^|[,{\s])["']?([A-Za-z_][A-Za-z0-9_]{0,63})["']?\s*:`)
+)
+
+// AddPage records the parameters of the forms and links of an HTML page.
+// Form fields are attributed to the form action, resolved against pageURL.
+func (inv *Inventory) AddPage(pageURL string, body []byte) {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return
+	}
+	page := string(body)
+
+	for _, form := range formRe.FindAllStringSubmatch(page, -1) {
+		attrs := attributes(form[1])
+		action := base
+		if a := attrs["action"]; a != "" {
+			if u, err := base.Parse(html.UnescapeString(a)); err == nil {
+				action = u
+			}
+		}
+		method := strings.ToUpper(attrs["method"])
+		for _, field := range fieldRe.FindAllStringSubmatch(form[2], -1) {
+			if name := attributes(field[1])["name"]; name != "" {
+				inv.Add(action.String(), html.UnescapeString(name), SourceForm)
+			}
+		}
+		inv.AddURL(action.String(), SourceForm)
+		inv.setMethod(action.String(), method)
+	}
+
+	for _, m := range hrefRe.FindAllStringSubmatch(page, -1) {
+		ref := html.UnescapeString(m[1] + m[2])
+		if !strings.Contains(ref, "?") {
+			continue
+		}
+		if u, err := base.Parse(ref); err == nil && u.Host == base.Host {
+			inv.AddURL(u.String(), SourceLink)
+		}
+	}
+}
+
+// AddJS records parameter names referenced by JavaScript. Script code
+// rarely says which endpoint a name belongs to, so every name is attributed
+// to endpoint (usually the page or host root that loaded the script).
+func (inv *Inventory) AddJS(endpoint string, script []byte) {
+	js := string(script)
+	for _, m := range jsQueryRe.FindAllStringSubmatch(js, -1) {
+		inv.Add(endpoint, m[1], SourceJS)
+	}
+	for _, m := range jsAccessRe.FindAllStringSubmatch(js, -1) {
+		inv.Add(endpoint, m[1], SourceJS)
+	}
+	for _, obj := range jsParamsRe.FindAllStringSubmatch(js, -1) {
+		for _, k := range jsKeyRe.FindAllStringSubmatch(obj[1], -1) {
+			inv.Add(endpoint, k[1], SourceJS)
+		}
+	}
+}
+
+// attributes returns the action, method and name attributes of a tag.
+func attributes(tag string) map[string]string {
+	out := map[string]string{}
+	for _, m := range attrRe.FindAllStringSubmatch(tag, -1) {
+		key := strings.ToLower(m[1])
+		if _, ok := out[key]; !ok {
+			out[key] = m[2] + m[3] + m[4]
+		}
+	}
+	return out
+}
@@ -0,0 +1,209 @@
+package params
+
+import (
+	"crypto/rand"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// DefaultChunkSize is how many candidate names are sent per request.
+const DefaultChunkSize = 40
+
+// maxBody is how much of each response is read for the comparison.
+const maxBody = 512 * 1024
+
+//go:embed wordlist.txt
+var defaultWordlist string
+
+// LoadWords returns the parameter names in file (one per line), or the
+// built-in list when file is empty.
+func LoadWords(file string) ([]string, error) {
+	content := defaultWordlist
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("could not read %s: %w", file, err)
+		}
+		content = string(data)
+	}
+	seen := map[string]bool{}
+	var words []string
+	for _, line := range strings.Split(content, "\n") {
+		w := strings.TrimSpace(line)
+		if w == "" || strings.HasPrefix(w, "#") || !validName.MatchString(w) || seen[w] {
+			continue
+		}
+		seen[w] = true
+		words = append(words, w)
+	}
+	return words, nil
+}
+
+// Hit is a hidden parameter confirmed by Mine.
+type Hit struct {
+	Param  string
+	Reason string // "reflected", "status", "length"
+}
+
+// response is what a request looks like for the comparison.
+type response struct {
+	status int
+	words  int
+	lines  int
+	body   string
+}
+
+// Mine brute-forces hidden GET parameters of endpoint, Arjun/x8 style:
+// names are sent in chunks with unique random values, and a chunk whose
+// response differs from the baseline (status, word/line count, or one of
+// its values reflected) is split in halves until the responsible names are
+// isolated. skip lists names that are already known. It returns the hits
+// and the number of requests sent.
+func Mine(client *http.Client, endpoint string, words, skip []string, chunkSize int) ([]Hit, int, error) {
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	known := map[string]bool{}
+	for _, n := range skip {
+		known[n] = true
+	}
+	var candidates []string
+	for _, w := range words {
+		if !known[w] {
+			known[w] = true
+			candidates = append(candidates, w)
+		}
+	}
+
+	m := &miner{client: client, endpoint: endpoint}
+
+	// Two baselines with a random parameter: when they disagree the page is
+	// too dynamic to diff, and only reflection is trusted.
+	probe := randomHex()
+	base, err := m.send(map[string]string{randomHex(): probe})
+	if err != nil {
+		return nil, m.requests, fmt.Errorf("baseline failed: %w", err)
+	}
+	again, err := m.send(map[string]string{randomHex(): randomHex()})
+	if err != nil {
+		return nil, m.requests, fmt.Errorf("baseline failed: %w", err)
+	}
+	m.base = base
+	m.stable = base.status == again.status && base.words == again.words && base.lines == again.lines
+	// Pages echoing the whole URL (canonical links, forms) reflect any value.
+	m.echoes = strings.Contains(base.body, probe)
+	if !m.stable && m.echoes {
+		return nil, m.requests, fmt.Errorf("response too dynamic to diff")
+	}
+
+	var hits []Hit
+	for i := 0; i < len(candidates); i += chunkSize {
+		end := i + chunkSize
+		if end > len(candidates) {
+			end = len(candidates)
+		}
+		hits = append(hits, m.bisect(candidates[i:end])...)
+	}
+	return hits, m.requests, nil
+}
+
+type miner struct {
+	client   *http.Client
+	endpoint string
+	base     response
+	stable   bool
+	echoes   bool
+	requests int
+}
+
+// bisect returns the names in chunk that change the response.
+func (m *miner) bisect(chunk []string) []Hit {
+	values := make(map[string]string, len(chunk))
+	for _, n := range chunk {
+		values[n] = randomHex()
+	}
+	resp, err := m.send(values)
+	if err != nil {
+		return nil
+	}
+	reason := m.diff(resp, values)
+	if reason == "" {
+		return nil
+	}
+	if len(chunk) == 1 {
+		return []Hit{{Param: chunk[0], Reason: reason}}
+	}
+	mid := len(chunk) / 2
+	return append(m.bisect(chunk[:mid]), m.bisect(chunk[mid:])...)
+}
+
+// diff explains how resp differs from the baseline, or returns "".
+func (m *miner) diff(resp response, values map[string]string) string {
+	if !m.echoes {
+		for _, v := range values {
+			if strings.Contains(resp.body, v) {
+				return "reflected"
+			}
+		}
+	}
+	if !m.stable {
+		return ""
+	}
+	if resp.status != m.base.status {
+		return "status"
+	}
+	if resp.words != m.base.words || resp.lines != m.base.lines {
+		return "length"
+	}
+	return ""
+}
+
+func (m *miner) send(values map[string]string) (response, error) {
+	u, err := url.Parse(m.endpoint)
+	if err != nil {
+		return response{}, err
+	}
+	q := u.Query()
+	for k, v := range values {
+		q.Set(k, v)
+	}
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return response{}, err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
+	m.requests++
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return response{}, err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxBody))
+
+	// Values are masked so a page echoing the whole query string does not
+	// count as a length change.
+	masked := string(body)
+	for _, v := range values {
+		masked = strings.ReplaceAll(masked, v, "")
+	}
+	return response{
+		status: resp.StatusCode,
+		words:  len(strings.Fields(masked)),
+		lines:  strings.Count(masked, "\n"),
+		body:   string(body),
+	}, nil
+}
+
+func randomHex() string {
+	b := make([]byte, 5)
+	rand.Read(b)
+	return "n" + hex.EncodeToString(b)
+}
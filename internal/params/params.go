@@ -0,0 +1,241 @@
+// Package params builds a per-endpoint inventory of request parameters.
+// Names are collected from URLs (gau, katana), HTML forms and links, and
+// JavaScript, and hidden ones are mined by brute-force with response
+// diffing (Mine). Checks that need injection points (open redirect, XSS,
+// SSRF) read the inventory through Targets.
+package params
+
+import (
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Source values recorded for each parameter.
+const (
+	SourceForm  = "form"
+	SourceLink  = "link"
+	SourceJS    = "js"
+	SourceMined = "mined"
+)
+
+// Check classes a parameter can feed.
+const (
+	ClassRedirect = "redirect"
+	ClassSSRF     = "ssrf"
+	ClassXSS      = "xss" // every parameter is an XSS candidate
+)
+
+var (
+	redirectNames = regexp.MustCompile(`(?i)^(url|uri|redirect(_?ur[li])?|return(_?url|_?to)?|returnto|next|goto|target|to|dest(ination)?|r?url|continue|forward|out|view|login_url|callback|success_url|back(url)?)$`)
+	ssrfNames     = regexp.MustCompile(`(?i)(^|_)(url|uri|link|href|src|host|domain|site|proxy|feed|webhook|callback|image|img|fetch|load|dest|destination|endpoint|server|path|file|document|preview)($|_)`)
+	validName     = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.\-\[\]]{0,63}$`)
+)
+
+// Classes returns the check classes a parameter name is a candidate for.
+func Classes(name string) []string {
+	classes := []string{ClassXSS}
+	if redirectNames.MatchString(name) {
+		classes = append(classes, ClassRedirect)
+	}
+	if ssrfNames.MatchString(name) {
+		classes = append(classes, ClassSSRF)
+	}
+	return classes
+}
+
+// Endpoint is one URL without query and the parameters it is known to take.
+type Endpoint struct {
+	URL    string              `json:"url"`
+	Method string              `json:"method,omitempty"` // POST when only seen in POST forms
+	Params map[string][]string `json:"params"`           // name → sources
+
+	seenGET bool // seen in a link, query string, JS or GET form
+}
+
+// Names returns the endpoint's parameter names, sorted.
+func (e Endpoint) Names() []string {
+	names := make([]string, 0, len(e.Params))
+	for n := range e.Params {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Target is one injection point: a parameter of an endpoint.
+type Target struct {
+	URL    string
+	Method string
+	Param  string
+}
+
+// With returns the target URL with its parameter set to value.
+func (t Target) With(value string) string {
+	return t.URL + "?" + url.QueryEscape(t.Param) + "=" + url.QueryEscape(value)
+}
+
+// Inventory is a concurrency-safe set of endpoints and their parameters.
+type Inventory struct {
+	mu        sync.Mutex
+	endpoints map[string]*Endpoint
+}
+
+// NewInventory returns an empty inventory.
+func NewInventory() *Inventory {
+	return &Inventory{endpoints: map[string]*Endpoint{}}
+}
+
+// Add records that endpoint takes the parameter name, seen in source.
+// Invalid names and endpoints are ignored.
+func (inv *Inventory) Add(endpoint, name, source string) {
+	key := Normalize(endpoint)
+	if key == "" || !validName.MatchString(name) {
+		return
+	}
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	e, ok := inv.endpoints[key]
+	if !ok {
+		e = &Endpoint{URL: key, Params: map[string][]string{}}
+		inv.endpoints[key] = e
+	}
+	if source != SourceForm {
+		e.seenGET = true
+		e.Method = ""
+	}
+	for _, s := range e.Params[name] {
+		if s == source {
+			return
+		}
+	}
+	e.Params[name] = append(e.Params[name], source)
+}
+
+// AddURL records every query parameter of rawURL.
+func (inv *Inventory) AddURL(rawURL, source string) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.RawQuery == "" {
+		return
+	}
+	for name := range u.Query() {
+		inv.Add(rawURL, name, source)
+	}
+}
+
+// setMethod records the method of a form on endpoint. The endpoint is
+// POST-only when it was never seen with GET.
+func (inv *Inventory) setMethod(endpoint, method string) {
+	key := Normalize(endpoint)
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	e, ok := inv.endpoints[key]
+	if !ok {
+		return
+	}
+	switch {
+	case method != "POST":
+		e.seenGET = true
+		e.Method = ""
+	case !e.seenGET:
+		e.Method = "POST"
+	}
+}
+
+// Endpoints returns a copy of every endpoint, sorted by URL.
+func (inv *Inventory) Endpoints() []Endpoint {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	out := make([]Endpoint, 0, len(inv.endpoints))
+	for _, e := range inv.endpoints {
+		c := Endpoint{URL: e.URL, Method: e.Method, Params: make(map[string][]string, len(e.Params))}
+		for n, s := range e.Params {
+			c.Params[n] = append([]string(nil), s...)
+		}
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].URL < out[j].URL })
+	return out
+}
+
+// Targets returns every (endpoint, parameter) pair whose name is a
+// candidate for class, optionally restricted to endpoints on host (empty =
+// all hosts).
+func (inv *Inventory) Targets(class, host string) []Target {
+	var out []Target
+	for _, e := range inv.Endpoints() {
+		if host != "" {
+			if u, err := url.Parse(e.URL); err != nil || !strings.EqualFold(u.Hostname(), host) {
+				continue
+			}
+		}
+		for _, n := range e.Names() {
+			for _, c := range Classes(n) {
+				if c == class {
+					out = append(out, Target{URL: e.URL, Method: e.Method, Param: n})
+					break
+				}
+			}
+		}
+	}
+	return out
+}
+
+// Probes returns the GET injection points for class on the host of
+// baseURL: each name in defaults against the host root first, then the
+// inventory's own targets on that host. POST-only endpoints are skipped and
+// the list is capped at max (0 = no cap).
+func (inv *Inventory) Probes(class, baseURL string, defaults []string, max int) []Target {
+	root := Normalize(strings.TrimRight(baseURL, "/") + "/")
+	u, err := url.Parse(root)
+	if root == "" || err != nil {
+		return nil
+	}
+	seen := map[string]bool{}
+	var out []Target
+	add := func(t Target) {
+		key := t.URL + "?" + t.Param
+		if seen[key] || (max > 0 && len(out) >= max) {
+			return
+		}
+		seen[key] = true
+		out = append(out, t)
+	}
+	for _, n := range defaults {
+		add(Target{URL: root, Param: n})
+	}
+	for _, t := range inv.Targets(class, u.Hostname()) {
+		if t.Method == "" {
+			add(t)
+		}
+	}
+	return out
+}
+
+// Len returns the number of endpoints.
+func (inv *Inventory) Len() int {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	return len(inv.endpoints)
+}
+
+// Normalize reduces a URL to scheme://host[:port]/path, lowercasing the
+// host and dropping default ports, query and fragment. It returns "" for
+// anything that is not an http(s) URL.
+func Normalize(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	if p := u.Port(); p != "" && !(u.Scheme == "http" && p == "80") && !(u.Scheme == "https" && p == "443") {
+		host += ":" + p
+	}
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	return u.Scheme + "://" + host + path
+}
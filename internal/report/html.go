@@ -0,0 +1,134 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"strings"
+)
+
+// writeHTML renders doc as a self-contained HTML page (no external assets).
+func writeHTML(path string, doc Document) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create HTML report: %w", err)
+	}
+	defer f.Close()
+
+	if err := htmlTemplate.Execute(f, doc); err != nil {
+		return fmt.Errorf("failed to render HTML report: %w", err)
+	}
+	return nil
+}
+
+var htmlTemplate = template.Must(template.New("run").Funcs(template.FuncMap{
+	"upper": strings.ToUpper,
+	"join":  strings.Join,
+	"inc":   func(i int) int { return i + 1 },
+	"topHosts": func(hosts []*Host) []*Host {
+		return topHosts(hosts, 20)
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>narmol — run {{.RunID}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #222; }
+h1 { font-size: 1.4em; margin-bottom: 0; }
+.meta { color: #666; margin-bottom: 1.5em; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 2em; font-size: 0.9em; }
+th, td { border-bottom: 1px solid #ddd; padding: 0.4em 0.6em; text-align: left; vertical-align: top; }
+th { background: #f5f5f5; }
+td.num { text-align: right; font-variant-numeric: tabular-nums; }
+.sev { font-weight: bold; }
+.critical { color: #8b0000; } .high { color: #d9534f; } .medium { color: #e69500; }
+.low { color: #31708f; } .info { color: #777; }
+.summary span { display: inline-block; margin-right: 2em; }
+</style>
+</head>
+<body>
+<h1>narmol — {{.Workflow}} run</h1>
+<div class="meta">Run {{.RunID}} · started {{.Started}} · finished {{.Finished}}</div>
+
+<div class="summary">
+<span>Domains: {{.Summary.Domains}} ({{.Summary.DomainsFailed}} failed)</span>
+<span>Hosts: {{.Summary.Hosts}} ({{.Summary.SharedHosts}} shared)</span>
+<span>IPs: {{.Summary.IPs}}</span>
+<span>Findings: {{.Summary.Findings}}{{range $sev, $n := .Summary.BySeverity}} · {{$n}} {{$sev}}{{end}}</span>
+{{if .Summary.Suppressed}}<span>Suppressed: {{.Summary.Suppressed}}</span>{{end}}
+</div>
+
+<h2>Fix first</h2>
+{{if .FixFirst}}
+<table>
+<tr><th>#</th><th>Risk</th><th>Severity</th><th>Target</th><th>Finding</th><th>CVSS / EPSS</th></tr>
+{{range $i, $f := .FixFirst}}
+<tr>
+<td class="num">{{inc $i}}</td>
+<td class="num">{{printf "%.1f" $f.Risk}}</td>
+<td class="sev {{$f.Severity}}">{{upper $f.Severity}}</td>
+<td>{{$f.Target}}</td>
+<td>{{$f.Title}}{{if $f.TemplateID}} <small>({{$f.TemplateID}})</small>{{end}}</td>
+<td>{{if $f.CVSS}}{{printf "%.1f" $f.CVSS}}{{else}}–{{end}} / {{if $f.EPSS}}{{printf "%.3f" $f.EPSS}}{{else}}–{{end}}</td>
+</tr>
+{{end}}
+</table>
+{{else}}
+<p>No findings.</p>
+{{end}}
+
+<h2>Hosts by risk</h2>
+{{with topHosts .Hosts}}
+<table>
+<tr><th>Risk</th><th>Host</th><th>Tags</th><th>Exposure</th><th>Domains</th></tr>
+{{range .}}
+<tr>
+<td class="num">{{printf "%.1f" .Risk}}</td>
+<td>{{.Host}}</td>
+<td>{{join .Tags ", "}}</td>
+<td>{{if .CDN}}CDN: {{.CDN}} {{end}}{{if .WAF}}WAF: {{.WAF}} {{end}}{{join .IPs ", "}}</td>
+<td>{{join .Domains ", "}}</td>
+</tr>
+{{end}}
+</table>
+{{else}}
+<p>No host carries findings.</p>
+{{end}}
+
+{{with .Clusters}}
+<h2>Same application on several hosts</h2>
+<table>
+<tr><th>Hosts</th><th>Application</th><th>Shared</th><th>Members</th></tr>
+{{range .}}
+<tr>
+<td class="num">{{len .Hosts}}</td>
+<td>{{.Title}}</td>
+<td>{{.Kind}} <small>{{.Key}}</small></td>
+<td>{{join .Hosts ", "}}</td>
+</tr>
+{{end}}
+</table>
+{{end}}
+
+<h2>All findings</h2>
+{{if .Findings}}
+<table>
+<tr><th>Risk</th><th>Severity</th><th>Phase</th><th>Target</th><th>Finding</th><th>Evidence</th></tr>
+{{range .Findings}}
+<tr>
+<td class="num">{{printf "%.1f" .Risk}}</td>
+<td class="sev {{.Severity}}">{{upper .Severity}}</td>
+<td>{{.Phase}}</td>
+<td>{{.Target}}</td>
+<td>{{.Title}}{{if and .Detail (ne .Detail .Title)}}<br><small>{{.Detail}}</small>{{end}}</td>
+<td>{{if .Evidence}}<a href="{{.Evidence}}">{{.Evidence}}</a>{{end}}</td>
+</tr>
+{{end}}
+</table>
+{{else}}
+<p>No findings.</p>
+{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,573 @@
+// Package report aggregates the per-domain output of a workflow run into a
+// single run-level report: one JSON document with a run ID, a global summary,
+// hosts and IPs deduplicated across domains, and the merged findings ranked
+// by risk.
+package report
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/FOUEN/narmol/internal/cluster"
+	"github.com/FOUEN/narmol/internal/findings"
+	"github.com/FOUEN/narmol/internal/scope"
+	"github.com/FOUEN/narmol/internal/suppress"
+)
+
+// Run collects the output of one workflow invocation across all domains.
+type Run struct {
+	ID       string
+	Workflow string
+	Started  time.Time
+
+	// Ignore hides accepted-risk findings; they are only counted. May be nil.
+	Ignore *suppress.List
+
+	scope   *scope.Scope // asset tags for risk scoring; may be nil
+	domains []*Domain
+	hosts   map[string]*Host
+	ips     map[string]*IP
+	fps     map[string]cluster.Fingerprint // probe fingerprints by URL
+	all     []findings.Finding
+	hidden  []findings.Finding // suppressed by Ignore
+}
+
+// Domain is the raw output of one w.Run call.
+type Domain struct {
+	Domain  string            `json:"domain"`
+	Error   string            `json:"error,omitempty"`
+	Results []json.RawMessage `json:"results"`
+
+	text string
+}
+
+// Host is a hostname seen in the run and the scope domains that reported it.
+type Host struct {
+	Host    string   `json:"host"`
+	Domains []string `json:"domains"`
+	IPs     []string `json:"ips,omitempty"`
+	Tags    []string `json:"tags,omitempty"` // asset tags from the scope file
+	CDN     string   `json:"cdn,omitempty"`  // CDN name when the host sits behind one
+	WAF     string   `json:"waf,omitempty"`  // WAF name when one was detected
+	Risk    float64  `json:"risk"`           // combined risk of the host's findings
+}
+
+// IP is an address seen in the run, with the hosts that resolve to it.
+type IP struct {
+	IP      string   `json:"ip"`
+	Hosts   []string `json:"hosts,omitempty"`
+	Domains []string `json:"domains"`
+}
+
+// NewRun starts a run for the given workflow. s supplies asset tags for
+// risk scoring and may be nil.
+func NewRun(workflow string, s *scope.Scope) *Run {
+	now := time.Now().UTC()
+	return &Run{
+		ID:       newID(now),
+		Workflow: workflow,
+		Started:  now,
+		scope:    s,
+		hosts:    map[string]*Host{},
+		ips:      map[string]*IP{},
+		fps:      map[string]cluster.Fingerprint{},
+	}
+}
+
+// newID returns a sortable, unique run identifier: <timestamp>-<random>.
+func newID(t time.Time) string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return t.Format("20060102T150405Z") + "-" + hex.EncodeToString(b)
+}
+
+// AddDomain records the output a workflow wrote for domain to textFile and
+// jsonFile. Missing files are treated as empty output.
+func (r *Run) AddDomain(domain, textFile, jsonFile string, runErr error) {
+	d := &Domain{Domain: domain, Results: []json.RawMessage{}}
+	if runErr != nil {
+		d.Error = runErr.Error()
+	}
+
+	if textFile != "" {
+		if data, err := os.ReadFile(textFile); err == nil {
+			d.text = string(data)
+		}
+	}
+
+	if jsonFile != "" {
+		if data, err := os.ReadFile(jsonFile); err == nil {
+			dec := json.NewDecoder(strings.NewReader(string(data)))
+			for dec.More() {
+				var raw json.RawMessage
+				if err := dec.Decode(&raw); err != nil {
+					fmt.Printf("[!] Could not parse %s output for %s: %s\n", r.Workflow, domain, err)
+					break
+				}
+				var obj map[string]any
+				if err := json.Unmarshal(raw, &obj); err != nil {
+					continue
+				}
+				if r.Ignore != nil {
+					if !r.filter(obj) {
+						continue
+					}
+					raw, _ = json.Marshal(obj)
+				}
+				d.Results = append(d.Results, raw)
+				r.indexObject(domain, obj)
+				if list, err := findings.Parse(raw); err == nil {
+					r.all = append(r.all, list...)
+				}
+			}
+		}
+	}
+
+	r.domains = append(r.domains, d)
+}
+
+// ─── Suppression ────────────────────────────────────────────────────────
+
+// filter removes suppressed findings from a raw result and reports whether
+// anything is left to keep. Phased reports keep their envelope with the
+// suppressed entries dropped from each phase.
+func (r *Run) filter(obj map[string]any) bool {
+	if phases, ok := obj["phases"].(map[string]any); ok {
+		for name, entries := range phases {
+			list, ok := entries.([]any)
+			if !ok {
+				continue
+			}
+			kept := make([]any, 0, len(list))
+			for _, e := range list {
+				if m, ok := e.(map[string]any); ok && r.suppressed(m) {
+					continue
+				}
+				kept = append(kept, e)
+			}
+			phases[name] = kept
+		}
+		return true
+	}
+	return !r.suppressed(obj)
+}
+
+func (r *Run) suppressed(m map[string]any) bool {
+	f, ok := findings.FromMap(m)
+	if !ok || r.Ignore.Match(f) == nil {
+		return false
+	}
+	r.hidden = append(r.hidden, f)
+	return true
+}
+
+// hideSuppressed drops the lines of a workflow's text output that describe
+// a suppressed finding (target as a whole token plus detail or title on the
+// same line).
+func (r *Run) hideSuppressed(text string) string {
+	if len(r.hidden) == 0 {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		hide := false
+		for _, f := range r.hidden {
+			if containsTarget(line, f.Target) &&
+				((f.Detail != "" && strings.Contains(line, f.Detail)) || (f.Title != "" && strings.Contains(line, f.Title))) {
+				hide = true
+				break
+			}
+		}
+		if !hide {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+// containsTarget reports whether target appears in line as a whole token:
+// not inside a longer host name, so "a.com" matches "https://a.com:8443/x"
+// but not "beta.com" or "a.com.evil.net".
+func containsTarget(line, target string) bool {
+	if target == "" {
+		return false
+	}
+	for from := 0; ; {
+		i := strings.Index(line[from:], target)
+		if i < 0 {
+			return false
+		}
+		start, end := from+i, from+i+len(target)
+		if (start == 0 || !hostByte(line[start-1])) &&
+			(end == len(line) || !hostByte(line[end]) ||
+				line[end] == '.' && (end+1 == len(line) || !hostByte(line[end+1]))) {
+			return true
+		}
+		from = start + 1
+	}
+}
+
+// hostByte reports whether c can continue a host name or path segment.
+func hostByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		c == '.' || c == '-' || c == '_'
+}
+
+// ─── Host / IP indexing ─────────────────────────────────────────────────
+
+// hostKeys are the JSON fields workflows use for the asset a result is about.
+var hostKeys = []string{"host", "subdomain", "url", "value", "input"}
+
+// indexObject walks a result object (or a phased report) and records every
+// host and IP it mentions.
+func (r *Run) indexObject(domain string, obj map[string]any) {
+	// Phased reports (web, full) nest their entries under "phases".
+	if phases, ok := obj["phases"].(map[string]any); ok {
+		for _, entries := range phases {
+			list, _ := entries.([]any)
+			for _, e := range list {
+				if m, ok := e.(map[string]any); ok {
+					r.indexObject(domain, m)
+				}
+			}
+		}
+		return
+	}
+
+	var host string
+	for _, k := range hostKeys {
+		v, _ := obj[k].(string)
+		if h := findings.Hostname(v); h != "" {
+			host = h
+			break
+		}
+	}
+	if host == "" {
+		return
+	}
+
+	if net.ParseIP(host) != nil {
+		r.addIP(domain, host, "")
+		return
+	}
+	h := r.addHost(domain, host)
+
+	// Probe fingerprints (active, alive) feed the same-application clusters.
+	if fp, ok := cluster.FromMap(obj); ok {
+		r.fps[fp.URL] = fp
+	}
+
+	// Exposure hints from probes: CDN (httpx) and WAF detection.
+	if cdn, _ := obj["cdn"].(bool); cdn && h.CDN == "" {
+		h.CDN = "yes"
+		if name, _ := obj["cdn_name"].(string); name != "" {
+			h.CDN = name
+		}
+	}
+	if waf, _ := obj["waf"].(string); waf != "" {
+		h.WAF = waf
+	}
+	if ip, _ := obj["ip"].(string); net.ParseIP(ip) != nil {
+		r.addIP(domain, ip, host)
+	}
+
+	// subdomains/recon attach resolved addresses.
+	if list, ok := obj["ips"].([]any); ok {
+		for _, v := range list {
+			if ip, ok := v.(string); ok && net.ParseIP(ip) != nil {
+				r.addIP(domain, ip, host)
+			}
+		}
+	}
+}
+
+func (r *Run) addHost(domain, host string) *Host {
+	h, ok := r.hosts[host]
+	if !ok {
+		h = &Host{Host: host}
+		r.hosts[host] = h
+	}
+	h.Domains = appendUnique(h.Domains, domain)
+	return h
+}
+
+func (r *Run) addIP(domain, ip, host string) {
+	entry, ok := r.ips[ip]
+	if !ok {
+		entry = &IP{IP: ip}
+		r.ips[ip] = entry
+	}
+	entry.Domains = appendUnique(entry.Domains, domain)
+	if host != "" {
+		entry.Hosts = appendUnique(entry.Hosts, host)
+		h := r.addHost(domain, host)
+		h.IPs = appendUnique(h.IPs, ip)
+	}
+}
+
+func appendUnique(slice []string, item string) []string {
+	for _, s := range slice {
+		if s == item {
+			return slice
+		}
+	}
+	return append(slice, item)
+}
+
+// ─── Output ─────────────────────────────────────────────────────────────
+
+// Document is the run-level JSON report.
+type Document struct {
+	RunID    string             `json:"run_id"`
+	Workflow string             `json:"workflow"`
+	Started  string             `json:"started"`
+	Finished string             `json:"finished"`
+	Summary  Summary            `json:"summary"`
+	FixFirst []findings.Finding `json:"fix_first"` // highest-risk findings, in order
+	Hosts    []*Host            `json:"hosts"`
+	IPs      []*IP              `json:"ips"`
+	Findings []findings.Finding `json:"findings"`           // sorted by risk
+	Clusters []cluster.Cluster  `json:"clusters,omitempty"` // hosts running the same application
+	Domains  []*Domain          `json:"domains"`
+}
+
+// Summary is the global summary across all domains of a run.
+type Summary struct {
+	Domains       int            `json:"domains"`
+	DomainsFailed int            `json:"domains_failed"`
+	Hosts         int            `json:"hosts"`
+	SharedHosts   int            `json:"shared_hosts"` // hosts reported under more than one domain
+	IPs           int            `json:"ips"`
+	SharedIPs     int            `json:"shared_ips"`
+	Findings      int            `json:"findings"`
+	BySeverity    map[string]int `json:"by_severity"`
+	Suppressed    int            `json:"suppressed"` // accepted-risk findings hidden by the ignore file
+}
+
+// Document builds the final JSON document for the run.
+func (r *Run) Document() Document {
+	doc := Document{
+		RunID:    r.ID,
+		Workflow: r.Workflow,
+		Started:  r.Started.Format(time.RFC3339),
+		Finished: time.Now().UTC().Format(time.RFC3339),
+		Hosts:    []*Host{},
+		IPs:      []*IP{},
+		Findings: findings.Dedup(r.all),
+		Domains:  r.domains,
+	}
+	if doc.Findings == nil {
+		doc.Findings = []findings.Finding{}
+	}
+	if doc.Domains == nil {
+		doc.Domains = []*Domain{}
+	}
+
+	for _, h := range r.hosts {
+		sort.Strings(h.IPs)
+		if r.scope != nil {
+			h.Tags = r.scope.Tags(h.Host)
+		}
+		doc.Hosts = append(doc.Hosts, h)
+		if len(h.Domains) > 1 {
+			doc.Summary.SharedHosts++
+		}
+	}
+	sort.Slice(doc.Hosts, func(i, j int) bool { return doc.Hosts[i].Host < doc.Hosts[j].Host })
+
+	for _, ip := range r.ips {
+		sort.Strings(ip.Hosts)
+		doc.IPs = append(doc.IPs, ip)
+		if len(ip.Domains) > 1 {
+			doc.Summary.SharedIPs++
+		}
+	}
+	sort.Slice(doc.IPs, func(i, j int) bool { return doc.IPs[i].IP < doc.IPs[j].IP })
+
+	prioritise(&doc, r.hosts)
+
+	fps := make([]cluster.Fingerprint, 0, len(r.fps))
+	for _, u := range sortedURLs(r.fps) {
+		fps = append(fps, r.fps[u])
+	}
+	doc.Clusters = cluster.Group(fps)
+
+	doc.Summary.Domains = len(r.domains)
+	for _, d := range r.domains {
+		if d.Error != "" {
+			doc.Summary.DomainsFailed++
+		}
+	}
+	doc.Summary.Hosts = len(doc.Hosts)
+	doc.Summary.IPs = len(doc.IPs)
+	doc.Summary.Findings = len(doc.Findings)
+	doc.Summary.Suppressed = len(findings.Dedup(r.hidden))
+	doc.Summary.BySeverity = map[string]int{}
+	for _, f := range doc.Findings {
+		doc.Summary.BySeverity[f.Severity]++
+	}
+	return doc
+}
+
+// Write writes the consolidated text, JSON and HTML reports (empty paths are
+// skipped). Each file holds the whole run, so they are overwritten rather
+// than appended to.
+func (r *Run) Write(textFile, jsonFile, htmlFile string) error {
+	doc := r.Document()
+
+	if textFile != "" {
+		if err := os.WriteFile(textFile, []byte(r.formatText(doc)), 0644); err != nil {
+			return fmt.Errorf("failed to write text report: %w", err)
+		}
+		fmt.Printf("[+] Run report saved to: %s\n", textFile)
+	}
+
+	if jsonFile != "" {
+		js, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal run report: %w", err)
+		}
+		if err := os.WriteFile(jsonFile, js, 0644); err != nil {
+			return fmt.Errorf("failed to write JSON report: %w", err)
+		}
+		fmt.Printf("[+] Run JSON report saved to: %s\n", jsonFile)
+	}
+
+	if htmlFile != "" {
+		if err := writeHTML(htmlFile, doc); err != nil {
+			return err
+		}
+		fmt.Printf("[+] Run HTML report saved to: %s\n", htmlFile)
+	}
+
+	fmt.Printf("[+] Run %s completed — %d domains, %d hosts (%d shared), %d IPs, %d findings\n",
+		doc.RunID, doc.Summary.Domains, doc.Summary.Hosts, doc.Summary.SharedHosts, doc.Summary.IPs, doc.Summary.Findings)
+	return nil
+}
+
+func (r *Run) formatText(doc Document) string {
+	var b strings.Builder
+	line := strings.Repeat("─", 70)
+	doubleLine := strings.Repeat("═", 70)
+
+	b.WriteString(doubleLine + "\n")
+	b.WriteString("  NARMOL — Run " + doc.RunID + "\n")
+	b.WriteString("  Workflow: " + doc.Workflow + "\n")
+	b.WriteString("  Started:  " + doc.Started + "\n")
+	b.WriteString(doubleLine + "\n")
+
+	for _, d := range r.domains {
+		b.WriteString("\n" + line + "\n")
+		b.WriteString("  DOMAIN: " + d.Domain + "\n")
+		b.WriteString(line + "\n")
+		if d.Error != "" {
+			b.WriteString("  [!] " + d.Error + "\n")
+		}
+		text := r.hideSuppressed(d.text)
+		if strings.TrimSpace(text) == "" {
+			b.WriteString("  No results.\n")
+			continue
+		}
+		b.WriteString(text)
+		if !strings.HasSuffix(text, "\n") {
+			b.WriteString("\n")
+		}
+	}
+
+	// ── Run summary ──
+	b.WriteString("\n" + doubleLine + "\n")
+	b.WriteString("  RUN SUMMARY\n")
+	b.WriteString(doubleLine + "\n")
+	b.WriteString(fmt.Sprintf("  Domains:   %d (%d failed)\n", doc.Summary.Domains, doc.Summary.DomainsFailed))
+	b.WriteString(fmt.Sprintf("  Hosts:     %d unique (%d shared across domains)\n", doc.Summary.Hosts, doc.Summary.SharedHosts))
+	b.WriteString(fmt.Sprintf("  IPs:       %d unique (%d shared across domains)\n", doc.Summary.IPs, doc.Summary.SharedIPs))
+	b.WriteString(fmt.Sprintf("  Findings:  %s\n", severityBreakdown(doc.Summary)))
+	if doc.Summary.Suppressed > 0 {
+		b.WriteString(fmt.Sprintf("  Suppressed: %d (accepted risk, see %s)\n", doc.Summary.Suppressed, r.Ignore.Path))
+	}
+
+	// ── Fix first ──
+	if len(doc.FixFirst) > 0 {
+		b.WriteString("\n  Fix first (by risk):\n")
+		for i, f := range doc.FixFirst {
+			b.WriteString(fmt.Sprintf("    %2d. [%5.1f] [%s] %s — %s\n", i+1, f.Risk, strings.ToUpper(f.Severity), f.Target, f.Title))
+		}
+
+		b.WriteString("\n  Riskiest hosts:\n")
+		for _, h := range topHosts(doc.Hosts, 5) {
+			tags := ""
+			if len(h.Tags) > 0 {
+				tags = " @" + strings.Join(h.Tags, " @")
+			}
+			b.WriteString(fmt.Sprintf("    [%5.1f] %s%s\n", h.Risk, h.Host, tags))
+		}
+	}
+
+	if len(doc.Clusters) > 0 {
+		b.WriteString("\n  Same application on several hosts:\n")
+		for _, c := range doc.Clusters {
+			b.WriteString("    " + c.Summary() + "\n")
+		}
+	}
+
+	var shared []string
+	for _, h := range doc.Hosts {
+		if len(h.Domains) > 1 {
+			shared = append(shared, fmt.Sprintf("%s (%s)", h.Host, strings.Join(h.Domains, ", ")))
+		}
+	}
+	if len(shared) > 0 {
+		b.WriteString("\n  Shared hosts:\n")
+		for _, s := range shared {
+			b.WriteString("    " + s + "\n")
+		}
+	}
+	b.WriteString(doubleLine + "\n")
+
+	return b.String()
+}
+
+func sortedURLs(fps map[string]cluster.Fingerprint) []string {
+	urls := make([]string, 0, len(fps))
+	for u := range fps {
+		urls = append(urls, u)
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+// topHosts returns up to n hosts with a non-zero risk, highest first.
+func topHosts(hosts []*Host, n int) []*Host {
+	var out []*Host
+	for _, h := range hosts {
+		if h.Risk > 0 {
+			out = append(out, h)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Risk > out[j].Risk })
+	if len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
+
+func severityBreakdown(s Summary) string {
+	if s.Findings == 0 {
+		return "0"
+	}
+	var parts []string
+	for _, sev := range []string{"critical", "high", "medium", "low", "info"} {
+		if c := s.BySeverity[sev]; c > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", c, sev))
+		}
+	}
+	return fmt.Sprintf("%d (%s)", s.Findings, strings.Join(parts, ", "))
+}
@@ -0,0 +1,124 @@
+package report
+
+import (
+	"math"
+	"net"
+	"sort"
+
+	"github.com/FOUEN/narmol/internal/findings"
+)
+
+// FixFirstLimit is how many findings the prioritised "fix first" list holds.
+const FixFirstLimit = 10
+
+// severityBase is the 0–10 impact used when a finding carries no CVSS score.
+var severityBase = map[string]float64{
+	"critical": 9.5,
+	"high":     7.5,
+	"medium":   5.0,
+	"low":      2.5,
+	"info":     0.5,
+}
+
+// criticalityFactor maps scope asset tags to a weight. The highest matching
+// tag wins; untagged assets weigh 1.
+var criticalityFactor = map[string]float64{
+	"critical": 1.5,
+	"high":     1.25,
+	"low":      0.75,
+}
+
+// Score returns a 0–100 risk score for f on the given host. It combines:
+//   - impact: the template CVSS score, or a severity-based default
+//   - exploitability: EPSS probability raises the score up to 2x
+//   - exposure: public IP raises it, private-only IPs or a CDN/WAF lower it
+//   - criticality: scope tags (@critical, @high, @low)
+func Score(f findings.Finding, h *Host) float64 {
+	impact := f.CVSS
+	if impact <= 0 {
+		impact = severityBase[f.Severity]
+	}
+
+	score := impact * 10 * (1 + f.EPSS)
+	if h != nil {
+		score *= h.exposureFactor() * h.criticalityFactor()
+	}
+	return math.Round(math.Min(score, 100)*10) / 10
+}
+
+// exposureFactor weighs how reachable the host is from the internet.
+func (h *Host) exposureFactor() float64 {
+	factor := 1.0
+	switch {
+	case h.public():
+		factor = 1.2
+	case len(h.IPs) > 0:
+		factor = 0.6 // only private addresses: internal asset
+	}
+	if h.CDN != "" || h.WAF != "" {
+		factor *= 0.8
+	}
+	return factor
+}
+
+func (h *Host) public() bool {
+	for _, s := range h.IPs {
+		ip := net.ParseIP(s)
+		if ip != nil && !ip.IsPrivate() && !ip.IsLoopback() && !ip.IsLinkLocalUnicast() {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *Host) criticalityFactor() float64 {
+	factor := 1.0
+	found := false
+	for _, t := range h.Tags {
+		if w, ok := criticalityFactor[t]; ok && (!found || w > factor) {
+			factor, found = w, true
+		}
+	}
+	return factor
+}
+
+// hostScore combines the scores of all findings on a host: the worst finding
+// dominates, every other finding adds a tenth of its own score.
+func hostScore(scores []float64) float64 {
+	if len(scores) == 0 {
+		return 0
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(scores)))
+	total := scores[0]
+	for _, s := range scores[1:] {
+		total += s / 10
+	}
+	return math.Round(math.Min(total, 100)*10) / 10
+}
+
+// prioritise scores every finding and host in doc, sorts findings by risk
+// and fills the fix-first list.
+func prioritise(doc *Document, hosts map[string]*Host) {
+	perHost := map[string][]float64{}
+	for i := range doc.Findings {
+		f := &doc.Findings[i]
+		f.Risk = Score(*f, hosts[f.Host])
+		perHost[f.Host] = append(perHost[f.Host], f.Risk)
+	}
+	for _, h := range doc.Hosts {
+		h.Risk = hostScore(perHost[h.Host])
+	}
+
+	sort.SliceStable(doc.Findings, func(i, j int) bool {
+		if doc.Findings[i].Risk != doc.Findings[j].Risk {
+			return doc.Findings[i].Risk > doc.Findings[j].Risk
+		}
+		return findings.SeverityOrder(doc.Findings[i].Severity) > findings.SeverityOrder(doc.Findings[j].Severity)
+	})
+
+	n := len(doc.Findings)
+	if n > FixFirstLimit {
+		n = FixFirstLimit
+	}
+	doc.FixFirst = doc.Findings[:n:n]
+}
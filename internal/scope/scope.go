@@ -14,6 +14,7 @@ type rule struct {
 	exclude bool   // true if this is an exclusion rule (prefixed with -)
 	ip      net.IP // non-nil if this is a single IP rule
 	cidr    *net.IPNet // non-nil if this is a CIDR rule
+	tags    []string   // asset tags ("@critical", "@pci"), stored without the "@"
 }
 
 // Scope enforces what targets can be audited.
@@ -28,6 +29,7 @@ type rule struct {
 //	10.0.0.1               # single IP
 //	192.168.1.0/24         # CIDR range
 //	-10.0.0.5              # exclude specific IP
+//	pay.example.com @critical @pci  # asset tags (used for risk scoring)
 type Scope struct {
 	includes []rule
 	excludes []rule
@@ -84,6 +86,16 @@ func processLine(s *Scope, line string) {
 		line = strings.TrimSpace(line[:idx])
 	}
 
+	// Split off asset tags: "pay.example.com @critical @pci"
+	fields := strings.Fields(line)
+	line = fields[0]
+	var tags []string
+	for _, f := range fields[1:] {
+		if strings.HasPrefix(f, "@") && len(f) > 1 {
+			tags = append(tags, strings.ToLower(f[1:]))
+		}
+	}
+
 	exclude := false
 	pattern := line
 	if strings.HasPrefix(line, "-") {
@@ -91,7 +103,7 @@ func processLine(s *Scope, line string) {
 		pattern = strings.TrimPrefix(line, "-")
 	}
 
-	r := rule{pattern: pattern, exclude: exclude}
+	r := rule{pattern: pattern, exclude: exclude, tags: tags}
 
 	// Try to parse as CIDR (e.g. 192.168.1.0/24)
 	if _, cidr, err := net.ParseCIDR(pattern); err == nil {
@@ -111,6 +123,44 @@ func processLine(s *Scope, line string) {
 // IsInScope checks whether a given target (domain/host/IP) is within scope.
 // Exclusions always take priority over inclusions.
 func (s *Scope) IsInScope(target string) bool {
+	target = normalize(target)
+
+	// Check if target is an IP address
+	targetIP := net.ParseIP(target)
+
+	// Check exclusions first — they always win
+	for _, r := range s.excludes {
+		if matchRule(r, target, targetIP) {
+			return false
+		}
+	}
+
+	// Check inclusions
+	for _, r := range s.includes {
+		if matchRule(r, target, targetIP) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsExcluded reports whether target matches an exclusion rule, e.g. an IP a
+// host in scope resolves to that must not be touched.
+func (s *Scope) IsExcluded(target string) bool {
+	target = normalize(target)
+	targetIP := net.ParseIP(target)
+	for _, r := range s.excludes {
+		if matchRule(r, target, targetIP) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalize lowercases target and strips protocol, port, path and IPv6
+// brackets.
+func normalize(target string) string {
 	target = strings.ToLower(strings.TrimSpace(target))
 
 	// Strip protocol if present
@@ -129,26 +179,29 @@ func (s *Scope) IsInScope(target string) bool {
 		target = target[:idx]
 	}
 	// Strip brackets from IPv6
-	target = strings.Trim(target, "[]")
+	return strings.Trim(target, "[]")
+}
 
-	// Check if target is an IP address
+// Tags returns the asset tags of every inclusion rule matching target.
+// Targets matched by no tagged rule return nil.
+func (s *Scope) Tags(target string) []string {
+	target = strings.ToLower(strings.TrimSpace(target))
 	targetIP := net.ParseIP(target)
 
-	// Check exclusions first — they always win
-	for _, r := range s.excludes {
-		if matchRule(r, target, targetIP) {
-			return false
-		}
-	}
-
-	// Check inclusions
+	var tags []string
+	seen := map[string]bool{}
 	for _, r := range s.includes {
-		if matchRule(r, target, targetIP) {
-			return true
+		if len(r.tags) == 0 || !matchRule(r, target, targetIP) {
+			continue
+		}
+		for _, t := range r.tags {
+			if !seen[t] {
+				seen[t] = true
+				tags = append(tags, t)
+			}
 		}
 	}
-
-	return false
+	return tags
 }
 
 // FilterHosts filters a list of hosts, returning only those in scope.
@@ -188,6 +241,30 @@ func (s *Scope) Domains() []string {
 	return domains
 }
 
+// WildcardRoots returns the base domain of every wildcard inclusion rule
+// that equals target or sits below it ("*.dev.example.com" → "dev.example.com"
+// for target "example.com"). Roots whose children are excluded as a whole
+// ("-*.staging.example.com") are skipped.
+func (s *Scope) WildcardRoots(target string) []string {
+	target = strings.ToLower(strings.TrimSpace(target))
+	seen := map[string]bool{}
+	var roots []string
+	for _, r := range s.includes {
+		if !strings.HasPrefix(r.pattern, "*.") {
+			continue
+		}
+		root := strings.ToLower(r.pattern[2:])
+		if root != target && !strings.HasSuffix(root, "."+target) {
+			continue
+		}
+		if !seen[root] && s.IsInScope("narmol-probe."+root) {
+			seen[root] = true
+			roots = append(roots, root)
+		}
+	}
+	return roots
+}
+
 // IPs returns all IP and CIDR inclusion rules as strings.
 func (s *Scope) IPs() []string {
 	var ips []string
@@ -221,6 +298,9 @@ func (s *Scope) String() string {
 		} else if r.ip != nil {
 			label = "ip"
 		}
+		if len(r.tags) > 0 {
+			label += ", @" + strings.Join(r.tags, " @")
+		}
 		sb.WriteString(fmt.Sprintf("    + %s (%s)\n", r.pattern, label))
 	}
 	if len(s.excludes) > 0 {
@@ -0,0 +1,343 @@
+// Package service identifies what listens on an open port: a passive banner
+// read first (SSH, FTP, SMTP, POP3, IMAP, MySQL, VNC speak first), then a TLS
+// handshake, then a few protocol probes (HTTP, Redis, PostgreSQL,
+// memcached). HTTP(S) services feed the web pipeline, the rest network
+// templates.
+package service
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Service names.
+const (
+	HTTP       = "http"
+	HTTPS      = "https"
+	TLS        = "tls" // TLS without a recognised protocol inside
+	SSH        = "ssh"
+	FTP        = "ftp"
+	SMTP       = "smtp"
+	POP3       = "pop3"
+	IMAP       = "imap"
+	MySQL      = "mysql"
+	VNC        = "vnc"
+	Redis      = "redis"
+	PostgreSQL = "postgresql"
+	Memcached  = "memcached"
+	Unknown    = "unknown"
+)
+
+// DefaultTimeout bounds each connection (dial plus read).
+const DefaultTimeout = 4 * time.Second
+
+// Service is what was found on host:port.
+type Service struct {
+	Host    string
+	Port    int
+	Name    string // one of the constants above
+	TLS     bool
+	Product string // product/version from the banner, when the protocol announces it
+	Banner  string // first line of the greeting or probe answer
+}
+
+// Addr returns host:port.
+func (s Service) Addr() string {
+	return net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+}
+
+// IsHTTP reports whether the service speaks HTTP (plain or over TLS).
+func (s Service) IsHTTP() bool {
+	return s.Name == HTTP || s.Name == HTTPS
+}
+
+// URL returns the base URL of an HTTP(S) service ("" otherwise).
+func (s Service) URL() string {
+	if !s.IsHTTP() {
+		return ""
+	}
+	return s.Name + "://" + s.Addr()
+}
+
+// String is the one-line description used in reports:
+// "ssh (OpenSSH_8.9p1)", "https", "unknown: <banner>".
+func (s Service) String() string {
+	out := s.Name
+	if s.TLS && s.Name != HTTPS && s.Name != TLS {
+		out += "/tls"
+	}
+	switch {
+	case s.Product != "":
+		out += " (" + s.Product + ")"
+	case s.Name == Unknown && s.Banner != "":
+		out += ": " + s.Banner
+	}
+	return out
+}
+
+// Target is an open port to identify.
+type Target struct {
+	Host string
+	Port int
+}
+
+// DetectAll identifies every target with up to concurrency connections in
+// flight and returns the results in target order.
+func DetectAll(targets []Target, concurrency int, timeout time.Duration) []Service {
+	if concurrency <= 0 {
+		concurrency = 20
+	}
+	out := make([]Service, len(targets))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		wg.Add(1)
+		go func(i int, t Target) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			out[i] = Detect(t.Host, t.Port, timeout)
+		}(i, t)
+	}
+	wg.Wait()
+	return out
+}
+
+// Detect identifies the service on host:port.
+func Detect(host string, port int, timeout time.Duration) Service {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	svc := Service{Host: host, Port: port, Name: Unknown}
+	addr := svc.Addr()
+
+	// 1. Server-first protocols announce themselves.
+	if banner := exchange(addr, nil, timeout/2, timeout); len(banner) > 0 {
+		if identifyBanner(&svc, banner) {
+			return svc
+		}
+		svc.Banner = firstLine(banner)
+	}
+
+	// 2. TLS, then HTTP inside it.
+	if conn, err := tls.DialWithDialer(&net.Dialer{Timeout: timeout}, "tcp", addr,
+		&tls.Config{InsecureSkipVerify: true, ServerName: serverName(host)}); err == nil {
+		svc.TLS = true
+		svc.Name = TLS
+		conn.SetDeadline(time.Now().Add(timeout))
+		conn.Write(httpProbe(host))
+		answer := readSome(conn)
+		conn.Close()
+		if bytes.HasPrefix(answer, []byte("HTTP/")) {
+			svc.Name = HTTPS
+			svc.Banner = firstLine(answer)
+			svc.Product = header(answer, "Server")
+		}
+		return svc
+	}
+
+	// 3. Client-first probes. PING goes first: Redis drops connections
+	// that look like HTTP, while HTTP servers just answer PING with a 400.
+	for _, p := range probes {
+		answer := exchange(addr, p.payload(host), timeout, timeout)
+		if len(answer) == 0 {
+			continue
+		}
+		if p.match(&svc, answer) {
+			return svc
+		}
+		if svc.Banner == "" {
+			svc.Banner = firstLine(answer)
+		}
+	}
+	return svc
+}
+
+// probe is a client-first protocol check.
+type probe struct {
+	payload func(host string) []byte
+	match   func(svc *Service, answer []byte) bool
+}
+
+var probes = []probe{
+	{
+		payload: func(string) []byte { return []byte("PING\r\n") },
+		match: func(svc *Service, a []byte) bool {
+			for _, prefix := range []string{"+PONG", "-NOAUTH", "-ERR", "-DENIED"} {
+				if bytes.HasPrefix(a, []byte(prefix)) {
+					svc.Name, svc.Banner = Redis, firstLine(a)
+					return true
+				}
+			}
+			return false
+		},
+	},
+	{
+		payload: httpProbe,
+		match: func(svc *Service, a []byte) bool {
+			if bytes.HasPrefix(a, []byte("HTTP/")) {
+				svc.Name, svc.Banner, svc.Product = HTTP, firstLine(a), header(a, "Server")
+				return true
+			}
+			return false
+		},
+	},
+	{
+		// PostgreSQL SSLRequest: the server answers a single 'S' or 'N'.
+		payload: func(string) []byte { return []byte{0, 0, 0, 8, 0x04, 0xd2, 0x16, 0x2f} },
+		match: func(svc *Service, a []byte) bool {
+			if len(a) == 1 && (a[0] == 'S' || a[0] == 'N') {
+				svc.Name = PostgreSQL
+				svc.TLS = a[0] == 'S'
+				return true
+			}
+			return false
+		},
+	},
+	{
+		payload: func(string) []byte { return []byte("version\r\n") },
+		match: func(svc *Service, a []byte) bool {
+			if v, ok := bytes.CutPrefix(a, []byte("VERSION ")); ok {
+				svc.Name, svc.Product = Memcached, "memcached "+firstLine(v)
+				return true
+			}
+			return false
+		},
+	},
+}
+
+// identifyBanner recognises server-first greetings.
+func identifyBanner(svc *Service, b []byte) bool {
+	line := firstLine(b)
+	lower := strings.ToLower(line)
+	switch {
+	case strings.HasPrefix(line, "SSH-"):
+		svc.Name = SSH
+		// SSH-2.0-OpenSSH_8.9p1 Ubuntu-3
+		if parts := strings.SplitN(line, "-", 3); len(parts) == 3 {
+			svc.Product = parts[2]
+		}
+	case strings.HasPrefix(line, "RFB "):
+		svc.Name, svc.Product = VNC, line
+	case strings.HasPrefix(line, "+OK"):
+		svc.Name = POP3
+	case strings.HasPrefix(line, "* OK"):
+		svc.Name = IMAP
+	case strings.HasPrefix(line, "220") && strings.Contains(lower, "ftp"):
+		svc.Name = FTP
+	case strings.HasPrefix(line, "220") && (strings.Contains(lower, "smtp") || strings.Contains(lower, "mail")):
+		svc.Name = SMTP
+	case strings.HasPrefix(line, "220"):
+		svc.Name = FTP
+	case isMySQLHandshake(b):
+		svc.Name = MySQL
+		// Protocol 10 handshake: 4-byte header, 0x0a, NUL-terminated version.
+		if end := bytes.IndexByte(b[5:], 0); b[4] == 0x0a && end > 0 {
+			svc.Product = "MySQL " + string(b[5:5+end])
+		}
+		return true
+	default:
+		return false
+	}
+	svc.Banner = line
+	if svc.Product == "" {
+		svc.Product = productFromGreeting(line)
+	}
+	return true
+}
+
+func isMySQLHandshake(b []byte) bool {
+	if len(b) < 6 {
+		return false
+	}
+	length := int(b[0]) | int(b[1])<<8 | int(b[2])<<16
+	if length == 0 || length > 1024 {
+		return false
+	}
+	// Protocol 10 handshake, or an error packet ("Host … is not allowed").
+	return b[4] == 0x0a || b[4] == 0xff
+}
+
+// productFromGreeting names the server software in an FTP/SMTP/POP3/IMAP
+// greeting: "220 mail.example.com ESMTP Postfix" → "Postfix".
+func productFromGreeting(line string) string {
+	lower := strings.ToLower(line)
+	for _, known := range []string{"Postfix", "Exim", "Sendmail", "Microsoft ESMTP", "vsFTPd", "ProFTPD", "Pure-FTPd", "FileZilla", "Dovecot", "Courier", "Cyrus"} {
+		if strings.Contains(lower, strings.ToLower(known)) {
+			return known
+		}
+	}
+	return ""
+}
+
+// exchange dials addr, optionally sends payload, and returns what the peer
+// says within read (empty on any failure).
+func exchange(addr string, payload []byte, read, dial time.Duration) []byte {
+	conn, err := net.DialTimeout("tcp", addr, dial)
+	if err != nil {
+		return nil
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(read))
+	if payload != nil {
+		if _, err := conn.Write(payload); err != nil {
+			return nil
+		}
+	}
+	return readSome(conn)
+}
+
+// readSome reads what the peer sends until it pauses, closes or 4 KiB.
+func readSome(conn net.Conn) []byte {
+	buf := make([]byte, 4096)
+	n, _ := conn.Read(buf)
+	return buf[:n]
+}
+
+func httpProbe(host string) []byte {
+	return []byte(fmt.Sprintf("GET / HTTP/1.0\r\nHost: %s\r\nUser-Agent: Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36\r\n\r\n", host))
+}
+
+// header returns the value of an HTTP response header from a raw answer.
+func header(raw []byte, name string) string {
+	for _, line := range strings.Split(string(raw), "\r\n")[1:] {
+		if line == "" {
+			break
+		}
+		if k, v, ok := strings.Cut(line, ":"); ok && strings.EqualFold(strings.TrimSpace(k), name) {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
+
+func firstLine(b []byte) string {
+	line := string(b)
+	if i := strings.IndexAny(line, "\r\n"); i >= 0 {
+		line = line[:i]
+	}
+	line = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, line)
+	if len(line) > 120 {
+		line = line[:120]
+	}
+	return strings.TrimSpace(line)
+}
+
+// serverName is the SNI for host (none for IP addresses).
+func serverName(host string) string {
+	if net.ParseIP(host) != nil {
+		return ""
+	}
+	return host
+}
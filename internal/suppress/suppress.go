@@ -0,0 +1,236 @@
+// Package suppress implements the .narmolignore allowlist: accepted-risk
+// findings that are counted but hidden from reports and exports.
+//
+// Format (one rule per line, all conditions on a line must match):
+//
+//	# accepted: marketing site has no Permissions-Policy
+//	phase=header host=www.example.com detail=/Permissions-Policy/ expires=2026-12-31 reason="marketing site"
+//	fingerprint=3f9a1c0d5e7b2a44 reason="false positive, see ticket SEC-12"
+//	template=tech-detect host=*.staging.example.com
+//	phase=tls host=staging.example.com detail=/Self-signed/ expires=2027-01-31 reason="internal CA"
+//
+// Keys: fingerprint, phase, host (glob), template, detail (/regex/ or
+// substring), expires (YYYY-MM-DD, rule stops matching after that day),
+// reason (free-text justification).
+package suppress
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/FOUEN/narmol/internal/findings"
+)
+
+// DefaultFile is loaded automatically from the working directory when no
+// file is given explicitly.
+const DefaultFile = ".narmolignore"
+
+// Rule is a single suppression entry.
+type Rule struct {
+	Fingerprint string
+	Phase       string
+	Host        string // glob, e.g. "*.staging.example.com"
+	TemplateID  string
+	Detail      *regexp.Regexp
+	Expires     time.Time // zero = never
+	Reason      string
+	Line        int
+}
+
+// List is a parsed suppression file. A nil *List suppresses nothing.
+type List struct {
+	Path  string
+	Rules []Rule
+}
+
+// Load parses a suppression file. An empty path loads DefaultFile if it
+// exists and returns nil otherwise.
+func Load(file string) (*List, error) {
+	if file == "" {
+		if _, err := os.Stat(DefaultFile); err != nil {
+			return nil, nil
+		}
+		file = DefaultFile
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("could not open suppression file: %w", err)
+	}
+	defer f.Close()
+
+	l := &List{Path: file}
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		r, err := parseRule(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", file, n, err)
+		}
+		r.Line = n
+		l.Rules = append(l.Rules, r)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading suppression file: %w", err)
+	}
+	return l, nil
+}
+
+func parseRule(line string) (Rule, error) {
+	var r Rule
+	fields, err := splitFields(line)
+	if err != nil {
+		return r, err
+	}
+
+	for _, field := range fields {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return r, fmt.Errorf("expected key=value, got %q", field)
+		}
+		switch strings.ToLower(key) {
+		case "fingerprint", "fp":
+			r.Fingerprint = strings.ToLower(value)
+		case "phase":
+			r.Phase = strings.ToLower(value)
+		case "host":
+			if _, err := path.Match(value, ""); err != nil {
+				return r, fmt.Errorf("invalid host glob %q: %w", value, err)
+			}
+			r.Host = strings.ToLower(value)
+		case "template", "template_id":
+			r.TemplateID = value
+		case "detail":
+			pattern := regexp.QuoteMeta(value)
+			if len(value) > 1 && strings.HasPrefix(value, "/") && strings.HasSuffix(value, "/") {
+				pattern = value[1 : len(value)-1]
+			}
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return r, fmt.Errorf("invalid detail regex %q: %w", value, err)
+			}
+			r.Detail = re
+		case "expires":
+			t, err := time.Parse("2006-01-02", value)
+			if err != nil {
+				return r, fmt.Errorf("invalid expiry %q (want YYYY-MM-DD)", value)
+			}
+			r.Expires = t
+		case "reason":
+			r.Reason = value
+		default:
+			return r, fmt.Errorf("unknown key %q", key)
+		}
+	}
+
+	if r.Fingerprint == "" && r.Phase == "" && r.Host == "" && r.TemplateID == "" && r.Detail == nil {
+		return r, fmt.Errorf("rule has no match condition")
+	}
+	return r, nil
+}
+
+// splitFields splits on whitespace, keeping double-quoted values together:
+// reason="accepted risk" → [reason=accepted risk].
+func splitFields(line string) ([]string, error) {
+	var fields []string
+	var cur strings.Builder
+	inQuotes := false
+	for _, c := range line {
+		switch {
+		case c == '"':
+			inQuotes = !inQuotes
+		case (c == ' ' || c == '\t') && !inQuotes:
+			if cur.Len() > 0 {
+				fields = append(fields, cur.String())
+				cur.Reset()
+			}
+		default:
+			cur.WriteRune(c)
+		}
+	}
+	if inQuotes {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if cur.Len() > 0 {
+		fields = append(fields, cur.String())
+	}
+	return fields, nil
+}
+
+// Expired reports whether the rule no longer applies at now.
+func (r Rule) Expired(now time.Time) bool {
+	// The expiry day itself is still covered.
+	return !r.Expires.IsZero() && now.After(r.Expires.Add(24*time.Hour))
+}
+
+// Matches reports whether f is covered by the rule (ignoring expiry).
+func (r Rule) Matches(f findings.Finding) bool {
+	if r.Fingerprint != "" && r.Fingerprint != f.Fingerprint() {
+		return false
+	}
+	if r.Phase != "" && r.Phase != strings.ToLower(f.Phase) {
+		return false
+	}
+	if r.Host != "" {
+		if ok, _ := path.Match(r.Host, strings.ToLower(f.Host)); !ok {
+			return false
+		}
+	}
+	if r.TemplateID != "" && r.TemplateID != f.TemplateID {
+		return false
+	}
+	if r.Detail != nil && !r.Detail.MatchString(f.Detail) && !r.Detail.MatchString(f.Title) {
+		return false
+	}
+	return true
+}
+
+// Match returns the first active rule covering f, or nil.
+func (l *List) Match(f findings.Finding) *Rule {
+	if l == nil {
+		return nil
+	}
+	now := time.Now()
+	for i := range l.Rules {
+		if !l.Rules[i].Expired(now) && l.Rules[i].Matches(f) {
+			return &l.Rules[i]
+		}
+	}
+	return nil
+}
+
+// Filter splits list into findings to report and suppressed ones.
+func (l *List) Filter(list []findings.Finding) (kept, suppressed []findings.Finding) {
+	for _, f := range list {
+		if l.Match(f) != nil {
+			suppressed = append(suppressed, f)
+		} else {
+			kept = append(kept, f)
+		}
+	}
+	return kept, suppressed
+}
+
+// Expired returns the rules whose expiry date has passed, so they can be
+// reviewed instead of silently ignored.
+func (l *List) Expired() []Rule {
+	if l == nil {
+		return nil
+	}
+	var out []Rule
+	now := time.Now()
+	for _, r := range l.Rules {
+		if r.Expired(now) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
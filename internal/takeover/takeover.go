@@ -0,0 +1,326 @@
+// Package takeover detects subdomain takeovers the way can-i-take-over-xyz
+// describes them: the full CNAME chain of a name is resolved and matched
+// against a list of third-party services, and a match is only reported once
+// it is confirmed — the service's "unclaimed" page is served on the name
+// (HTTP body fingerprint) or the chain ends in NXDOMAIN for services where a
+// dangling record is enough to claim it. CNAMEs to a service that is in use
+// are not reported.
+//
+// The service list is a data file in can-i-take-over-xyz's fingerprints.json
+// format. A copy is built in; "narmol update" refreshes it into ~/.narmol.
+package takeover
+
+import (
+	"crypto/tls"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/FOUEN/narmol/internal/evidence"
+	"github.com/FOUEN/narmol/internal/scope"
+
+	"github.com/projectdiscovery/dnsx/libs/dnsx"
+	"golang.org/x/net/publicsuffix"
+)
+
+// FingerprintsURL is the upstream service list fetched by UpdateFingerprints.
+const FingerprintsURL = "https://raw.githubusercontent.com/EdOverflow/can-i-take-over-xyz/master/fingerprints.json"
+
+// dataFileName is the refreshed service list inside ~/.narmol.
+const dataFileName = "takeover-fingerprints.json"
+
+// maxChain bounds how many CNAME hops are followed.
+const maxChain = 10
+
+const maxBody = 1024 * 1024
+
+//go:embed fingerprints.json
+var builtinFingerprints []byte
+
+// Service is one entry of the service list.
+type Service struct {
+	Service     string   `json:"service"`
+	CNAME       []string `json:"cname"`       // CNAME target patterns
+	Fingerprint string   `json:"fingerprint"` // body of the unclaimed page ("NXDOMAIN" for DNS-only services)
+	HTTPStatus  int      `json:"http_status"` // status of the unclaimed page; 0 = any
+	NXDomain    bool     `json:"nxdomain"`    // a chain ending in NXDOMAIN is claimable
+	Status      string   `json:"status"`      // "Vulnerable", "Edge case", "Not vulnerable"
+	Vulnerable  bool     `json:"vulnerable"`
+}
+
+// matches reports whether a CNAME target belongs to the service.
+func (s Service) matches(cname string) bool {
+	for _, p := range s.CNAME {
+		if p = strings.ToLower(strings.Trim(p, ".")); p != "" && strings.Contains(cname, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse decodes a service list.
+func Parse(data []byte) ([]Service, error) {
+	var services []Service
+	if err := json.Unmarshal(data, &services); err != nil {
+		return nil, fmt.Errorf("invalid takeover fingerprints: %w", err)
+	}
+	if len(services) == 0 {
+		return nil, fmt.Errorf("invalid takeover fingerprints: empty list")
+	}
+	return services, nil
+}
+
+// DataFile returns the path of the refreshed service list (~/.narmol/…).
+func DataFile() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".narmol", dataFileName), nil
+}
+
+// Load returns the service list and where it came from: the refreshed copy
+// in DataFile when present and valid, the built-in list otherwise.
+func Load() ([]Service, string, error) {
+	if path, err := DataFile(); err == nil {
+		if data, err := os.ReadFile(path); err == nil {
+			if services, err := Parse(data); err == nil {
+				return services, path, nil
+			}
+			fmt.Printf("[!] Ignoring %s: not a valid service list\n", path)
+		}
+	}
+	services, err := Parse(builtinFingerprints)
+	return services, "built-in", err
+}
+
+// UpdateFingerprints downloads the upstream service list into DataFile.
+// The current file is only replaced by a list that parses.
+func UpdateFingerprints() error {
+	path, err := DataFile()
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(FingerprintsURL)
+	if err != nil {
+		return fmt.Errorf("could not download takeover fingerprints: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("could not download takeover fingerprints: HTTP %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 8*maxBody))
+	if err != nil {
+		return fmt.Errorf("could not download takeover fingerprints: %w", err)
+	}
+	services, err := Parse(data)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	fmt.Printf("[+] Takeover fingerprints updated: %d services → %s\n", len(services), path)
+	return nil
+}
+
+// Confirmation methods.
+const (
+	ByFingerprint = "fingerprint" // the service's unclaimed page is served
+	ByNXDomain    = "nxdomain"    // the chain ends in NXDOMAIN on a service claimable that way
+	ByDangling    = "dangling"    // the chain ends in NXDOMAIN outside any known service
+)
+
+// Result is a confirmed (or dangling) takeover candidate.
+type Result struct {
+	Host     string
+	Chain    []string // CNAME targets, in resolution order
+	CNAME    string   // chain entry that matched the service (last hop when dangling)
+	Service  string
+	Method   string // ByFingerprint, ByNXDomain or ByDangling
+	Severity string
+	Detail   string
+	Evidence *evidence.Record
+}
+
+// Checker resolves and verifies names against a service list.
+type Checker struct {
+	Services []Service
+	Scope    *scope.Scope // dangling CNAMEs into scope are not claimable
+	dns      *dnsx.DNSX
+	client   *http.Client
+}
+
+// NewChecker returns a checker using resolvers (dnsx defaults when empty).
+func NewChecker(services []Service, resolvers []string) (*Checker, error) {
+	opts := dnsx.DefaultOptions
+	opts.MaxRetries = 2
+	opts.Hostsfile = false
+	if len(resolvers) > 0 {
+		opts.BaseResolvers = resolvers
+	}
+	client, err := dnsx.New(opts)
+	if err != nil {
+		return nil, fmt.Errorf("could not create dnsx client: %w", err)
+	}
+	return &Checker{
+		Services: services,
+		dns:      client,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+				DialContext:     (&net.Dialer{Timeout: 5 * time.Second}).DialContext,
+			},
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}, nil
+}
+
+// Chain follows the CNAMEs of host and returns them in order with the
+// response code of the last lookup ("NOERROR", "NXDOMAIN", …) and the raw
+// answers as evidence.
+func (c *Checker) Chain(host string) (chain []string, status, raw string) {
+	seen := map[string]bool{strings.ToLower(host): true}
+	name := host
+	for i := 0; i < maxChain; i++ {
+		data, err := c.dns.QueryOne(name)
+		if err != nil || data == nil {
+			return chain, "", raw
+		}
+		status = data.StatusCode
+		raw += data.Raw
+		added := false
+		for _, target := range data.CNAME {
+			target = strings.ToLower(strings.TrimRight(target, "."))
+			if target != "" && !seen[target] {
+				seen[target] = true
+				chain = append(chain, target)
+				added = true
+			}
+		}
+		if !added {
+			break
+		}
+		name = chain[len(chain)-1]
+	}
+	return chain, status, raw
+}
+
+// Check resolves host and returns a result when a takeover is confirmed.
+// The chain is matched from its last hop, the resource actually serving the
+// name.
+func (c *Checker) Check(host string) (Result, bool) {
+	chain, status, raw := c.Chain(host)
+	if len(chain) == 0 {
+		return Result{}, false
+	}
+	nxdomain := status == "NXDOMAIN"
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		for _, svc := range c.Services {
+			if svc.matches(chain[i]) {
+				// A known service is either confirmed or in use, never a
+				// generic dangling record.
+				return c.confirm(host, chain, chain[i], svc, nxdomain, raw)
+			}
+		}
+	}
+
+	// A chain that ends nowhere, outside any known service: the target's
+	// domain may be unregistered. A stale record to another name of the
+	// same organisation is not claimable.
+	if last := chain[len(chain)-1]; nxdomain && c.foreign(host, last) {
+		return Result{
+			Host:     host,
+			Chain:    chain,
+			CNAME:    last,
+			Service:  "dangling CNAME",
+			Method:   ByDangling,
+			Severity: "medium",
+			Detail:   fmt.Sprintf("%s → %s does not resolve (NXDOMAIN): check whether its domain can be registered", host, last),
+			Evidence: evidence.Raw("A "+host, raw),
+		}, true
+	}
+	return Result{}, false
+}
+
+// foreign reports whether target's registrable domain differs from host's
+// and is outside scope: only then can it be registered by someone else.
+func (c *Checker) foreign(host, target string) bool {
+	root, err := publicsuffix.EffectiveTLDPlusOne(target)
+	if err != nil {
+		return false
+	}
+	if hostRoot, err := publicsuffix.EffectiveTLDPlusOne(host); err == nil && hostRoot == root {
+		return false
+	}
+	return c.Scope == nil || (!c.Scope.IsInScope(root) && !c.Scope.IsInScope(target))
+}
+
+// confirm verifies a CNAME to svc: NXDOMAIN for services claimable that
+// way, the unclaimed page otherwise. Services not marked vulnerable are
+// never reported.
+func (c *Checker) confirm(host string, chain []string, cname string, svc Service, nxdomain bool, raw string) (Result, bool) {
+	if !svc.Vulnerable {
+		return Result{}, false
+	}
+	r := Result{Host: host, Chain: chain, CNAME: cname, Service: svc.Service, Severity: "high"}
+	if nxdomain && (svc.NXDomain || svc.Fingerprint == "NXDOMAIN") {
+		r.Method = ByNXDomain
+		r.Detail = fmt.Sprintf("%s → %s does not resolve (NXDOMAIN): the %s resource can be registered", host, chain[len(chain)-1], svc.Service)
+		r.Evidence = evidence.Raw("A "+host, raw)
+		return r, true
+	}
+	if svc.Fingerprint != "" && svc.Fingerprint != "NXDOMAIN" {
+		if ev, ok := c.fingerprint(host, svc); ok {
+			r.Method = ByFingerprint
+			r.Detail = fmt.Sprintf("%s serves the unclaimed %s page (%q)", host, svc.Service, svc.Fingerprint)
+			r.Evidence = ev
+			return r, true
+		}
+	}
+	return Result{}, false
+}
+
+// fingerprint fetches host over HTTPS then HTTP and looks for the service's
+// unclaimed page.
+func (c *Checker) fingerprint(host string, svc Service) (*evidence.Record, bool) {
+	for _, scheme := range []string{"https", "http"} {
+		req, err := http.NewRequest("GET", scheme+"://"+host+"/", nil)
+		if err != nil {
+			continue
+		}
+		req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
+		resp, err := c.client.Do(req)
+		if err != nil {
+			continue
+		}
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxBody))
+		resp.Body.Close()
+		if svc.HTTPStatus != 0 && resp.StatusCode != svc.HTTPStatus {
+			continue
+		}
+		if strings.Contains(string(body), svc.Fingerprint) {
+			return evidence.HTTP(req, resp, body), true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,367 @@
+// Package urlclass tags URLs (mostly historical ones from gau) by what makes
+// them worth a look: sensitive file extensions (backups, configs, archives),
+// parameters suggestive of SSRF, open redirect, LFI or SQLi (gf-style name
+// lists), admin panels, API versions and secrets in the query string.
+// Recheck tells which of them still answer, calibrated per host against
+// random paths so catch-all (soft-404) hosts do not make every URL live;
+// URLs carrying secrets or action parameters are re-checked without their
+// query string so archived tokens and state-changing requests are not replayed.
+package urlclass
+
+import (
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/FOUEN/narmol/internal/fuzz"
+	"github.com/FOUEN/narmol/internal/params"
+)
+
+// Categories, in report order.
+const (
+	Secret   = "secret"   // credential or token in the query string
+	Backup   = "backup"   // .bak, .old, .sql, editor copies…
+	Config   = "config"   // .env, web.config, .yml, .ini…
+	Archive  = "archive"  // .zip, .tar.gz, .war…
+	Admin    = "admin"    // admin panels and consoles
+	API      = "api"      // /api/, /v1/, /graphql…
+	SSRF     = "ssrf"     // parameter that takes a URL or host
+	Redirect = "redirect" // parameter that takes a redirect target
+	LFI      = "lfi"      // parameter that takes a file or path
+	SQLi     = "sqli"     // parameter that usually reaches a query
+)
+
+// Order lists the categories in report order.
+var Order = []string{Secret, Backup, Config, Archive, Admin, API, SSRF, Redirect, LFI, SQLi}
+
+// Sensitive reports whether a live URL of the category is a finding on its
+// own (an exposed file or credential) rather than a lead.
+func Sensitive(category string) bool {
+	switch category {
+	case Secret, Backup, Config, Archive:
+		return true
+	}
+	return false
+}
+
+// Tag is one category a URL falls into and what matched.
+type Tag struct {
+	Category string `json:"category"`
+	Match    string `json:"match"` // "ext .bak", "param url", "path /admin"…
+}
+
+var (
+	backupExts  = set(".bak", ".backup", ".bkp", ".old", ".orig", ".save", ".swp", ".tmp", ".temp", ".copy", ".sql", ".dump", ".db", ".sqlite", ".mdb")
+	configExts  = set(".env", ".config", ".conf", ".cfg", ".ini", ".yml", ".yaml", ".toml", ".properties", ".htaccess", ".htpasswd", ".npmrc", ".pem", ".key", ".ovpn", ".log")
+	archiveExts = set(".zip", ".tar", ".gz", ".tgz", ".bz2", ".xz", ".rar", ".7z", ".war", ".jar", ".ear")
+	configFiles = set("web.config", "wp-config.php", "config.php", "configuration.php", "settings.py", "local.xml", "docker-compose.yml", "credentials", "id_rsa", ".git/config", ".ds_store", "phpinfo.php")
+	// staticExts are skipped for parameter classes: a cache-buster on a
+	// stylesheet is not an injection point.
+	staticExts = set(".css", ".js", ".png", ".jpg", ".jpeg", ".gif", ".svg", ".ico", ".woff", ".woff2", ".ttf", ".eot", ".webp", ".mp4", ".mp3")
+
+	adminPath = regexp.MustCompile(`(?i)/(admin|administrator|wp-admin|adminer|phpmyadmin|pma|cpanel|manager|management|manage|dashboard|console|backoffice|back-office|controlpanel|panel|sysadmin|_admin|staff|moderator|webadmin|siteadmin)(/|\.[a-z]+$|$)`)
+	apiPath   = regexp.MustCompile(`(?i)/(api|rest|graphql|gql|v[0-9]{1,2}(\.[0-9]+)?)(/|$)`)
+
+	lfiNames    = regexp.MustCompile(`(?i)^(file|filename|file_?name|filepath|file_?path|path|document|doc|folder|dir|directory|include|inc|require|page|template|tpl|layout|style|pdf|download|read|load|conf|root|locate|lang)$`)
+	sqliNames   = regexp.MustCompile(`(?i)^(id|[a-z]+_?id|select|sort|sort_?by|order|order_?by|where|query|search|filter|column|col|field|table|row|from|sel|keyword|report|category|cat|group|limit|offset|results|delete|update|process)$`)
+	actionNames = regexp.MustCompile(`(?i)^(action|do|cmd|command|op|operation|method|task|mode|delete|del|remove|drop|destroy|logout|signout|unsubscribe|subscribe|confirm|approve|reject|activate|deactivate|enable|disable|reset|verify|execute|exec|run|submit|cancel|transfer|pay|purchase|buy|send|invite|vote)$`)
+	secretNames = regexp.MustCompile(`(?i)^(token|access_?token|auth_?token|id_?token|refresh_?token|api_?key|apikey|key|secret|client_?secret|password|passwd|pass|pwd|auth|session|session_?id|sessid|sid|jwt|signature|sig|private_?key|credentials?|aws_?access_?key_?id|x-amz-signature|x-amz-credential|x-amz-security-token)$`)
+
+	jwtValue  = regexp.MustCompile(`^eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}\.`)
+	awsValue  = regexp.MustCompile(`^(AKIA|ASIA)[A-Z0-9]{16}$`)
+	pathValue = regexp.MustCompile(`(\.\./|\.\.%2f|^/etc/|^/var/|^[a-z]:\\|^file:)`)
+	urlValue  = regexp.MustCompile(`(?i)^(https?:)?//|^https?%3a`)
+)
+
+func set(items ...string) map[string]bool {
+	m := make(map[string]bool, len(items))
+	for _, i := range items {
+		m[i] = true
+	}
+	return m
+}
+
+// Classify returns the categories of rawURL in report order, one tag per
+// category. URLs with no category return nil.
+func Classify(rawURL string) []Tag {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil
+	}
+	found := map[string]string{}
+	add := func(category, match string) {
+		if _, ok := found[category]; !ok {
+			found[category] = match
+		}
+	}
+
+	p := strings.ToLower(u.Path)
+	base := path.Base(p)
+	ext := path.Ext(p)
+	if strings.HasSuffix(p, "~") {
+		add(Backup, "editor copy ~")
+	}
+	if backupExts[ext] {
+		add(Backup, "ext "+ext)
+	}
+	if configExts[ext] || configFiles[base] || strings.HasSuffix(p, "/.git/config") {
+		match := "file " + base
+		if configExts[ext] {
+			match = "ext " + ext
+		}
+		add(Config, match)
+	}
+	if archiveExts[ext] {
+		add(Archive, "ext "+ext)
+	}
+	if m := adminPath.FindStringSubmatch(u.Path); m != nil {
+		add(Admin, "path /"+strings.ToLower(m[1]))
+	}
+	if m := apiPath.FindStringSubmatch(u.Path); m != nil {
+		add(API, "path /"+strings.ToLower(m[1]))
+	}
+
+	query := u.Query()
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := query.Get(name)
+		if secretNames.MatchString(name) && value != "" {
+			add(Secret, "param "+name)
+		}
+		if jwtValue.MatchString(value) {
+			add(Secret, "JWT in "+name)
+		}
+		if awsValue.MatchString(value) {
+			add(Secret, "AWS key in "+name)
+		}
+		if staticExts[ext] {
+			continue
+		}
+		for _, c := range params.Classes(name) {
+			switch c {
+			case params.ClassSSRF:
+				add(SSRF, "param "+name)
+			case params.ClassRedirect:
+				add(Redirect, "param "+name)
+			}
+		}
+		if urlValue.MatchString(value) {
+			add(SSRF, "URL in "+name)
+			add(Redirect, "URL in "+name)
+		}
+		if lfiNames.MatchString(name) {
+			add(LFI, "param "+name)
+		}
+		if pathValue.MatchString(strings.ToLower(value)) {
+			add(LFI, "path in "+name)
+		}
+		if sqliNames.MatchString(name) {
+			add(SQLi, "param "+name)
+		}
+	}
+
+	if len(found) == 0 {
+		return nil
+	}
+	tags := make([]Tag, 0, len(found))
+	for _, c := range Order {
+		if m, ok := found[c]; ok {
+			tags = append(tags, Tag{Category: c, Match: m})
+		}
+	}
+	return tags
+}
+
+// Categories returns the category names of tags.
+func Categories(tags []Tag) []string {
+	out := make([]string, len(tags))
+	for i, t := range tags {
+		out[i] = t.Category
+	}
+	return out
+}
+
+// Liveness is the answer of a historical URL today.
+type Liveness struct {
+	URL           string
+	StatusCode    int   // 0 when the request failed
+	ContentLength int64 // bytes read, capped
+	Live          bool
+	SoftNotFound  bool   // answers like a random path on the host (catch-all)
+	Requested     string // URL actually requested (see Replay)
+}
+
+// Replay returns the URL Recheck requests for rawURL: scheme://host/path
+// when the query carries a secret or an action parameter (delete, logout,
+// confirm…), so a leaked token is not used and a state-changing request is
+// not repeated; rawURL unchanged otherwise.
+func Replay(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.RawQuery == "" {
+		return rawURL
+	}
+	strip := false
+	for _, t := range Classify(rawURL) {
+		if t.Category == Secret {
+			strip = true
+		}
+	}
+	for name := range u.Query() {
+		if actionNames.MatchString(name) {
+			strip = true
+		}
+	}
+	if !strip {
+		return rawURL
+	}
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String()
+}
+
+// maxRead caps the body read per URL; the length is only a size hint.
+const maxRead = 256 * 1024
+
+// Recheck requests every URL once (GET, redirects not followed, query
+// dropped where Replay says so) with up to concurrency requests in flight
+// and returns the answers in input order.
+// A URL is live when it answers 2xx/3xx, or 401/403 (it exists, access is
+// denied), unless the answer matches what the host returns for a random
+// path with the same extension (see internal/fuzz signatures).
+func Recheck(urls []string, concurrency int) []Liveness {
+	if concurrency <= 0 {
+		concurrency = 20
+	}
+	client := &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig:     &tls.Config{InsecureSkipVerify: true},
+			MaxIdleConnsPerHost: 4,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	// Calibration probes: one random path per host and extension.
+	probes := map[string]bool{}
+	for _, u := range urls {
+		if key, ok := probeKey(u); ok {
+			probes[key] = true
+		}
+	}
+	var mu sync.Mutex
+	baselines := map[string][]fuzz.Signature{}
+	work := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range work {
+				base, ext, _ := strings.Cut(key, "|")
+				word := randomWord()
+				_, sig, err := fetch(client, base+"/"+word+ext, word)
+				if err != nil {
+					continue
+				}
+				mu.Lock()
+				baselines[key] = append(baselines[key], sig)
+				mu.Unlock()
+			}
+		}()
+	}
+	for key := range probes {
+		work <- key
+	}
+	close(work)
+	wg.Wait()
+
+	out := make([]Liveness, len(urls))
+	idx := make(chan int)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range idx {
+				key, _ := probeKey(urls[i])
+				out[i] = recheck(client, urls[i], baselines[key])
+			}
+		}()
+	}
+	for i := range urls {
+		idx <- i
+	}
+	close(idx)
+	wg.Wait()
+	return out
+}
+
+// probeKey groups a URL with the calibration probe that matches it: its
+// scheme://host and extension.
+func probeKey(rawURL string) (string, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return "", false
+	}
+	return u.Scheme + "://" + u.Host + "|" + path.Ext(u.Path), true
+}
+
+func recheck(client *http.Client, rawURL string, baseline []fuzz.Signature) Liveness {
+	l := Liveness{URL: rawURL, Requested: Replay(rawURL)}
+	u, err := url.Parse(l.Requested)
+	if err != nil {
+		return l
+	}
+	resp, sig, err := fetch(client, l.Requested, path.Base(u.Path))
+	if err != nil {
+		return l
+	}
+	l.StatusCode = resp.StatusCode
+	l.ContentLength = resp.ContentLength
+	l.Live = resp.StatusCode < 400 || resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden
+	for _, b := range baseline {
+		if b.Matches(sig) {
+			l.SoftNotFound = true
+			l.Live = false
+		}
+	}
+	return l
+}
+
+// fetch requests rawURL and returns the response (ContentLength set to the
+// bytes read, body closed) and its signature with word masked out.
+func fetch(client *http.Client, rawURL, word string) (*http.Response, fuzz.Signature, error) {
+	req, err := http.NewRequest("GET", rawURL, nil)
+	if err != nil {
+		return nil, fuzz.Signature{}, err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fuzz.Signature{}, err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxRead))
+	resp.ContentLength = int64(len(body))
+	return resp, fuzz.NewSignature(resp.StatusCode, resp.Header.Get("Location"), body, word), nil
+}
+
+func randomWord() string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return "narmol" + hex.EncodeToString(b)
+}
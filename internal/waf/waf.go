@@ -0,0 +1,341 @@
+// Package waf fingerprints web application firewalls in front of a host.
+// Detection combines passive signals (headers, cookies, server banner) on a
+// normal request with the reaction to a benign request that looks malicious
+// (SQLi/XSS/traversal strings in the query): a block page, or a status the
+// normal request did not get. Workflows use the result to record which
+// hosts sit behind which WAF and, with --waf-backoff, to slow down.
+package waf
+
+import (
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BackoffRateLimit is the request rate (per second) active workflows drop to
+// on hosts behind a WAF when backoff is enabled.
+const BackoffRateLimit = 10
+
+// NoisyTags are the nuclei tags skipped on hosts behind a WAF when backoff
+// is enabled: payload-heavy templates that mostly trip the WAF.
+var NoisyTags = []string{"dos", "fuzz", "fuzzing", "intrusive", "bruteforce", "sqli", "xss"}
+
+// Generic is the name reported when requests are blocked but no known
+// product signature matched.
+const Generic = "Generic"
+
+// probeQuery is the malicious-looking query string sent to trigger the WAF.
+// It carries no working payload.
+const probeQuery = "id=1%27%20OR%20%271%27%3D%271&q=%3Cscript%3Ealert(1)%3C%2Fscript%3E&file=..%2F..%2F..%2F..%2Fetc%2Fpasswd"
+
+const maxBody = 256 * 1024
+
+// signature identifies one product: header matches "name: value" lines
+// (lowercased), cookie matches cookie names, body matches the block page
+// returned to the probe.
+type signature struct {
+	name   string
+	header *regexp.Regexp
+	cookie *regexp.Regexp
+	body   *regexp.Regexp
+}
+
+var signatures = []signature{
+	{
+		name:   "Cloudflare",
+		header: regexp.MustCompile(`(?im)^(cf-ray|cf-cache-status|cf-mitigated):|^server: cloudflare`),
+		cookie: regexp.MustCompile(`^(__cfduid|__cf_bm|cf_clearance)$`),
+		body:   regexp.MustCompile(`(?i)attention required! \| cloudflare|cloudflare ray id`),
+	},
+	{
+		name:   "AWS WAF",
+		header: regexp.MustCompile(`(?im)^x-amzn-waf-action:`),
+		cookie: regexp.MustCompile(`^aws-waf-token$`),
+		body:   regexp.MustCompile(`(?is)request blocked\..*generated by cloudfront|<title>403 forbidden</title>.*awselb`),
+	},
+	{
+		name:   "Akamai",
+		header: regexp.MustCompile(`(?im)^server: akamaighost|^akamai-grn:|^x-akamai-`),
+		cookie: regexp.MustCompile(`^(ak_bmsc|bm_sz|_abck)$`),
+		body:   regexp.MustCompile(`(?is)access denied.*you don't have permission to access.*reference #[0-9a-f.]+`),
+	},
+	{
+		name:   "Imperva Incapsula",
+		header: regexp.MustCompile(`(?im)^x-iinfo:|^x-cdn: (incapsula|imperva)`),
+		cookie: regexp.MustCompile(`^(incap_ses_|visid_incap_|nlbi_)`),
+		body:   regexp.MustCompile(`(?i)incapsula incident id|_incapsula_resource`),
+	},
+	{
+		name:   "Sucuri",
+		header: regexp.MustCompile(`(?im)^x-sucuri-(id|cache|block):|^server: sucuri`),
+		body:   regexp.MustCompile(`(?i)sucuri website firewall|cloudproxy@sucuri\.net`),
+	},
+	{
+		name:   "F5 BIG-IP ASM",
+		header: regexp.MustCompile(`(?im)^x-wa-info:|^server: big-?ip`),
+		cookie: regexp.MustCompile(`^(TS[0-9a-f]{6,}|BIGipServer)`),
+		body:   regexp.MustCompile(`(?i)the requested url was rejected\. please consult with your administrator`),
+	},
+	{
+		name:   "Azure Front Door",
+		header: regexp.MustCompile(`(?im)^x-azure-ref:|^x-msedge-ref:`),
+		body:   regexp.MustCompile(`(?i)the request is blocked\.|azure web application firewall`),
+	},
+	{
+		name:   "Fastly (Signal Sciences)",
+		header: regexp.MustCompile(`(?im)^x-sigsci-|^fastly-io-info:`),
+		body:   regexp.MustCompile(`(?i)signal sciences`),
+	},
+	{
+		name:   "Barracuda",
+		cookie: regexp.MustCompile(`^(barra_counter_session|BNI__BARRACUDA_LB_COOKIE)`),
+		body:   regexp.MustCompile(`(?i)barracuda networks|you are unable to access this website`),
+	},
+	{
+		name:   "FortiWeb",
+		cookie: regexp.MustCompile(`^FORTIWAFSID$`),
+		body:   regexp.MustCompile(`(?i)fortigate|fortiweb|\.fgd_icon`),
+	},
+	{
+		name:   "Citrix NetScaler",
+		header: regexp.MustCompile(`(?im)^(cneonction|ncoection|via: ns-cache)`),
+		cookie: regexp.MustCompile(`^(ns_af|citrix_ns_id|NSC_)`),
+		body:   regexp.MustCompile(`(?i)ns_af=|netscaler`),
+	},
+	{
+		name:   "ModSecurity",
+		header: regexp.MustCompile(`(?im)^server: .*(mod_security|noyb)`),
+		body:   regexp.MustCompile(`(?i)mod_security|this error was generated by mod_security|(?s:not acceptable!.*an appropriate) representation`),
+	},
+	{
+		name: "Wordfence",
+		body: regexp.MustCompile(`(?i)generated by wordfence|a potentially unsafe operation has been detected`),
+	},
+	{
+		name:   "Radware AppWall",
+		header: regexp.MustCompile(`(?im)^x-sl-compstate:`),
+		body:   regexp.MustCompile(`(?is)unauthorized activity has been detected.*case number`),
+	},
+	{
+		name:   "DDoS-Guard",
+		header: regexp.MustCompile(`(?im)^server: ddos-guard`),
+		cookie: regexp.MustCompile(`^__ddg`),
+	},
+	{
+		name:   "StackPath",
+		header: regexp.MustCompile(`(?im)^x-sp-(url|waf)`),
+		body:   regexp.MustCompile(`(?is)stackpath.*you performed an action that triggered the service`),
+	},
+	{
+		name:   "Reblaze",
+		header: regexp.MustCompile(`(?im)^server: reblaze`),
+		cookie: regexp.MustCompile(`^rbzid$`),
+	},
+}
+
+// blockStatus are the statuses a WAF answers a blocked request with.
+var blockStatus = map[int]bool{400: true, 403: true, 405: true, 406: true, 419: true, 429: true, 501: true, 503: true, 999: true}
+
+// Result is the verdict for one host.
+type Result struct {
+	Name    string   // product name, Generic, or "" when no WAF was seen
+	Signals []string // what matched, e.g. "header cf-ray", "probe blocked (403)"
+	Blocked bool     // the malicious-looking probe was blocked
+	Status  int      // status of the probe (0 when the connection was dropped)
+}
+
+// Detected reports whether a WAF was identified.
+func (r Result) Detected() bool { return r.Name != "" }
+
+// String is "Name (signal, signal)".
+func (r Result) String() string {
+	if !r.Detected() {
+		return ""
+	}
+	if len(r.Signals) == 0 {
+		return r.Name
+	}
+	return r.Name + " (" + strings.Join(r.Signals, ", ") + ")"
+}
+
+// NewClient returns the HTTP client Detect expects: no redirects followed,
+// certificates not verified.
+func NewClient() *http.Client {
+	return &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			DialContext: (&net.Dialer{
+				Timeout: 5 * time.Second,
+			}).DialContext,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// Detect fingerprints the WAF in front of baseURL with two requests: the
+// root page, then the root page with probeQuery. It returns an error only
+// when the normal request fails.
+func Detect(client *http.Client, baseURL string) (Result, error) {
+	root := strings.TrimRight(baseURL, "/") + "/"
+	base, err := fetch(client, root)
+	if err != nil {
+		return Result{}, err
+	}
+	var res Result
+	seen := map[string]bool{}
+	match := func(p page, withBody bool) {
+		for _, sig := range signatures {
+			if s := sig.match(p, withBody); s != "" {
+				if res.Name == "" {
+					res.Name = sig.name
+				}
+				if sig.name == res.Name && !seen[s] {
+					seen[s] = true
+					res.Signals = append(res.Signals, s)
+				}
+			}
+		}
+	}
+	match(base, false)
+
+	probe, err := fetch(client, root+"?"+probeQuery)
+	switch {
+	case err != nil:
+		// A reset or timeout right after a normal answer is a drop.
+		res.Blocked = true
+		res.Signals = append(res.Signals, "probe dropped")
+	default:
+		res.Status = probe.status
+		// Block pages are only trusted on a blocking status: a normal page
+		// may well mention a WAF product.
+		blocked := blockStatus[probe.status] && probe.status != base.status
+		match(probe, blocked)
+		if blocked {
+			res.Blocked = true
+			res.Signals = append(res.Signals, "probe blocked ("+strconv.Itoa(probe.status)+")")
+		}
+	}
+	if res.Name == "" && res.Blocked {
+		res.Name = Generic
+	}
+	return res, nil
+}
+
+// DetectAll runs Detect on every URL, concurrency at a time, and returns
+// the hosts where a WAF was seen keyed by URL.
+func DetectAll(urls []string, concurrency int) map[string]Result {
+	if concurrency <= 0 {
+		concurrency = 20
+	}
+	client := NewClient()
+	out := map[string]Result{}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for _, u := range urls {
+		wg.Add(1)
+		go func(u string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			res, err := Detect(client, u)
+			if err != nil || !res.Detected() {
+				return
+			}
+			mu.Lock()
+			out[u] = res
+			mu.Unlock()
+		}(u)
+	}
+	wg.Wait()
+	return out
+}
+
+// Split partitions urls into those behind a WAF (per found) and the rest,
+// keeping their order. URLs with a path are matched by their host base.
+func Split(urls []string, found map[string]Result) (protected, open []string) {
+	for _, u := range urls {
+		_, ok := found[u]
+		if !ok {
+			if p, err := url.Parse(u); err == nil {
+				_, ok = found[p.Scheme+"://"+p.Host]
+			}
+		}
+		if ok {
+			protected = append(protected, u)
+		} else {
+			open = append(open, u)
+		}
+	}
+	return protected, open
+}
+
+// page is what a response looks like to the signatures.
+type page struct {
+	status  int
+	headers string // "name: value" lines, names lowercased
+	cookies []string
+	body    string
+}
+
+func (s signature) match(p page, withBody bool) string {
+	if s.header != nil {
+		if m := s.header.FindString(p.headers); m != "" {
+			name, _, _ := strings.Cut(strings.TrimSpace(m), ":")
+			return "header " + name
+		}
+	}
+	if s.cookie != nil {
+		for _, c := range p.cookies {
+			if s.cookie.MatchString(c) {
+				return "cookie " + c
+			}
+		}
+	}
+	if withBody && s.body != nil && s.body.MatchString(p.body) {
+		return "block page"
+	}
+	return ""
+}
+
+func fetch(client *http.Client, u string) (page, error) {
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return page{}, err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
+	resp, err := client.Do(req)
+	if err != nil {
+		return page{}, err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxBody))
+
+	names := make([]string, 0, len(resp.Header))
+	for k := range resp.Header {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var headers strings.Builder
+	for _, k := range names {
+		for _, v := range resp.Header[k] {
+			headers.WriteString(strings.ToLower(k) + ": " + strings.ToLower(v) + "\n")
+		}
+	}
+	var cookies []string
+	for _, c := range resp.Cookies() {
+		cookies = append(cookies, c.Name)
+	}
+	return page{status: resp.StatusCode, headers: headers.String(), cookies: cookies, body: string(body)}, nil
+}
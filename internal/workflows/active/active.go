@@ -7,8 +7,11 @@ import (
 	"io"
 	"os"
 	"strings"
+	"sync"
 	"sync/atomic"
 
+	"github.com/FOUEN/narmol/internal/brute"
+	"github.com/FOUEN/narmol/internal/cluster"
 	"github.com/FOUEN/narmol/internal/scope"
 	"github.com/FOUEN/narmol/internal/workflows"
 
@@ -24,7 +27,9 @@ func init() {
 
 // ActiveWorkflow finds all subdomains for a domain and probes which ones are active.
 // Step 1: subfinder discovers subdomains, filtering through scope.
+// Step 1b (--brute): dnsx brute-forces more subdomains under the wildcard roots.
 // Step 2: httpx probes all in-scope hosts to find active ones.
+// Step 3: hosts sharing a favicon, page body or JARM are clustered.
 type ActiveWorkflow struct{}
 
 func (w *ActiveWorkflow) Name() string {
@@ -86,6 +91,35 @@ func (w *ActiveWorkflow) Run(domain string, s *scope.Scope, opts workflows.Outpu
 	fmt.Printf("[+] Subfinder found %d subdomains -- %d in scope, %d excluded\n",
 		totalFound, inScope, excluded)
 
+	// ── Step 1b: DNS brute-force (--brute) ────────────────────────────
+	if opts.Brute {
+		fmt.Println("[*] Brute-forcing subdomains with dnsx...")
+		known := make(map[string]bool, len(hosts))
+		for _, h := range hosts {
+			known[h] = true
+		}
+		var mu sync.Mutex
+		var added int
+		stats, bErr := brute.Run(s.WildcardRoots(domain), s, brute.Options{
+			Wordlist:  opts.Wordlist,
+			Resolvers: opts.Resolvers,
+			RateLimit: opts.RateLimit,
+		}, func(r brute.Result) {
+			mu.Lock()
+			defer mu.Unlock()
+			if !known[r.Subdomain] {
+				known[r.Subdomain] = true
+				hosts = append(hosts, r.Subdomain)
+				added++
+			}
+		})
+		if bErr != nil {
+			fmt.Printf("[!] DNS brute-force failed: %v\n", bErr)
+		} else {
+			fmt.Printf("[+] Brute-force found %d subdomains -- %d new (%d wildcard answers dropped)\n", stats.Found, added, stats.Wildcard)
+		}
+	}
+
 	if len(hosts) == 0 {
 		return fmt.Errorf("no subdomains remaining after scope filtering")
 	}
@@ -111,6 +145,8 @@ func (w *ActiveWorkflow) Run(domain string, s *scope.Scope, opts workflows.Outpu
 	}
 
 	var activeCount int64
+	var fpMu sync.Mutex
+	var fingerprints []cluster.Fingerprint
 
 	hxOptions := &httpx_runner.Options{
 		InputTargetHost:    goflags.StringSlice(hosts),
@@ -130,12 +166,21 @@ func (w *ActiveWorkflow) Run(domain string, s *scope.Scope, opts workflows.Outpu
 		TechDetect:         true,
 		OutputCDN:          "true",
 		ExtractTitle:       true,
+		Favicon:            true,
+		Jarm:               true,
+		// The body is kept (capped) for the simhash; it is not written out.
+		ResponseInStdout:          true,
+		MaxResponseBodySizeToSave: maxBodySize,
+		MaxResponseBodySizeToRead: maxBodySize,
 		OnResult: func(r httpx_runner.Result) {
 			if r.Err != nil {
 				return
 			}
 			compact := compactFromResult(r)
 			atomic.AddInt64(&activeCount, 1)
+			fpMu.Lock()
+			fingerprints = append(fingerprints, compact.fingerprint())
+			fpMu.Unlock()
 
 			if textFile == nil && jsonFile == nil {
 				fmt.Println(compact.URL)
@@ -163,6 +208,23 @@ func (w *ActiveWorkflow) Run(domain string, s *scope.Scope, opts workflows.Outpu
 	hxRunner.RunEnumeration()
 	hxRunner.Close()
 
+	// ── Step 3: Same-application clusters ─────────────────────────────
+	clusters := cluster.Group(fingerprints)
+	for _, c := range clusters {
+		line := "[cluster] " + c.Summary()
+		if textFile == nil && jsonFile == nil {
+			fmt.Println(line)
+		}
+		if textFile != nil {
+			fmt.Fprintln(textFile, line)
+		}
+		if jsonFile != nil {
+			if js, err := json.Marshal(clusterResult{Cluster: c, Detail: c.Summary()}); err == nil {
+				fmt.Fprintln(jsonFile, string(js))
+			}
+		}
+	}
+
 	// ── Summary ───────────────────────────────────────────────────────
 	active := atomic.LoadInt64(&activeCount)
 
@@ -173,7 +235,7 @@ func (w *ActiveWorkflow) Run(domain string, s *scope.Scope, opts workflows.Outpu
 		fmt.Printf("[+] Text results saved to: %s\n", opts.TextFile)
 	}
 
-	fmt.Printf("[+] Workflow 'active' completed -- %d active hosts found.\n", active)
+	fmt.Printf("[+] Workflow 'active' completed -- %d active hosts found, %d same-application clusters.\n", active, len(clusters))
 	return nil
 }
 
@@ -191,6 +253,33 @@ type activeResult struct {
 	Tech       []string `json:"tech,omitempty"`
 	CDN        bool     `json:"cdn,omitempty"`
 	CDNName    string   `json:"cdn_name,omitempty"`
+
+	// Fingerprints used to cluster hosts running the same application.
+	ContentLength int                    `json:"content_length"`
+	Favicon       string                 `json:"favicon,omitempty"`      // favicon mmh3
+	JARM          string                 `json:"jarm,omitempty"`         // TLS JARM fingerprint
+	BodySimhash   string                 `json:"body_simhash,omitempty"` // simhash of the body, hex
+	Headers       map[string]interface{} `json:"headers,omitempty"`
+}
+
+// maxBodySize caps the response body httpx reads for the body simhash.
+const maxBodySize = 512 * 1024
+
+func (r activeResult) fingerprint() cluster.Fingerprint {
+	return cluster.Fingerprint{
+		URL:        r.URL,
+		Title:      r.Title,
+		StatusCode: r.StatusCode,
+		Favicon:    r.Favicon,
+		JARM:       r.JARM,
+		BodyHash:   r.BodySimhash,
+	}
+}
+
+// clusterResult is the JSON line written for each same-application cluster.
+type clusterResult struct {
+	cluster.Cluster
+	Detail string `json:"detail"`
 }
 
 // compactFromResult converts a full httpx Result struct into a compact
@@ -208,6 +297,12 @@ func compactFromResult(r httpx_runner.Result) activeResult {
 		Tech:       r.Technologies,
 		CDN:        r.CDN,
 		CDNName:    r.CDNName,
+
+		ContentLength: r.ContentLength,
+		Favicon:       r.FavIconMMH3,
+		JARM:          r.JarmHash,
+		BodySimhash:   cluster.BodySimhash(r.ResponseBody),
+		Headers:       r.ResponseHeaders,
 	}
 }
 
@@ -229,6 +324,12 @@ func compactResult(jsonLine string) (string, string) {
 	jsonGetString(full, "title", &r.Title)
 	jsonGetString(full, "webserver", &r.Webserver)
 	jsonGetString(full, "cdn_name", &r.CDNName)
+	jsonGetString(full, "favicon", &r.Favicon)
+	jsonGetString(full, "jarm_hash", &r.JARM)
+
+	var body string
+	jsonGetString(full, "body", &body)
+	r.BodySimhash = cluster.BodySimhash(body)
 
 	if raw, ok := full["status_code"]; ok {
 		json.Unmarshal(raw, &r.StatusCode)
@@ -239,6 +340,12 @@ func compactResult(jsonLine string) (string, string) {
 	if raw, ok := full["tech"]; ok {
 		json.Unmarshal(raw, &r.Tech)
 	}
+	if raw, ok := full["content_length"]; ok {
+		json.Unmarshal(raw, &r.ContentLength)
+	}
+	if raw, ok := full["header"]; ok {
+		json.Unmarshal(raw, &r.Headers)
+	}
 
 	out, err := json.Marshal(r)
 	if err != nil {
@@ -6,6 +6,7 @@ import (
 	"os"
 	"sync/atomic"
 
+	"github.com/FOUEN/narmol/internal/cluster"
 	"github.com/FOUEN/narmol/internal/scope"
 	"github.com/FOUEN/narmol/internal/workflows"
 
@@ -35,6 +36,13 @@ type aliveResult struct {
 	Title      string `json:"title,omitempty"`
 	Webserver  string `json:"webserver,omitempty"`
 	Scheme     string `json:"scheme"`
+
+	// Fingerprints; the report clusters hosts running the same application.
+	ContentLength int                    `json:"content_length"`
+	Favicon       string                 `json:"favicon,omitempty"`
+	JARM          string                 `json:"jarm,omitempty"`
+	BodySimhash   string                 `json:"body_simhash,omitempty"`
+	Headers       map[string]interface{} `json:"headers,omitempty"`
 }
 
 func (r aliveResult) summary() string {
@@ -96,6 +104,12 @@ func (w *AliveWorkflow) Run(domain string, s *scope.Scope, opts workflows.Output
 		HostMaxErrors:      30,
 		RandomAgent:        true,
 		ExtractTitle:       true,
+		Favicon:            true,
+		Jarm:               true,
+		// The body is kept (capped) for the simhash; it is not written out.
+		ResponseInStdout:          true,
+		MaxResponseBodySizeToSave: 512 * 1024,
+		MaxResponseBodySizeToRead: 512 * 1024,
 		OnResult: func(r httpx_runner.Result) {
 			if r.Err != nil {
 				return
@@ -111,6 +125,12 @@ func (w *AliveWorkflow) Run(domain string, s *scope.Scope, opts workflows.Output
 				Title:      r.Title,
 				Webserver:  r.WebServer,
 				Scheme:     r.Scheme,
+
+				ContentLength: r.ContentLength,
+				Favicon:       r.FavIconMMH3,
+				JARM:          r.JarmHash,
+				BodySimhash:   cluster.BodySimhash(r.ResponseBody),
+				Headers:       r.ResponseHeaders,
 			}
 			atomic.AddInt64(&aliveCount, 1)
 
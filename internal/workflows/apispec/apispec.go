@@ -0,0 +1,235 @@
+package apispec
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	spec "github.com/FOUEN/narmol/internal/apispec"
+	"github.com/FOUEN/narmol/internal/discover"
+	"github.com/FOUEN/narmol/internal/scope"
+	"github.com/FOUEN/narmol/internal/workflows"
+)
+
+func init() {
+	workflows.Register(&APISpecWorkflow{})
+}
+
+// APISpecWorkflow finds exposed API descriptions and inventories their
+// endpoints. Pipeline: subfinder (wildcard scope) → httpx → spec paths
+// (swagger.json, openapi.yaml, /v2/api-docs, Postman collections) → parse.
+type APISpecWorkflow struct{}
+
+func (w *APISpecWorkflow) Name() string { return "apispec" }
+
+func (w *APISpecWorkflow) Description() string {
+	return "API spec discovery: OpenAPI/Swagger/Postman on every live host, parsed into an endpoint inventory (method, params, auth)."
+}
+
+const defaultConcurrency = 20
+
+// apiResult is the JSON output format. Exposed specs are findings
+// (category "apispec", severity); endpoints are URL inventory (source
+// "apispec") tagged with their method, parameters and auth schemes.
+type apiResult struct {
+	URL      string   `json:"url"`
+	Category string   `json:"category,omitempty"` // "apispec" for the spec itself
+	Source   string   `json:"source,omitempty"`   // "apispec" for endpoints
+	Severity string   `json:"severity,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+	Format   string   `json:"format,omitempty"` // openapi, swagger, postman
+	Method   string   `json:"method,omitempty"`
+	Path     string   `json:"path,omitempty"`
+	Params   []string `json:"params,omitempty"`
+	Auth     []string `json:"auth,omitempty"`
+	Unauth   bool     `json:"unauthenticated,omitempty"` // no auth declared: nuclei input
+	Spec     string   `json:"spec,omitempty"`            // spec the endpoint comes from
+}
+
+func (r apiResult) summary() string {
+	if r.Category != "" {
+		return fmt.Sprintf("[%s] %s — %s", strings.ToUpper(r.Severity), r.URL, r.Detail)
+	}
+	auth := "none"
+	if len(r.Auth) > 0 {
+		auth = strings.Join(r.Auth, ",")
+	}
+	line := fmt.Sprintf("%s %s (auth: %s)", r.Method, r.URL, auth)
+	if len(r.Params) > 0 {
+		line += " [" + strings.Join(r.Params, ", ") + "]"
+	}
+	return line
+}
+
+func (w *APISpecWorkflow) Run(domain string, s *scope.Scope, opts workflows.OutputOptions) error {
+	if !s.IsInScope(domain) {
+		return fmt.Errorf("domain %s is not in scope", domain)
+	}
+
+	// ── Step 1: Hosts ─────────────────────────────────────────────────
+	hosts := []string{domain}
+	if s.HasWildcard(domain) {
+		hosts = append(hosts, discover.Subdomains(domain, s)...)
+	}
+
+	// ── Step 2: httpx → live base URLs ────────────────────────────────
+	bases := discover.Live(hosts, s)
+	if len(bases) == 0 {
+		return fmt.Errorf("no live hosts for %s", domain)
+	}
+
+	// ── Output files ──────────────────────────────────────────────────
+	var textFile, jsonFile *os.File
+	var err error
+	if opts.TextFile != "" {
+		textFile, err = os.OpenFile(opts.TextFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open text output: %w", err)
+		}
+		defer textFile.Close()
+	}
+	if opts.JSONFile != "" {
+		jsonFile, err = os.OpenFile(opts.JSONFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open JSON output: %w", err)
+		}
+		defer jsonFile.Close()
+	}
+
+	var mu sync.Mutex
+	emit := func(r apiResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		if textFile == nil && jsonFile == nil {
+			fmt.Println(r.summary())
+		}
+		if textFile != nil {
+			fmt.Fprintln(textFile, r.summary())
+		}
+		if jsonFile != nil {
+			if js, jErr := json.Marshal(r); jErr == nil {
+				fmt.Fprintln(jsonFile, string(js))
+			}
+		}
+	}
+
+	// ── Step 3: Spec discovery + parsing ──────────────────────────────
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	fmt.Printf("[*] Looking for API specs on %d hosts (%d paths each)...\n", len(bases), len(spec.Paths))
+
+	client := newClient()
+	var specs, endpoints, unauth int64
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, base := range bases {
+		wg.Add(1)
+		go func(base string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			for _, sp := range spec.Discover(client, base) {
+				atomic.AddInt64(&specs, 1)
+				results := toResults(sp, s)
+				for _, r := range results {
+					if r.Category == "" {
+						atomic.AddInt64(&endpoints, 1)
+						if r.Unauth {
+							atomic.AddInt64(&unauth, 1)
+						}
+					}
+					emit(r)
+				}
+			}
+		}(base)
+	}
+	wg.Wait()
+
+	// ── Summary ───────────────────────────────────────────────────────
+	if opts.TextFile != "" {
+		fmt.Printf("[+] Text results saved to: %s\n", opts.TextFile)
+	}
+	if opts.JSONFile != "" {
+		fmt.Printf("[+] JSON results saved to: %s\n", opts.JSONFile)
+	}
+	fmt.Printf("[+] Workflow 'apispec' completed — %d specs, %d endpoints (%d unauthenticated)\n", specs, endpoints, unauth)
+	return nil
+}
+
+// newClient returns the HTTP client used to fetch specs.
+func newClient() *http.Client {
+	return &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			DialContext:     (&net.Dialer{Timeout: 3 * time.Second}).DialContext,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// toResults turns a parsed spec into its exposure finding followed by one
+// inventory entry per in-scope endpoint.
+func toResults(sp spec.Spec, s *scope.Scope) []apiResult {
+	var eps []apiResult
+	var open int
+	for _, e := range sp.Endpoints {
+		if u, err := url.Parse(e.URL); err != nil || !s.IsInScope(u.Hostname()) {
+			continue
+		}
+		if e.Unauthenticated() {
+			open++
+		}
+		eps = append(eps, apiResult{
+			URL:    e.URL,
+			Source: "apispec",
+			Format: sp.Format,
+			Method: e.Method,
+			Path:   e.Path,
+			Params: e.Params,
+			Auth:   e.Auth,
+			Unauth: e.Unauthenticated(),
+			Spec:   sp.URL,
+		})
+	}
+
+	title := ""
+	if sp.Title != "" {
+		title = fmt.Sprintf(" %q", sp.Title)
+	}
+	finding := apiResult{
+		URL:      sp.URL,
+		Category: "apispec",
+		Severity: "low",
+		Format:   sp.Format,
+		Detail: fmt.Sprintf("Exposed %s%s — %d endpoints, %d without auth",
+			formatLabels[sp.Format], title, len(eps), open),
+	}
+	// An API that documents unauthenticated write operations is more than
+	// an information leak.
+	for _, r := range eps {
+		if r.Unauth && r.Method != "GET" && r.Method != "HEAD" && r.Method != "OPTIONS" {
+			finding.Severity = "medium"
+			break
+		}
+	}
+	return append([]apiResult{finding}, eps...)
+}
+
+var formatLabels = map[string]string{
+	spec.OpenAPI: "OpenAPI spec",
+	spec.Swagger: "Swagger spec",
+	spec.Postman: "Postman collection",
+}
@@ -0,0 +1,194 @@
+package buckets
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	cloud "github.com/FOUEN/narmol/internal/buckets"
+	"github.com/FOUEN/narmol/internal/evidence"
+	"github.com/FOUEN/narmol/internal/scope"
+	"github.com/FOUEN/narmol/internal/workflows"
+)
+
+func init() {
+	workflows.Register(&BucketsWorkflow{})
+}
+
+// BucketsWorkflow enumerates cloud storage buckets named after the target.
+// Pipeline: base names (domain, keywords) × words → existence on S3, GCS,
+// Azure Blob and DigitalOcean Spaces → anonymous list/read checks.
+type BucketsWorkflow struct{}
+
+func (w *BucketsWorkflow) Name() string { return "buckets" }
+
+func (w *BucketsWorkflow) Description() string {
+	return "Cloud bucket discovery: S3, GCS, Azure Blob and Spaces names from the target, anonymous list/read checks."
+}
+
+const defaultConcurrency = 50
+
+// bucketResult is the JSON output format. Public buckets are findings
+// (category, url, severity, detail); private ones are inventory.
+type bucketResult struct {
+	URL      string   `json:"url"`
+	Category string   `json:"category"` // always "bucket"
+	Provider string   `json:"provider"` // s3, gcs, azure, spaces
+	Bucket   string   `json:"bucket"`
+	Access   string   `json:"access"` // public-list, public-read, private
+	Severity string   `json:"severity,omitempty"`
+	Detail   string   `json:"detail"`
+	Objects  int      `json:"objects,omitempty"`  // objects on the first listing page
+	Sample   []string `json:"sample,omitempty"`   // a few object keys
+	Readable string   `json:"readable,omitempty"` // object downloaded anonymously
+	Evidence string   `json:"evidence,omitempty"` // path to raw request/response proof
+
+	ev *evidence.Record
+}
+
+func (r bucketResult) summary() string {
+	line := fmt.Sprintf("[%s] %s:%s %s — %s", strings.ToUpper(r.Access), r.Provider, r.Bucket, r.URL, r.Detail)
+	if r.Severity != "" {
+		line = fmt.Sprintf("[%s] %s", strings.ToUpper(r.Severity), line)
+	}
+	return line
+}
+
+func (w *BucketsWorkflow) Run(domain string, s *scope.Scope, opts workflows.OutputOptions) error {
+	if !s.IsInScope(domain) {
+		return fmt.Errorf("domain %s is not in scope", domain)
+	}
+
+	// ── Step 1: Candidate names ───────────────────────────────────────
+	words, err := cloud.LoadWords(opts.Wordlist)
+	if err != nil {
+		return err
+	}
+	bases := cloud.Bases(domain, opts.Keywords)
+	names := cloud.Candidates(bases, words, opts.BucketMax)
+	fmt.Printf("[+] %d candidate names from %d bases (%s)\n", len(names), len(bases), strings.Join(bases, ", "))
+
+	// ── Output files ──────────────────────────────────────────────────
+	var textFile, jsonFile *os.File
+	if opts.TextFile != "" {
+		textFile, err = os.OpenFile(opts.TextFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open text output: %w", err)
+		}
+		defer textFile.Close()
+	}
+	if opts.JSONFile != "" {
+		jsonFile, err = os.OpenFile(opts.JSONFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open JSON output: %w", err)
+		}
+		defer jsonFile.Close()
+	}
+
+	store, err := evidence.NewStore(opts.EvidenceDir, opts.EvidenceMaxBytes)
+	if err != nil {
+		return err
+	}
+
+	var mu sync.Mutex
+	emit := func(r bucketResult) {
+		r.Evidence = store.Save(r.Category, r.URL, r.Detail, r.ev)
+		mu.Lock()
+		defer mu.Unlock()
+		if textFile == nil && jsonFile == nil {
+			fmt.Println(r.summary())
+		}
+		if textFile != nil {
+			fmt.Fprintln(textFile, r.summary())
+		}
+		if jsonFile != nil {
+			if js, jErr := json.Marshal(r); jErr == nil {
+				fmt.Fprintln(jsonFile, string(js))
+			}
+		}
+	}
+
+	// ── Step 2: Existence + anonymous access ──────────────────────────
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	checker := cloud.NewChecker(opts.BucketEndpoints)
+	fmt.Printf("[*] Checking %d names on %s (%d in parallel)...\n", len(names), strings.Join(cloud.Providers, ", "), concurrency)
+
+	var found, public int64
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, name := range names {
+		for _, provider := range cloud.Providers {
+			if !cloud.Valid(provider, name) {
+				continue
+			}
+			wg.Add(1)
+			go func(provider, name string) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+				for _, r := range checker.Check(provider, name) {
+					atomic.AddInt64(&found, 1)
+					if r.Access != cloud.AccessPrivate {
+						atomic.AddInt64(&public, 1)
+					}
+					emit(toResult(r))
+				}
+			}(provider, name)
+		}
+	}
+	wg.Wait()
+
+	// ── Summary ───────────────────────────────────────────────────────
+	if opts.TextFile != "" {
+		fmt.Printf("[+] Text results saved to: %s\n", opts.TextFile)
+	}
+	if opts.JSONFile != "" {
+		fmt.Printf("[+] JSON results saved to: %s\n", opts.JSONFile)
+	}
+	fmt.Printf("[+] Workflow 'buckets' completed — %d buckets found, %d publicly accessible\n", found, public)
+	return nil
+}
+
+// toResult ranks a bucket: anonymous listing is high, anonymous reads
+// without listing medium, private buckets carry no severity.
+func toResult(r cloud.Result) bucketResult {
+	out := bucketResult{
+		URL:      r.URL,
+		Category: "bucket",
+		Provider: r.Provider,
+		Bucket:   r.Bucket,
+		Access:   r.Access,
+		Objects:  r.Objects,
+		Sample:   r.Sample,
+		Readable: r.Readable,
+		ev:       r.Evidence,
+	}
+	label := providerLabels[r.Provider] + " " + r.Bucket
+	switch r.Access {
+	case cloud.AccessList:
+		out.Severity = "high"
+		out.Detail = fmt.Sprintf("%s allows anonymous listing (%d objects on first page)", label, r.Objects)
+		if r.Readable != "" {
+			out.Detail += ", objects readable"
+		}
+	case cloud.AccessRead:
+		out.Severity = "medium"
+		out.Detail = label + " objects readable anonymously (listing denied): " + r.Readable
+	default:
+		out.Detail = label + " exists, no anonymous access"
+	}
+	return out
+}
+
+var providerLabels = map[string]string{
+	cloud.S3:     "S3 bucket",
+	cloud.GCS:    "GCS bucket",
+	cloud.Azure:  "Azure storage",
+	cloud.Spaces: "Spaces bucket",
+}
@@ -4,14 +4,19 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
 
+	"github.com/FOUEN/narmol/internal/auth"
 	"github.com/FOUEN/narmol/internal/scope"
 	"github.com/FOUEN/narmol/internal/workflows"
 
+	"github.com/go-rod/rod/lib/launcher"
 	katana_output "github.com/projectdiscovery/katana/pkg/output"
+	katana_engine "github.com/projectdiscovery/katana/pkg/engine"
+	katana_hybrid "github.com/projectdiscovery/katana/pkg/engine/hybrid"
 	katana_standard "github.com/projectdiscovery/katana/pkg/engine/standard"
 	katana_types "github.com/projectdiscovery/katana/pkg/types"
 )
@@ -21,7 +26,9 @@ func init() {
 }
 
 // CrawlWorkflow crawls alive hosts to discover endpoints, links, and JS files.
-// Uses katana standard (HTTP) engine.
+// Uses katana standard (HTTP) engine, or the hybrid (headless Chrome) engine
+// with opts.Headless when a local browser is available: it renders SPA
+// routes, captures XHR/fetch requests and fills forms.
 type CrawlWorkflow struct{}
 
 func (w *CrawlWorkflow) Name() string { return "crawl" }
@@ -30,17 +37,34 @@ func (w *CrawlWorkflow) Description() string {
 	return "Crawl alive hosts with katana to discover endpoints, links, and JS files."
 }
 
-// crawlResult is the JSON output format.
+// crawlResult is the JSON output format. XHR/fetch requests captured in
+// headless mode have source "xhr" and their method; forms have source
+// "form", the page they were found on as url, and the form itself.
 type crawlResult struct {
-	URL    string `json:"url"`
-	Source string `json:"source"`
-	Tag    string `json:"tag,omitempty"`
-	Attr   string `json:"attribute,omitempty"`
+	URL    string     `json:"url"`
+	Source string     `json:"source"`
+	Tag    string     `json:"tag,omitempty"`
+	Attr   string     `json:"attribute,omitempty"`
+	Method string     `json:"method,omitempty"`
+	Form   *crawlForm `json:"form,omitempty"`
+}
+
+// crawlForm is a form and its field names.
+type crawlForm struct {
+	Method  string   `json:"method"`
+	Action  string   `json:"action"`
+	Enctype string   `json:"enctype,omitempty"`
+	Fields  []string `json:"fields"`
 }
 
 func (r crawlResult) summary() string {
 	extra := ""
-	if r.Tag != "" {
+	switch {
+	case r.Form != nil:
+		extra = fmt.Sprintf(" [form %s %s: %s]", r.Form.Method, r.Form.Action, strings.Join(r.Form.Fields, ", "))
+	case r.Method != "":
+		extra = fmt.Sprintf(" (%s %s)", r.Source, r.Method)
+	case r.Tag != "":
 		extra = fmt.Sprintf(" (%s.%s)", r.Tag, r.Attr)
 	}
 	return fmt.Sprintf("%s%s", r.URL, extra)
@@ -57,9 +81,13 @@ func (w *CrawlWorkflow) Run(domain string, s *scope.Scope, opts workflows.Output
 		target = "https://" + target
 	}
 
+	sess, err := auth.New(opts.Auth, domain, s)
+	if err != nil {
+		return err
+	}
+
 	// ── Output files ──────────────────────────────────────────────────
 	var textFile, jsonFile *os.File
-	var err error
 	if opts.TextFile != "" {
 		textFile, err = os.OpenFile(opts.TextFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 		if err != nil {
@@ -76,65 +104,118 @@ func (w *CrawlWorkflow) Run(domain string, s *scope.Scope, opts workflows.Output
 	}
 
 	seen := &sync.Map{}
-	var count int64
+	var count, formCount int64
+
+	var mu sync.Mutex
+	emit := func(r crawlResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		if textFile == nil && jsonFile == nil {
+			fmt.Println(r.summary())
+		}
+		if textFile != nil {
+			fmt.Fprintln(textFile, r.summary())
+		}
+		if jsonFile != nil {
+			if js, jErr := json.Marshal(r); jErr == nil {
+				fmt.Fprintln(jsonFile, string(js))
+			}
+		}
+	}
+
+	// XHR requests and forms of every crawled page (in-scope, new ones only).
+	extras := func(result katana_output.Result) {
+		if result.Response == nil {
+			return
+		}
+		for _, x := range result.Response.XhrRequests {
+			if x.URL == "" || !s.IsInScope(x.URL) {
+				continue
+			}
+			if _, loaded := seen.LoadOrStore(x.Method+" "+x.URL, true); loaded {
+				continue
+			}
+			atomic.AddInt64(&count, 1)
+			emit(crawlResult{URL: x.URL, Source: "xhr", Method: x.Method})
+		}
+		for _, f := range result.Response.Forms {
+			form := &crawlForm{Method: strings.ToUpper(f.Method), Action: f.Action, Enctype: f.Enctype, Fields: f.Parameters}
+			if form.Method == "" {
+				form.Method = "GET"
+			}
+			if form.Action == "" {
+				form.Action = result.Request.URL
+			}
+			if !s.IsInScope(form.Action) {
+				continue
+			}
+			fields := append([]string(nil), form.Fields...)
+			sort.Strings(fields)
+			if _, loaded := seen.LoadOrStore("form "+form.Method+" "+form.Action+" "+strings.Join(fields, ","), true); loaded {
+				continue
+			}
+			atomic.AddInt64(&formCount, 1)
+			emit(crawlResult{URL: result.Request.URL, Source: "form", Form: form})
+		}
+	}
 
 	fmt.Printf("[*] Crawling %s with katana...\n", target)
 
 	katanaOpts := &katana_types.Options{
-		MaxDepth:     3,
-		FieldScope:   "rdn",
-		Concurrency:  10,
-		Parallelism:  10,
-		Timeout:      10,
-		RateLimit:    100,
-		Strategy:     "breadth-first",
-		KnownFiles:   "all",
-		NoColors:     true,
-		Silent:       true,
+		MaxDepth:       3,
+		BodyReadSize:   4 << 20, // katana reads no body when 0
+		FieldScope:     "rdn",
+		Concurrency:    10,
+		Parallelism:    10,
+		Timeout:        10,
+		RateLimit:      100,
+		Strategy:       "breadth-first",
+		KnownFiles:     "all",
+		NoColors:       true,
+		Silent:         true,
+		FormExtraction: true,
 		OnResult: func(result katana_output.Result) {
 			u := result.Request.URL
-			if u == "" {
+			if u == "" || !s.IsInScope(u) {
 				return
 			}
+			extras(result)
 			if _, loaded := seen.LoadOrStore(u, true); loaded {
 				return
 			}
-			if !s.IsInScope(u) {
-				return
-			}
 
-			r := crawlResult{
+			atomic.AddInt64(&count, 1)
+			emit(crawlResult{
 				URL:    u,
 				Source: result.Request.Source,
 				Tag:    result.Request.Tag,
 				Attr:   result.Request.Attribute,
-			}
-			atomic.AddInt64(&count, 1)
-
-			if textFile == nil && jsonFile == nil {
-				fmt.Println(r.summary())
-			}
-			if textFile != nil {
-				fmt.Fprintln(textFile, r.summary())
-			}
-			if jsonFile != nil {
-				if js, jErr := json.Marshal(r); jErr == nil {
-					fmt.Fprintln(jsonFile, string(js))
-				}
-			}
+			})
 		},
 	}
 
-	crawlerOptions, err := katana_types.NewCrawlerOptions(katanaOpts)
-	if err != nil {
-		return fmt.Errorf("failed to create crawler options: %w", err)
+	// With a session for the target, every request carries it and the
+	// crawl stays on the session hosts. Redirects are not followed: katana
+	// follows them to any host, headers included. Headless mode is off:
+	// Chrome sends extra headers to every host a page loads from, and form
+	// filling would submit logout and delete forms while logged in.
+	headless := opts.Headless
+	if sess.Applies(target) {
+		fmt.Printf("[+] Authenticated session: %s\n", sess)
+		katanaOpts.CustomHeaders = sess.HeaderLines()
+		katanaOpts.Scope = sess.ScopeRegex()
+		katanaOpts.DisableRedirects = true
+		if headless {
+			fmt.Println("[!] --headless is not used with a session (the browser would send it to third-party hosts) — standard crawling")
+			headless = false
+		}
 	}
-	defer crawlerOptions.Close()
 
-	crawler, err := katana_standard.New(crawlerOptions)
+	crawler, crawlerOptions, err := newCrawler(katanaOpts, headless)
 	if err != nil {
-		return fmt.Errorf("failed to create crawler: %w", err)
+		return err
 	}
+	defer crawlerOptions.Close()
 	defer crawler.Close()
 
 	if err := crawler.Crawl(target); err != nil {
@@ -149,6 +230,48 @@ func (w *CrawlWorkflow) Run(domain string, s *scope.Scope, opts workflows.Output
 	if opts.JSONFile != "" {
 		fmt.Printf("[+] JSON results saved to: %s\n", opts.JSONFile)
 	}
-	fmt.Printf("[+] Workflow 'crawl' completed — %d URLs discovered\n", total)
+	fmt.Printf("[+] Workflow 'crawl' completed — %d URLs discovered, %d forms\n", total, atomic.LoadInt64(&formCount))
 	return nil
 }
+
+// newCrawler returns the hybrid (headless Chrome) crawler when headless is
+// set and a browser starts, the standard one otherwise.
+func newCrawler(katanaOpts *katana_types.Options, headless bool) (katana_engine.Engine, *katana_types.CrawlerOptions, error) {
+	if headless {
+		if path, ok := launcher.LookPath(); !ok {
+			fmt.Println("[!] No Chrome/Chromium found — falling back to standard crawling")
+		} else {
+			hybridOpts := *katanaOpts
+			hybridOpts.Headless = true
+			hybridOpts.UseInstalledChrome = true
+			hybridOpts.SystemChromePath = path
+			hybridOpts.XhrExtraction = true
+			hybridOpts.AutomaticFormFill = true
+			// Chrome refuses to start sandboxed as root.
+			hybridOpts.HeadlessNoSandbox = os.Geteuid() == 0
+
+			crawlerOptions, err := katana_types.NewCrawlerOptions(&hybridOpts)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to create crawler options: %w", err)
+			}
+			crawler, err := katana_hybrid.New(crawlerOptions)
+			if err == nil {
+				fmt.Printf("[+] Headless crawling with %s\n", path)
+				return crawler, crawlerOptions, nil
+			}
+			crawlerOptions.Close()
+			fmt.Printf("[!] Could not start headless browser (%s) — falling back to standard crawling\n", err)
+		}
+	}
+
+	crawlerOptions, err := katana_types.NewCrawlerOptions(katanaOpts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create crawler options: %w", err)
+	}
+	crawler, err := katana_standard.New(crawlerOptions)
+	if err != nil {
+		crawlerOptions.Close()
+		return nil, nil, fmt.Errorf("failed to create crawler: %w", err)
+	}
+	return crawler, crawlerOptions, nil
+}
@@ -0,0 +1,461 @@
+package dnsaudit
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/FOUEN/narmol/internal/scope"
+	"github.com/FOUEN/narmol/internal/workflows"
+
+	dns "github.com/miekg/dns"
+)
+
+func init() {
+	workflows.Register(&DNSAuditWorkflow{})
+}
+
+// DNSAuditWorkflow assesses the DNS security posture of a root domain:
+// zone transfer, SPF, DMARC, DKIM, DNSSEC, CAA and dangling NS/MX records.
+type DNSAuditWorkflow struct{}
+
+func (w *DNSAuditWorkflow) Name() string { return "dnsaudit" }
+
+func (w *DNSAuditWorkflow) Description() string {
+	return "DNS posture: AXFR, SPF, DMARC, DKIM, DNSSEC, CAA, dangling NS/MX."
+}
+
+// auditResult is the JSON output format.
+type auditResult struct {
+	Target   string `json:"target"`
+	Phase    string `json:"phase"` // always "dns"
+	Check    string `json:"check"` // axfr, spf, dmarc, dkim, dnssec, caa, ns, mx
+	Severity string `json:"severity"`
+	Detail   string `json:"detail"`
+	Record   string `json:"record,omitempty"`
+}
+
+func (r auditResult) summary() string {
+	line := fmt.Sprintf("[%s] %s [%s] %s", strings.ToUpper(r.Severity), r.Target, r.Check, r.Detail)
+	if r.Record != "" {
+		line += " — " + r.Record
+	}
+	return line
+}
+
+func (w *DNSAuditWorkflow) Run(domain string, s *scope.Scope, opts workflows.OutputOptions) error {
+	if !s.IsInScope(domain) {
+		return fmt.Errorf("domain %s is not in scope", domain)
+	}
+
+	// ── Output files ──────────────────────────────────────────────────
+	var textFile, jsonFile *os.File
+	var err error
+	if opts.TextFile != "" {
+		textFile, err = os.OpenFile(opts.TextFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open text output: %w", err)
+		}
+		defer textFile.Close()
+	}
+	if opts.JSONFile != "" {
+		jsonFile, err = os.OpenFile(opts.JSONFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open JSON output: %w", err)
+		}
+		defer jsonFile.Close()
+	}
+
+	resolver := ""
+	if len(opts.Resolvers) > 0 {
+		resolver = opts.Resolvers[0]
+	}
+	a := NewAuditor(resolver)
+	fmt.Printf("[*] Auditing DNS posture of %s via %s...\n", domain, a.Resolver)
+
+	results := a.Audit(domain)
+	bySeverity := map[string]int{}
+	for _, r := range results {
+		bySeverity[r.Severity]++
+		if textFile == nil && jsonFile == nil {
+			fmt.Println(r.summary())
+		}
+		if textFile != nil {
+			fmt.Fprintln(textFile, r.summary())
+		}
+		if jsonFile != nil {
+			if js, jErr := json.Marshal(r); jErr == nil {
+				fmt.Fprintln(jsonFile, string(js))
+			}
+		}
+	}
+
+	// ── Summary ───────────────────────────────────────────────────────
+	if opts.TextFile != "" {
+		fmt.Printf("[+] Text results saved to: %s\n", opts.TextFile)
+	}
+	if opts.JSONFile != "" {
+		fmt.Printf("[+] JSON results saved to: %s\n", opts.JSONFile)
+	}
+	fmt.Printf("[+] Workflow 'dnsaudit' completed — %d findings (%d high, %d medium, %d low, %d info)\n",
+		len(results), bySeverity["high"], bySeverity["medium"], bySeverity["low"], bySeverity["info"])
+	return nil
+}
+
+// ─── Auditor ────────────────────────────────────────────────────────────
+
+// maxSPFLookups is the RFC 7208 limit on DNS-querying SPF terms.
+const maxSPFLookups = 10
+
+// dkimSelectors are selectors used by common mail providers and MTAs.
+var dkimSelectors = []string{
+	"default", "dkim", "mail", "selector1", "selector2", "google", "k1", "k2",
+	"s1", "s2", "smtp", "mandrill", "mxvault", "zoho", "amazonses", "sendgrid",
+	"everlytickey1", "everlytickey2", "protonmail", "fm1", "fm2", "mailjet",
+}
+
+// Auditor runs the DNS checks through one recursive resolver. Authoritative
+// servers are contacted directly on NSPort for zone transfers, so pointing
+// Resolver and NSPort at an in-process server makes every check testable.
+type Auditor struct {
+	Resolver string // host:port of the recursive resolver
+	NSPort   string // port of the authoritative servers (AXFR)
+	Timeout  time.Duration
+}
+
+// NewAuditor returns an auditor using resolver ("1.1.1.1", "udp:8.8.8.8:53"),
+// falling back to the system resolver and then to 8.8.8.8.
+func NewAuditor(resolver string) *Auditor {
+	if resolver == "" {
+		resolver = "8.8.8.8"
+		if conf, err := dns.ClientConfigFromFile("/etc/resolv.conf"); err == nil && len(conf.Servers) > 0 {
+			resolver = net.JoinHostPort(conf.Servers[0], conf.Port)
+		}
+	}
+	for _, p := range []string{"udp:", "tcp:"} {
+		resolver = strings.TrimPrefix(resolver, p)
+	}
+	if _, _, err := net.SplitHostPort(resolver); err != nil {
+		resolver = net.JoinHostPort(resolver, "53")
+	}
+	return &Auditor{Resolver: resolver, NSPort: "53", Timeout: 5 * time.Second}
+}
+
+// Audit runs every check against domain.
+func (a *Auditor) Audit(domain string) []auditResult {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	var results []auditResult
+	add := func(check, severity, detail, record string) {
+		results = append(results, auditResult{
+			Target: domain, Phase: "dns", Check: check, Severity: severity, Detail: detail, Record: record,
+		})
+	}
+
+	// ── NS: dangling delegations + AXFR ───────────────────────────────
+	nsHosts := a.names(domain, dns.TypeNS)
+	if len(nsHosts) == 0 {
+		add("ns", "info", "No NS records returned", "")
+	}
+	for _, ns := range nsHosts {
+		ips := a.addresses(ns)
+		if len(ips) == 0 {
+			if a.nxdomain(ns) {
+				add("ns", "high", "Dangling NS record: name server does not resolve (delegation takeover risk)", ns)
+			}
+			continue
+		}
+		// Every address: anycast and multi-homed servers may differ, and
+		// the first one may be unreachable.
+		for _, ip := range ips {
+			if n := a.axfr(domain, ip); n > 0 {
+				add("axfr", "high", fmt.Sprintf("Zone transfer (AXFR) allowed on %s — %d records disclosed", ip, n), ns)
+				break
+			}
+		}
+	}
+
+	// ── MX: dangling mail exchangers ──────────────────────────────────
+	mxHosts := a.names(domain, dns.TypeMX)
+	for _, mx := range mxHosts {
+		if mx == "" || mx == "." {
+			continue // null MX (RFC 7505)
+		}
+		if len(a.addresses(mx)) == 0 && a.nxdomain(mx) {
+			add("mx", "medium", "Dangling MX record: mail exchanger does not resolve", mx)
+		}
+	}
+	sendsMail := len(mxHosts) > 0
+
+	// ── SPF ───────────────────────────────────────────────────────────
+	missing := "low"
+	if sendsMail {
+		missing = "medium"
+	}
+	spf := a.spfRecords(domain)
+	switch {
+	case len(spf) == 0:
+		add("spf", missing, "No SPF record (domain can be spoofed)", "")
+	case len(spf) > 1:
+		add("spf", "medium", "Multiple SPF records (permerror, SPF is ignored)", strings.Join(spf, " | "))
+	default:
+		a.checkSPF(domain, spf[0], add)
+	}
+
+	// ── DMARC ─────────────────────────────────────────────────────────
+	a.checkDMARC(domain, missing, add)
+
+	// ── DKIM ──────────────────────────────────────────────────────────
+	var selectors []string
+	for _, sel := range dkimSelectors {
+		for _, txt := range a.txt(sel + "._domainkey." + domain) {
+			if strings.Contains(txt, "p=") || strings.HasPrefix(txt, "v=DKIM1") {
+				selectors = append(selectors, sel)
+				break
+			}
+		}
+	}
+	if len(selectors) > 0 {
+		add("dkim", "info", "DKIM keys found for selectors: "+strings.Join(selectors, ", "), "")
+	} else if sendsMail {
+		add("dkim", "low", "No DKIM key found for common selectors", "")
+	}
+
+	// ── DNSSEC ────────────────────────────────────────────────────────
+	if len(a.records(domain, dns.TypeDNSKEY)) == 0 {
+		add("dnssec", "low", "DNSSEC not enabled (no DNSKEY records)", "")
+	}
+
+	// ── CAA ───────────────────────────────────────────────────────────
+	var issuers []string
+	for _, rr := range a.records(domain, dns.TypeCAA) {
+		if caa, ok := rr.(*dns.CAA); ok && strings.HasPrefix(caa.Tag, "issue") {
+			issuers = append(issuers, caa.Value)
+		}
+	}
+	if len(issuers) == 0 {
+		add("caa", "low", "No CAA records (any certificate authority may issue)", "")
+	} else {
+		add("caa", "info", "CAA restricts issuance to: "+strings.Join(issuers, ", "), "")
+	}
+
+	return results
+}
+
+// checkSPF evaluates a single SPF record: the "all" qualifier, deprecated
+// mechanisms and the total DNS lookup count.
+func (a *Auditor) checkSPF(domain, record string, add func(check, severity, detail, record string)) {
+	terms := strings.Fields(strings.ToLower(record))
+	all := ""
+	redirect := false
+	for _, t := range terms[1:] {
+		switch {
+		case strings.TrimLeft(t, "+-~?") == "all":
+			all = t
+		case strings.HasPrefix(t, "redirect="):
+			redirect = true
+		case strings.TrimLeft(t, "+-~?") == "ptr" || strings.HasPrefix(strings.TrimLeft(t, "+-~?"), "ptr:"):
+			add("spf", "low", "SPF uses the deprecated ptr mechanism", record)
+		}
+	}
+
+	switch all {
+	case "+all", "all":
+		add("spf", "high", "SPF ends in +all (any host may send mail for the domain)", record)
+	case "?all":
+		add("spf", "low", "SPF ends in ?all (neutral, spoofed mail is not rejected)", record)
+	case "":
+		if !redirect {
+			add("spf", "low", "SPF has no 'all' mechanism (defaults to neutral)", record)
+		}
+	}
+
+	if n := a.spfLookups(domain, record, 0, map[string]bool{domain: true}); n > maxSPFLookups {
+		add("spf", "medium", fmt.Sprintf("SPF needs %d DNS lookups (limit %d, permerror)", n, maxSPFLookups), record)
+	}
+}
+
+// spfLookups counts the DNS-querying terms of record, following include and
+// redirect targets.
+func (a *Auditor) spfLookups(domain, record string, depth int, seen map[string]bool) int {
+	if depth > maxSPFLookups {
+		return 0
+	}
+	count := 0
+	for _, t := range strings.Fields(strings.ToLower(record))[1:] {
+		t = strings.TrimLeft(t, "+-~?")
+		var target string
+		switch {
+		case strings.HasPrefix(t, "include:"):
+			target = strings.TrimPrefix(t, "include:")
+		case strings.HasPrefix(t, "redirect="):
+			target = strings.TrimPrefix(t, "redirect=")
+		case t == "a", t == "mx", t == "ptr",
+			strings.HasPrefix(t, "a:"), strings.HasPrefix(t, "a/"),
+			strings.HasPrefix(t, "mx:"), strings.HasPrefix(t, "mx/"),
+			strings.HasPrefix(t, "ptr:"), strings.HasPrefix(t, "exists:"):
+			count++
+			continue
+		default:
+			continue
+		}
+
+		count++
+		if seen[target] {
+			continue
+		}
+		seen[target] = true
+		if nested := a.spfRecords(target); len(nested) == 1 {
+			count += a.spfLookups(target, nested[0], depth+1, seen)
+		}
+	}
+	return count
+}
+
+// checkDMARC evaluates the _dmarc policy of domain.
+func (a *Auditor) checkDMARC(domain, missing string, add func(check, severity, detail, record string)) {
+	var record string
+	for _, txt := range a.txt("_dmarc." + domain) {
+		if strings.HasPrefix(strings.ToLower(txt), "v=dmarc1") {
+			record = txt
+			break
+		}
+	}
+	if record == "" {
+		add("dmarc", missing, "No DMARC record (spoofed mail is not rejected)", "")
+		return
+	}
+
+	tags := map[string]string{}
+	for _, part := range strings.Split(record, ";") {
+		if k, v, ok := strings.Cut(strings.TrimSpace(part), "="); ok {
+			tags[strings.ToLower(strings.TrimSpace(k))] = strings.ToLower(strings.TrimSpace(v))
+		}
+	}
+	switch tags["p"] {
+	case "reject", "quarantine":
+	case "none":
+		add("dmarc", "low", "DMARC policy is p=none (monitoring only)", record)
+	default:
+		add("dmarc", "medium", "DMARC record has no valid policy (p=)", record)
+	}
+	if pct, err := strconv.Atoi(tags["pct"]); err == nil && pct < 100 {
+		add("dmarc", "low", fmt.Sprintf("DMARC applies to %d%% of mail only", pct), record)
+	}
+	if tags["rua"] == "" {
+		add("dmarc", "info", "DMARC has no aggregate report address (rua)", record)
+	}
+}
+
+// ─── DNS helpers ────────────────────────────────────────────────────────
+
+// query sends one question to the resolver, retrying over TCP when the UDP
+// answer is truncated.
+func (a *Auditor) query(name string, qtype uint16) (*dns.Msg, error) {
+	m := new(dns.Msg)
+	m.SetQuestion(dns.Fqdn(name), qtype)
+	m.SetEdns0(4096, qtype == dns.TypeDNSKEY)
+
+	c := &dns.Client{Timeout: a.Timeout}
+	resp, _, err := c.Exchange(m, a.Resolver)
+	if err == nil && resp.Truncated {
+		c.Net = "tcp"
+		resp, _, err = c.Exchange(m, a.Resolver)
+	}
+	return resp, err
+}
+
+// records returns the answer records of type qtype for name.
+func (a *Auditor) records(name string, qtype uint16) []dns.RR {
+	resp, err := a.query(name, qtype)
+	if err != nil || resp.Rcode != dns.RcodeSuccess {
+		return nil
+	}
+	var out []dns.RR
+	for _, rr := range resp.Answer {
+		if rr.Header().Rrtype == qtype {
+			out = append(out, rr)
+		}
+	}
+	return out
+}
+
+// names returns the target host names of NS or MX records, sorted.
+func (a *Auditor) names(name string, qtype uint16) []string {
+	var out []string
+	for _, rr := range a.records(name, qtype) {
+		switch r := rr.(type) {
+		case *dns.NS:
+			out = append(out, strings.ToLower(strings.TrimSuffix(r.Ns, ".")))
+		case *dns.MX:
+			out = append(out, strings.ToLower(strings.TrimSuffix(r.Mx, ".")))
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// addresses returns the A and AAAA addresses of host.
+func (a *Auditor) addresses(host string) []string {
+	var out []string
+	for _, rr := range a.records(host, dns.TypeA) {
+		out = append(out, rr.(*dns.A).A.String())
+	}
+	for _, rr := range a.records(host, dns.TypeAAAA) {
+		out = append(out, rr.(*dns.AAAA).AAAA.String())
+	}
+	return out
+}
+
+// nxdomain reports whether the resolver says host does not exist. Timeouts
+// and SERVFAIL are not treated as dangling, to avoid false positives.
+func (a *Auditor) nxdomain(host string) bool {
+	resp, err := a.query(host, dns.TypeA)
+	return err == nil && resp.Rcode == dns.RcodeNameError
+}
+
+func (a *Auditor) txt(name string) []string {
+	var out []string
+	for _, rr := range a.records(name, dns.TypeTXT) {
+		out = append(out, strings.Join(rr.(*dns.TXT).Txt, ""))
+	}
+	return out
+}
+
+func (a *Auditor) spfRecords(domain string) []string {
+	var out []string
+	for _, txt := range a.txt(domain) {
+		if l := strings.ToLower(txt); l == "v=spf1" || strings.HasPrefix(l, "v=spf1 ") {
+			out = append(out, txt)
+		}
+	}
+	return out
+}
+
+// axfr attempts a zone transfer from the server at ip and returns the number
+// of records received (0 when refused).
+func (a *Auditor) axfr(domain, ip string) int {
+	m := new(dns.Msg)
+	m.SetAxfr(dns.Fqdn(domain))
+
+	t := &dns.Transfer{DialTimeout: a.Timeout, ReadTimeout: a.Timeout}
+	env, err := t.In(m, net.JoinHostPort(ip, a.NSPort))
+	if err != nil {
+		return 0
+	}
+	n, failed := 0, false
+	for e := range env { // drain fully so the transfer goroutine exits
+		if e.Error != nil {
+			failed = true
+		}
+		n += len(e.RR)
+	}
+	if failed {
+		return 0
+	}
+	return n
+}
@@ -0,0 +1,169 @@
+package dnsaudit
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	dns "github.com/miekg/dns"
+)
+
+// fakeZone answers every query from a fixed record set: names it does not
+// know are NXDOMAIN, zones in axfr allow transfers, the others refuse them.
+type fakeZone struct {
+	records map[string][]dns.RR // owner name (FQDN) → records
+	axfr    map[string]bool
+}
+
+func (z *fakeZone) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
+	m := new(dns.Msg)
+	m.SetReply(r)
+	q := r.Question[0]
+	name := strings.ToLower(q.Name)
+	rrs, known := z.records[name]
+	switch {
+	case q.Qtype == dns.TypeAXFR:
+		if !z.axfr[name] {
+			m.Rcode = dns.RcodeRefused
+			break
+		}
+		var soa dns.RR
+		var body []dns.RR
+		for owner, rrs := range z.records {
+			if !dns.IsSubDomain(name, owner) {
+				continue
+			}
+			for _, rr := range rrs {
+				if rr.Header().Rrtype == dns.TypeSOA && owner == name {
+					soa = rr
+				} else {
+					body = append(body, rr)
+				}
+			}
+		}
+		m.Answer = append(append([]dns.RR{soa}, body...), soa)
+	case !known:
+		m.Rcode = dns.RcodeNameError
+	default:
+		for _, rr := range rrs {
+			if rr.Header().Rrtype == q.Qtype {
+				m.Answer = append(m.Answer, rr)
+			}
+		}
+	}
+	w.WriteMsg(m)
+}
+
+// fakeDNS serves z on 127.0.0.1 over UDP (the resolver) and TCP (the
+// authoritative port for AXFR) and returns an auditor pointed at both.
+func fakeDNS(t *testing.T, z *fakeZone) *Auditor {
+	t.Helper()
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, srv := range []*dns.Server{{PacketConn: pc, Handler: z}, {Listener: l, Handler: z}} {
+		started := make(chan struct{})
+		srv.NotifyStartedFunc = func() { close(started) }
+		go srv.ActivateAndServe()
+		<-started
+		t.Cleanup(func() { srv.Shutdown() })
+	}
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+	return &Auditor{Resolver: pc.LocalAddr().String(), NSPort: port, Timeout: time.Second}
+}
+
+func zone(t *testing.T, lines ...string) map[string][]dns.RR {
+	t.Helper()
+	out := map[string][]dns.RR{}
+	for _, line := range lines {
+		rr, err := dns.NewRR(line)
+		if err != nil {
+			t.Fatalf("%q: %v", line, err)
+		}
+		out[rr.Header().Name] = append(out[rr.Header().Name], rr)
+	}
+	return out
+}
+
+func TestAudit(t *testing.T) {
+	var includes []string
+	for i := 1; i <= 11; i++ {
+		includes = append(includes, "include:i"+string(rune('a'+i))+".closed.test")
+	}
+	a := fakeDNS(t, &fakeZone{
+		records: zone(t,
+			// open.test: transfers allowed (on the second NS address),
+			// SPF +all, DMARC p=none, no CAA, dangling NS and MX.
+			"open.test. 300 IN SOA ns1.open.test. admin.open.test. 1 3600 600 86400 300",
+			"open.test. 300 IN NS ns1.open.test.",
+			"open.test. 300 IN NS ns.gone.test.",
+			"open.test. 300 IN MX 10 mail.gone.test.",
+			`open.test. 300 IN TXT "v=spf1 +all"`,
+			`_dmarc.open.test. 300 IN TXT "v=DMARC1; p=none; rua=mailto:d@open.test"`,
+			"ns1.open.test. 300 IN A 127.0.0.2",
+			"ns1.open.test. 300 IN A 127.0.0.1",
+			"www.open.test. 300 IN A 192.0.2.10",
+
+			// closed.test: transfers refused, SPF over the lookup limit,
+			// DMARC p=reject, CAA set.
+			"closed.test. 300 IN SOA ns1.closed.test. admin.closed.test. 1 3600 600 86400 300",
+			"closed.test. 300 IN NS ns1.closed.test.",
+			`closed.test. 300 IN TXT "v=spf1 `+strings.Join(includes, " ")+` -all"`,
+			`closed.test. 300 IN CAA 0 issue "letsencrypt.org"`,
+			`_dmarc.closed.test. 300 IN TXT "v=DMARC1; p=reject; rua=mailto:d@closed.test"`,
+			"ns1.closed.test. 300 IN A 127.0.0.1",
+		),
+		axfr: map[string]bool{"open.test.": true},
+	})
+
+	tests := []struct {
+		domain string
+		want   []string // check/severity pairs that must be reported
+		absent []string // check/severity pairs that must not
+	}{
+		{
+			domain: "open.test",
+			want:   []string{"axfr/high", "spf/high", "dmarc/low", "caa/low", "ns/high", "mx/medium"},
+		},
+		{
+			domain: "closed.test",
+			want:   []string{"spf/medium", "caa/info"},
+			absent: []string{"axfr/high", "spf/high", "dmarc/low", "caa/low", "ns/high"},
+		},
+	}
+	for _, tt := range tests {
+		got := map[string]string{}
+		for _, r := range a.Audit(tt.domain) {
+			got[r.Check+"/"+r.Severity] = r.Detail + " " + r.Record
+		}
+		for _, w := range tt.want {
+			if _, ok := got[w]; !ok {
+				t.Errorf("%s: missing %s, got %v", tt.domain, w, got)
+			}
+		}
+		for _, w := range tt.absent {
+			if d, ok := got[w]; ok {
+				t.Errorf("%s: unexpected %s (%s)", tt.domain, w, d)
+			}
+		}
+	}
+
+	if d := a.Audit("open.test"); !strings.Contains(findCheck(d, "axfr"), "127.0.0.1") {
+		t.Errorf("axfr: want the transfer on the second address, got %q", findCheck(d, "axfr"))
+	}
+}
+
+func findCheck(results []auditResult, check string) string {
+	for _, r := range results {
+		if r.Check == check {
+			return r.Detail
+		}
+	}
+	return ""
+}
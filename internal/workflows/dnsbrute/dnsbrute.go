@@ -0,0 +1,114 @@
+package dnsbrute
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/FOUEN/narmol/internal/brute"
+	"github.com/FOUEN/narmol/internal/scope"
+	"github.com/FOUEN/narmol/internal/workflows"
+)
+
+func init() {
+	workflows.Register(&DNSBruteWorkflow{})
+}
+
+// DNSBruteWorkflow actively brute-forces subdomains with dnsx.
+// Pipeline: wildcard roots from scope → wildcard detection → wordlist
+// resolution (rate limited) → wildcard + scope filter.
+type DNSBruteWorkflow struct{}
+
+func (w *DNSBruteWorkflow) Name() string { return "dnsbrute" }
+
+func (w *DNSBruteWorkflow) Description() string {
+	return "Active DNS brute-force (dnsx + wordlist) with wildcard detection. No probing."
+}
+
+// bruteResult is the JSON output format (same shape as the subdomains workflow).
+type bruteResult struct {
+	Subdomain string   `json:"subdomain"`
+	IPs       []string `json:"ips,omitempty"`
+	Source    string   `json:"source"`
+}
+
+func (r bruteResult) summary() string {
+	return fmt.Sprintf("%s → %s", r.Subdomain, strings.Join(r.IPs, ", "))
+}
+
+func (w *DNSBruteWorkflow) Run(domain string, s *scope.Scope, opts workflows.OutputOptions) error {
+	if !s.IsInScope(domain) {
+		return fmt.Errorf("domain %s is not in scope", domain)
+	}
+	roots := s.WildcardRoots(domain)
+	if len(roots) == 0 {
+		return fmt.Errorf("dnsbrute workflow requires wildcard scope (*.%s)", domain)
+	}
+
+	// ── Output files ──────────────────────────────────────────────────
+	var textFile, jsonFile *os.File
+	var err error
+	if opts.TextFile != "" {
+		textFile, err = os.OpenFile(opts.TextFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open text output: %w", err)
+		}
+		defer textFile.Close()
+	}
+	if opts.JSONFile != "" {
+		jsonFile, err = os.OpenFile(opts.JSONFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open JSON output: %w", err)
+		}
+		defer jsonFile.Close()
+	}
+
+	var mu sync.Mutex
+	emit := func(r bruteResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		if textFile == nil && jsonFile == nil {
+			fmt.Println(r.summary())
+		}
+		if textFile != nil {
+			fmt.Fprintln(textFile, r.summary())
+		}
+		if jsonFile != nil {
+			if js, jErr := json.Marshal(r); jErr == nil {
+				fmt.Fprintln(jsonFile, string(js))
+			}
+		}
+	}
+
+	// ── Brute-force ───────────────────────────────────────────────────
+	fmt.Printf("[*] Brute-forcing %d wildcard roots: %s\n", len(roots), strings.Join(roots, ", "))
+
+	stats, err := brute.Run(roots, s, bruteOptions(opts), func(r brute.Result) {
+		emit(bruteResult{Subdomain: r.Subdomain, IPs: r.IPs, Source: "dnsbrute"})
+	})
+	if err != nil {
+		return err
+	}
+
+	// ── Summary ───────────────────────────────────────────────────────
+	fmt.Printf("[+] Resolved %d candidates — %d found, %d wildcard answers dropped, %d out of scope\n",
+		stats.Candidates, stats.Found, stats.Wildcard, stats.OutOfScope)
+	if opts.TextFile != "" {
+		fmt.Printf("[+] Text results saved to: %s\n", opts.TextFile)
+	}
+	if opts.JSONFile != "" {
+		fmt.Printf("[+] JSON results saved to: %s\n", opts.JSONFile)
+	}
+	fmt.Printf("[+] Workflow 'dnsbrute' completed — %d subdomains\n", stats.Found)
+	return nil
+}
+
+func bruteOptions(opts workflows.OutputOptions) brute.Options {
+	return brute.Options{
+		Wordlist:  opts.Wordlist,
+		Resolvers: opts.Resolvers,
+		RateLimit: opts.RateLimit,
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"net/http/httputil"
 	"net/url"
 	"os"
 	"sort"
@@ -18,16 +19,23 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/FOUEN/narmol/internal/apispec"
+	"github.com/FOUEN/narmol/internal/auth"
+	"github.com/FOUEN/narmol/internal/brute"
+	"github.com/FOUEN/narmol/internal/evidence"
+	"github.com/FOUEN/narmol/internal/gauconf"
+	"github.com/FOUEN/narmol/internal/graphql"
+	"github.com/FOUEN/narmol/internal/params"
 	"github.com/FOUEN/narmol/internal/scope"
+	"github.com/FOUEN/narmol/internal/service"
+	"github.com/FOUEN/narmol/internal/waf"
 	"github.com/FOUEN/narmol/internal/workflows"
 	"github.com/FOUEN/narmol/internal/workflows/secrets"
 
-	mapset "github.com/deckarep/golang-set/v2"
-	gau_providers "github.com/lc/gau/v2/pkg/providers"
 	gau_runner "github.com/lc/gau/v2/runner"
-	"github.com/valyala/fasthttp"
 
 	"github.com/projectdiscovery/goflags"
+	"github.com/projectdiscovery/httpx/common/customheader"
 	httpx_runner "github.com/projectdiscovery/httpx/runner"
 	katana_output "github.com/projectdiscovery/katana/pkg/output"
 	katana_standard "github.com/projectdiscovery/katana/pkg/engine/standard"
@@ -46,11 +54,13 @@ func init() {
 }
 
 // FullWorkflow runs the complete scan pipeline:
-//  1. Recon        — subfinder (recursive) + gau (passive, no target contact)
+//  1. Recon        — subfinder (recursive) + gau (passive) + resolved permutations
 //  2. Probe        — httpx alive check + tech fingerprinting
 //  3. Crawl        — katana endpoint discovery on live hosts
-//  4. Port scan    — naabu on live hosts + IPs/CIDRs from scope
-//  5. Vuln assess  — nuclei + headers + TLS + redirects + smuggling + git secrets (parallel)
+//  4. Port scan    — naabu on live hosts + IPs/CIDRs from scope, service
+//     detection on open ports; HTTP(S) services go back through httpx
+//  5. Vuln assess  — nuclei + headers + TLS + redirects + smuggling + git secrets (parallel),
+//     nuclei network templates on the other services
 //  6. Report       — unified structured output by phases
 type FullWorkflow struct{}
 
@@ -70,6 +80,24 @@ func (w *FullWorkflow) Run(domain string, s *scope.Scope, opts workflows.OutputO
 		Date:   time.Now().UTC().Format(time.RFC3339),
 	}
 
+	store, err := evidence.NewStore(opts.EvidenceDir, opts.EvidenceMaxBytes)
+	if err != nil {
+		return err
+	}
+
+	sess, err := auth.New(opts.Auth, domain, s)
+	if err != nil {
+		return err
+	}
+	if sess != nil {
+		fmt.Printf("[+] Authenticated session: %s\n", sess)
+		evidence.RedactHeaders(sess.HeaderNames()...)
+	}
+
+	// URLs from gau and katana feed the parameter inventory used by the
+	// open redirect checks.
+	inv := params.NewInventory()
+
 	seen := &sync.Map{}
 	collect := func(r finding) bool {
 		key := r.Phase + ":" + r.Value
@@ -79,6 +107,10 @@ func (w *FullWorkflow) Run(domain string, s *scope.Scope, opts workflows.OutputO
 		if _, loaded := seen.LoadOrStore(key, true); loaded {
 			return false
 		}
+		if r.Phase == "url" {
+			inv.AddURL(r.Value, r.Detail)
+		}
+		r.Evidence = store.Save(r.Phase, r.Value, r.Detail+r.TemplateID, r.ev)
 		report.add(r)
 		return true
 	}
@@ -93,6 +125,7 @@ func (w *FullWorkflow) Run(domain string, s *scope.Scope, opts workflows.OutputO
 		subdomains = w.runSubfinder(domain, s, collect)
 		if len(subdomains) > 0 {
 			w.runSubfinderRecursive(subdomains, s, collect)
+			subdomains = append(subdomains, w.runPermutations(domain, subdomains, s, opts, collect)...)
 		}
 	} else {
 		collect(finding{Phase: "recon", Value: domain, Detail: "scope target"})
@@ -106,7 +139,7 @@ func (w *FullWorkflow) Run(domain string, s *scope.Scope, opts workflows.OutputO
 	gauWg.Add(1)
 	go func() {
 		defer gauWg.Done()
-		w.runGau(domain, s, collect)
+		w.runGau(domain, s, opts.Gau, collect)
 	}()
 
 	fmt.Printf("[+] %d subdomains discovered\n", len(subdomains))
@@ -116,12 +149,16 @@ func (w *FullWorkflow) Run(domain string, s *scope.Scope, opts workflows.OutputO
 	// ═══════════════════════════════════════════════════════════════════
 	fmt.Println("\n[*] ═══ Phase 2: PROBE (alive + fingerprint) ═══")
 
-	liveHosts, techSet := w.runHttpx(subdomains, s, collect)
+	liveHosts, techSet := w.runHttpx(subdomains, s, sess, collect)
 	if len(liveHosts) == 0 {
 		fmt.Println("[!] No live hosts found")
 	} else {
 		fmt.Printf("[+] %d live hosts, %d technologies detected\n", len(liveHosts), len(techSet))
 	}
+	wafs := w.runWAFDetection(liveHosts, collect)
+	if len(wafs) > 0 && !opts.WAFBackoff {
+		fmt.Println("[!] Hosts behind a WAF are scanned at full rate — use --waf-backoff to slow down")
+	}
 
 	// Wait for gau to finish
 	gauWg.Wait()
@@ -138,11 +175,24 @@ func (w *FullWorkflow) Run(domain string, s *scope.Scope, opts workflows.OutputO
 		phase3Wg.Add(1)
 		go func() {
 			defer phase3Wg.Done()
-			w.runKatana(liveHosts, s, collect)
+			w.runKatana(liveHosts, s, sess, collect)
 		}()
 	}
 
-	// 3b. Naabu port scan on all targets (hosts + IPs)
+	// 3a'. API specs (OpenAPI/Swagger/Postman) — endpoints as URLs,
+	// unauthenticated ones go to nuclei
+	var apiTargets []string
+	if len(liveHosts) > 0 {
+		phase3Wg.Add(1)
+		go func() {
+			defer phase3Wg.Done()
+			apiTargets = w.runAPISpecDiscovery(liveHosts, s, inv, sess, collect)
+		}()
+	}
+
+	// 3b. Naabu port scan on all targets (hosts + IPs), then service
+	// detection on the open ports
+	var services []service.Service
 	portTargets := make([]string, len(subdomains))
 	copy(portTargets, subdomains)
 	if s.HasIPs() {
@@ -152,12 +202,33 @@ func (w *FullWorkflow) Run(domain string, s *scope.Scope, opts workflows.OutputO
 		phase3Wg.Add(1)
 		go func() {
 			defer phase3Wg.Done()
-			w.runNaabu(portTargets, collect)
+			services = w.runServiceDetection(w.runNaabu(portTargets), collect)
 		}()
 	}
 
 	phase3Wg.Wait()
 
+	// 3c. HTTP(S) services on ports httpx has not seen go back through
+	// httpx → techdetect (and WAF detection); the rest go to nuclei network
+	// templates in phase 4.
+	webURLs, netTargets, netTags := routeServices(services, liveHosts)
+	if len(webURLs) > 0 {
+		fmt.Printf("[*] %d HTTP(S) services on other ports\n", len(webURLs))
+		moreHosts, moreTech := w.runHttpx(webURLs, s, sess, collect)
+		liveHosts = append(liveHosts, moreHosts...)
+		for tech := range moreTech {
+			techSet[tech] = struct{}{}
+		}
+		if moreWAFs := w.runWAFDetection(moreHosts, collect); len(moreWAFs) > 0 {
+			if wafs == nil {
+				wafs = map[string]waf.Result{}
+			}
+			for h, r := range moreWAFs {
+				wafs[h] = r
+			}
+		}
+	}
+
 	// ═══════════════════════════════════════════════════════════════════
 	// Phase 4: VULNERABILITY ASSESSMENT (all parallel)
 	// ═══════════════════════════════════════════════════════════════════
@@ -166,25 +237,42 @@ func (w *FullWorkflow) Run(domain string, s *scope.Scope, opts workflows.OutputO
 
 		tags := buildNucleiTags(techSet)
 		fmt.Printf("[+] Nuclei tags from fingerprint: %s\n", strings.Join(tags, ", "))
+		nucleiTargets := append(append([]string{}, liveHosts...), apiTargets...)
 
 		var vulnWg sync.WaitGroup
 
 		vulnWg.Add(1)
 		go func() {
 			defer vulnWg.Done()
-			w.runNuclei(liveHosts, tags, collect)
+			// Session hosts are scanned apart, with the session headers.
+			authed, other := sess.Split(nucleiTargets)
+			for _, group := range []struct {
+				targets []string
+				headers []string
+			}{{other, nil}, {authed, sess.HeaderLines()}} {
+				if !opts.WAFBackoff || len(wafs) == 0 {
+					w.runNuclei(group.targets, tags, false, group.headers, collect)
+					continue
+				}
+				protected, open := waf.Split(group.targets, wafs)
+				w.runNuclei(open, tags, false, group.headers, collect)
+				w.runNuclei(protected, tags, true, group.headers, collect)
+			}
+			// One nuclei engine at a time: network templates run after the
+			// web scan.
+			w.runNetworkNuclei(netTargets, netTags, collect)
 		}()
 
 		vulnWg.Add(1)
 		go func() {
 			defer vulnWg.Done()
-			w.runGitExposureCheck(liveHosts, collect)
+			w.runGitExposureCheck(liveHosts, sess, collect)
 		}()
 
 		vulnWg.Add(1)
 		go func() {
 			defer vulnWg.Done()
-			w.runSecurityHeaderChecks(liveHosts, collect)
+			w.runSecurityHeaderChecks(liveHosts, sess, collect)
 		}()
 
 		vulnWg.Add(1)
@@ -196,7 +284,7 @@ func (w *FullWorkflow) Run(domain string, s *scope.Scope, opts workflows.OutputO
 		vulnWg.Add(1)
 		go func() {
 			defer vulnWg.Done()
-			w.runOpenRedirectChecks(liveHosts, collect)
+			w.runOpenRedirectChecks(liveHosts, inv, sess, collect)
 		}()
 
 		vulnWg.Add(1)
@@ -205,7 +293,16 @@ func (w *FullWorkflow) Run(domain string, s *scope.Scope, opts workflows.OutputO
 			w.runSmugglingChecks(liveHosts, collect)
 		}()
 
+		vulnWg.Add(1)
+		go func() {
+			defer vulnWg.Done()
+			w.runGraphQLChecks(liveHosts, opts.ArtifactDir, sess, collect)
+		}()
+
 		vulnWg.Wait()
+	} else if len(netTargets) > 0 {
+		fmt.Println("\n[*] ═══ Phase 4: VULNERABILITY ASSESSMENT ═══")
+		w.runNetworkNuclei(netTargets, netTags, collect)
 	}
 
 	// ═══════════════════════════════════════════════════════════════════
@@ -308,28 +405,42 @@ func (w *FullWorkflow) runSubfinderRecursive(seeds []string, s *scope.Scope, col
 	fmt.Printf("[+] Recursive subfinder: %d new subdomains\n", newFound)
 }
 
-// ─── Gau ────────────────────────────────────────────────────────────────
+// ─── Permutations ───────────────────────────────────────────────────────
 
-func (w *FullWorkflow) runGau(domain string, s *scope.Scope, collect func(finding) bool) {
-	fmt.Printf("[*] Running gau on %s...\n", domain)
-	var urlCount int64
+// runPermutations resolves alterations of the discovered subdomains and
+// returns the ones that exist, so they are probed like any other host.
+func (w *FullWorkflow) runPermutations(domain string, subs []string, s *scope.Scope, opts workflows.OutputOptions, collect func(finding) bool) []string {
+	candidates := brute.Permutations(subs, s.WildcardRoots(domain), s, opts.PermuteMax)
+	fmt.Printf("[*] Resolving %d subdomain permutations...\n", len(candidates))
 
-	config := &gau_providers.Config{
-		Threads:           5,
-		Timeout:           45,
-		MaxRetries:        3,
-		IncludeSubdomains: true,
-		RemoveParameters:  false,
-		Client: &fasthttp.Client{
-			TLSConfig: &tls.Config{InsecureSkipVerify: true},
-		},
-		Blacklist: mapset.NewThreadUnsafeSet(""),
+	var mu sync.Mutex
+	var found []string
+	stats, err := brute.Resolve(candidates, brute.Options{
+		Resolvers: opts.Resolvers,
+		RateLimit: opts.RateLimit,
+	}, func(r brute.Result) {
+		if collect(finding{Phase: "recon", Value: r.Subdomain, Detail: "permutation"}) {
+			mu.Lock()
+			found = append(found, r.Subdomain)
+			mu.Unlock()
+		}
+	})
+	if err != nil {
+		fmt.Printf("[!] Permutation resolution failed: %s\n", err)
+		return nil
 	}
+	fmt.Printf("[+] Permutations: %d resolved (%d wildcard answers dropped)\n", stats.Found, stats.Wildcard)
+	return found
+}
 
-	providerNames := []string{"wayback", "otx", "urlscan"}
+// ─── Gau ────────────────────────────────────────────────────────────────
+
+func (w *FullWorkflow) runGau(domain string, s *scope.Scope, opts gauconf.Options, collect func(finding) bool) {
+	fmt.Printf("[*] Running gau on %s (%s)...\n", domain, opts.Describe())
+	var urlCount int64
 
 	gau := &gau_runner.Runner{}
-	if err := gau.Init(config, providerNames, gau_providers.Filters{}); err != nil {
+	if err := gau.Init(opts.Config(), opts.ProviderNames(), opts.Filters()); err != nil {
 		fmt.Printf("[!] Could not initialize gau: %s\n", err)
 		return
 	}
@@ -354,7 +465,7 @@ func (w *FullWorkflow) runGau(domain string, s *scope.Scope, collect func(findin
 		defer wg.Done()
 		for u := range results {
 			u = strings.TrimSpace(u)
-			if u == "" || !s.IsInScope(u) {
+			if u == "" || !s.IsInScope(u) || !opts.Keep(u) {
 				continue
 			}
 			if collect(finding{Phase: "url", Value: u, Detail: "gau"}) {
@@ -372,12 +483,32 @@ func (w *FullWorkflow) runGau(domain string, s *scope.Scope, collect func(findin
 
 // ─── httpx ──────────────────────────────────────────────────────────────
 
-func (w *FullWorkflow) runHttpx(hosts []string, s *scope.Scope, collect func(finding) bool) ([]string, map[string]struct{}) {
+func (w *FullWorkflow) runHttpx(hosts []string, s *scope.Scope, sess *auth.Session, collect func(finding) bool) ([]string, map[string]struct{}) {
+	// Session hosts are probed apart so only they get the session headers.
+	authed, other := sess.Split(hosts)
+	liveHosts, techSet := w.probeHttpx(other, s, nil, collect)
+	if len(authed) > 0 {
+		fmt.Printf("[*] Probing %d session hosts with credentials...\n", len(authed))
+		more, moreTech := w.probeHttpx(authed, s, sess.HeaderLines(), collect)
+		liveHosts = append(liveHosts, more...)
+		for tech := range moreTech {
+			techSet[tech] = struct{}{}
+		}
+	}
+	return liveHosts, techSet
+}
+
+// probeHttpx runs httpx on hosts with optional custom headers. With
+// headers, redirects are only followed on the same host.
+func (w *FullWorkflow) probeHttpx(hosts []string, s *scope.Scope, headers []string, collect func(finding) bool) ([]string, map[string]struct{}) {
+	techSet := make(map[string]struct{})
+	if len(hosts) == 0 {
+		return nil, techSet
+	}
 	fmt.Printf("[*] Probing %d hosts with httpx...\n", len(hosts))
 
 	var mu sync.Mutex
 	var liveHosts []string
-	techSet := make(map[string]struct{})
 
 	hxOptions := httpx_runner.Options{
 		Methods:         "GET",
@@ -394,6 +525,7 @@ func (w *FullWorkflow) runHttpx(hosts []string, s *scope.Scope, collect func(fin
 		OutputCDN:       "true",
 		ExtractTitle:    true,
 		DisableUpdateCheck: true,
+		CustomHeaders:   customheader.CustomHeaders(headers),
 		OnResult: func(result httpx_runner.Result) {
 			if result.Err != nil {
 				return
@@ -421,10 +553,16 @@ func (w *FullWorkflow) runHttpx(hosts []string, s *scope.Scope, collect func(fin
 				Webserver:  result.WebServer,
 				CDN:        result.CDN,
 				CDNName:    result.CDNName,
+				IP:         result.HostIP,
 			})
 		},
 	}
 
+	if len(headers) > 0 {
+		hxOptions.FollowRedirects = false
+		hxOptions.FollowHostRedirects = true
+	}
+
 	hxRunner, err := httpx_runner.New(&hxOptions)
 	if err != nil {
 		fmt.Printf("[!] Could not create httpx runner: %s\n", err)
@@ -439,28 +577,49 @@ func (w *FullWorkflow) runHttpx(hosts []string, s *scope.Scope, collect func(fin
 
 // ─── Katana ─────────────────────────────────────────────────────────────
 
-func (w *FullWorkflow) runKatana(liveHosts []string, s *scope.Scope, collect func(finding) bool) {
+func (w *FullWorkflow) runKatana(liveHosts []string, s *scope.Scope, sess *auth.Session, collect func(finding) bool) {
 	fmt.Printf("[*] Crawling %d hosts with katana...\n", len(liveHosts))
 	var count int64
 
+	// Session hosts are crawled apart, with the session headers and the
+	// crawl kept on the session hosts.
+	authed, other := sess.Split(liveHosts)
+	w.crawl(other, s, nil, nil, &count, collect)
+	w.crawl(authed, s, sess.HeaderLines(), sess.ScopeRegex(), &count, collect)
+
+	fmt.Printf("[+] Katana: %d URLs crawled\n", count)
+}
+
+// crawl runs one katana crawler over hosts, sending headers on every
+// request and, with urlScope, following only URLs matching it. With headers
+// redirects are not followed: katana follows them to any host, headers
+// included.
+func (w *FullWorkflow) crawl(hosts []string, s *scope.Scope, headers, urlScope []string, count *int64, collect func(finding) bool) {
+	if len(hosts) == 0 {
+		return
+	}
 	katanaOpts := &katana_types.Options{
-		MaxDepth:    3,
-		FieldScope:  "rdn",
-		Concurrency: 10,
-		Parallelism: 10,
-		Timeout:     10,
-		RateLimit:   100,
-		Strategy:    "breadth-first",
-		KnownFiles:  "all",
-		NoColors:    true,
-		Silent:      true,
+		MaxDepth:         3,
+		BodyReadSize:     4 << 20, // katana reads no body when 0
+		FieldScope:       "rdn",
+		Concurrency:      10,
+		Parallelism:      10,
+		Timeout:          10,
+		RateLimit:        100,
+		Strategy:         "breadth-first",
+		KnownFiles:       "all",
+		NoColors:         true,
+		Silent:           true,
+		CustomHeaders:    goflags.StringSlice(headers),
+		Scope:            goflags.StringSlice(urlScope),
+		DisableRedirects: len(headers) > 0,
 		OnResult: func(result katana_output.Result) {
 			u := result.Request.URL
 			if u == "" || !s.IsInScope(u) {
 				return
 			}
 			if collect(finding{Phase: "url", Value: u, Detail: "katana"}) {
-				atomic.AddInt64(&count, 1)
+				atomic.AddInt64(count, 1)
 			}
 		},
 	}
@@ -479,21 +638,110 @@ func (w *FullWorkflow) runKatana(liveHosts []string, s *scope.Scope, collect fun
 	}
 	defer crawler.Close()
 
-	if err := crawler.Crawl(liveHosts[0]); err != nil {
+	if err := crawler.Crawl(hosts[0]); err != nil {
 		fmt.Printf("[!] Katana crawl error: %s\n", err)
 	}
-	for _, h := range liveHosts[1:] {
+	for _, h := range hosts[1:] {
 		_ = crawler.Crawl(h)
 	}
+}
 
-	fmt.Printf("[+] Katana: %d URLs crawled\n", count)
+// ─── API specs ──────────────────────────────────────────────────────────
+
+// maxAPITargets caps the unauthenticated API endpoints added to nuclei.
+const maxAPITargets = 200
+
+// runAPISpecDiscovery fetches OpenAPI/Swagger/Postman documents from each
+// live host (see internal/apispec). Each spec is an exposure; its in-scope
+// endpoints are URL findings and their query parameters feed inv. Returns
+// the unauthenticated endpoint URLs for nuclei.
+func (w *FullWorkflow) runAPISpecDiscovery(liveHosts []string, s *scope.Scope, inv *params.Inventory, sess *auth.Session, collect func(finding) bool) []string {
+	fmt.Printf("[*] Looking for API specs on %d hosts...\n", len(liveHosts))
+
+	client := &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			DialContext:     (&net.Dialer{Timeout: 3 * time.Second}).DialContext,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	client.Transport = sess.Transport(client.Transport)
+
+	var mu sync.Mutex
+	var targets []string
+	var specs, endpoints int64
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, 20)
+
+	for _, host := range liveHosts {
+		wg.Add(1)
+		go func(h string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			for _, sp := range apispec.Discover(client, h) {
+				atomic.AddInt64(&specs, 1)
+				open := 0
+				for _, e := range sp.Endpoints {
+					u, err := url.Parse(e.URL)
+					if err != nil || !s.IsInScope(u.Hostname()) {
+						continue
+					}
+					authSchemes := "none"
+					if !e.Unauthenticated() {
+						authSchemes = strings.Join(e.Auth, ",")
+					}
+					detail := fmt.Sprintf("apispec %s, auth: %s", e.Method, authSchemes)
+					if len(e.Params) > 0 {
+						detail += ", params: " + strings.Join(e.Params, " ")
+					}
+					if collect(finding{Phase: "url", Value: e.URL, Detail: detail}) {
+						atomic.AddInt64(&endpoints, 1)
+					}
+					for _, p := range e.Params {
+						if name, ok := strings.CutPrefix(p, "query:"); ok {
+							inv.Add(e.URL, name, "apispec")
+						}
+					}
+					if e.Unauthenticated() {
+						open++
+						mu.Lock()
+						if len(targets) < maxAPITargets {
+							targets = appendUnique(targets, e.URL)
+						}
+						mu.Unlock()
+					}
+				}
+				collect(finding{
+					Phase: "exposure", Value: sp.URL, Severity: "low",
+					Detail: fmt.Sprintf("Exposed %s spec %q — %d endpoints, %d without auth", sp.Format, sp.Title, len(sp.Endpoints), open),
+				})
+			}
+		}(host)
+	}
+
+	wg.Wait()
+	fmt.Printf("[+] API specs: %d found, %d endpoints, %d unauthenticated queued for nuclei\n", specs, endpoints, len(targets))
+	return targets
 }
 
 // ─── Naabu ──────────────────────────────────────────────────────────────
 
-func (w *FullWorkflow) runNaabu(targets []string, collect func(finding) bool) {
+// openPort is a naabu result waiting for service detection.
+type openPort struct {
+	Host, IP string
+	Port     int
+	Protocol string
+}
+
+func (w *FullWorkflow) runNaabu(targets []string) []openPort {
 	fmt.Printf("[*] Port scanning %d targets with naabu...\n", len(targets))
-	var count int64
+	var mu sync.Mutex
+	var ports []openPort
 
 	options := &naabu_runner.Options{
 		Host:               goflags.StringSlice(targets),
@@ -508,19 +756,10 @@ func (w *FullWorkflow) runNaabu(targets []string, collect func(finding) bool) {
 		NoColor:            true,
 		DisableUpdateCheck: true,
 		OnResult: func(hr *naabu_result.HostResult) {
+			mu.Lock()
+			defer mu.Unlock()
 			for _, p := range hr.Ports {
-				host := hr.Host
-				if hr.IP != "" && hr.IP != hr.Host {
-					host = hr.Host + " (" + hr.IP + ")"
-				}
-				if collect(finding{
-					Phase:  "port",
-					Value:  fmt.Sprintf("%s:%d", hr.Host, p.Port),
-					Host:   host,
-					Detail: fmt.Sprintf("port %d/%s open", p.Port, p.Protocol.String()),
-				}) {
-					atomic.AddInt64(&count, 1)
-				}
+				ports = append(ports, openPort{Host: hr.Host, IP: hr.IP, Port: p.Port, Protocol: p.Protocol.String()})
 			}
 		},
 	}
@@ -528,7 +767,7 @@ func (w *FullWorkflow) runNaabu(targets []string, collect func(finding) bool) {
 	runner, err := naabu_runner.NewRunner(options)
 	if err != nil {
 		fmt.Printf("[!] Could not create naabu runner: %s\n", err)
-		return
+		return nil
 	}
 	defer runner.Close()
 
@@ -539,27 +778,182 @@ func (w *FullWorkflow) runNaabu(targets []string, collect func(finding) bool) {
 		fmt.Printf("[!] Naabu scan error: %s\n", err)
 	}
 
-	fmt.Printf("[+] Naabu: %d open ports found\n", count)
+	fmt.Printf("[+] Naabu: %d open ports found\n", len(ports))
+	return ports
+}
+
+// ─── Service detection ──────────────────────────────────────────────────
+
+// runServiceDetection identifies the service behind each open port (see
+// internal/service) and records the port with it.
+func (w *FullWorkflow) runServiceDetection(ports []openPort, collect func(finding) bool) []service.Service {
+	if len(ports) == 0 {
+		return nil
+	}
+	fmt.Printf("[*] Identifying services on %d open ports...\n", len(ports))
+
+	targets := make([]service.Target, len(ports))
+	for i, p := range ports {
+		targets[i] = service.Target{Host: p.Host, Port: p.Port}
+	}
+	services := service.DetectAll(targets, 20, service.DefaultTimeout)
+
+	var web int
+	for i, svc := range services {
+		p := ports[i]
+		host := p.Host
+		if p.IP != "" && p.IP != p.Host {
+			host = p.Host + " (" + p.IP + ")"
+		}
+		collect(finding{
+			Phase:   "port",
+			Value:   fmt.Sprintf("%s:%d", p.Host, p.Port),
+			Host:    host,
+			Service: svc.Name,
+			Detail:  fmt.Sprintf("port %d/%s open — %s", p.Port, p.Protocol, svc),
+		})
+		if svc.IsHTTP() {
+			web++
+		}
+	}
+	fmt.Printf("[+] Services: %d HTTP(S), %d other\n", web, len(services)-web)
+	return services
+}
+
+// networkTags maps a service to the nuclei tags of its network/javascript
+// templates. Unidentified services get the generic network templates.
+var networkTags = map[string]string{
+	service.SSH:        "ssh",
+	service.FTP:        "ftp",
+	service.SMTP:       "smtp",
+	service.POP3:       "pop3",
+	service.IMAP:       "imap",
+	service.MySQL:      "mysql",
+	service.VNC:        "vnc",
+	service.Redis:      "redis",
+	service.PostgreSQL: "postgres",
+	service.Memcached:  "memcached",
+	service.Unknown:    "network",
+}
+
+// routeServices splits detected services: HTTP(S) ones not already among
+// liveHosts become base URLs for httpx, the others host:port targets and
+// tags for network templates.
+func routeServices(services []service.Service, liveHosts []string) (webURLs, netTargets, netTags []string) {
+	known := map[string]bool{}
+	for _, h := range liveHosts {
+		if u, err := url.Parse(h); err == nil {
+			known[hostPort(u)] = true
+		}
+	}
+	for _, svc := range services {
+		if svc.IsHTTP() {
+			u, _ := url.Parse(svc.URL())
+			if !known[hostPort(u)] {
+				known[hostPort(u)] = true
+				webURLs = append(webURLs, svc.URL())
+			}
+			continue
+		}
+		if tag, ok := networkTags[svc.Name]; ok {
+			netTargets = appendUnique(netTargets, svc.Addr())
+			netTags = appendUnique(netTags, tag)
+		}
+	}
+	return webURLs, netTargets, netTags
+}
+
+// hostPort returns host:port of u with the scheme's default port filled in.
+func hostPort(u *url.URL) string {
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	return strings.ToLower(u.Hostname()) + ":" + port
+}
+
+// ─── WAF ────────────────────────────────────────────────────────────────
+
+// runWAFDetection fingerprints the WAF in front of each live host and
+// records it as a "waf" discovery entry (read by the run report).
+func (w *FullWorkflow) runWAFDetection(liveHosts []string, collect func(finding) bool) map[string]waf.Result {
+	if len(liveHosts) == 0 {
+		return nil
+	}
+	fmt.Printf("[*] Fingerprinting WAFs on %d hosts...\n", len(liveHosts))
+	found := waf.DetectAll(liveHosts, 20)
+	for _, h := range liveHosts {
+		if r, ok := found[h]; ok {
+			collect(finding{Phase: "waf", Value: h, WAF: r.Name, Detail: r.String()})
+		}
+	}
+	fmt.Printf("[+] %d hosts behind a WAF\n", len(found))
+	return found
 }
 
 // ─── Nuclei ─────────────────────────────────────────────────────────────
 
-func (w *FullWorkflow) runNuclei(targets []string, tags []string, collect func(finding) bool) {
-	fmt.Printf("[*] Scanning %d targets with nuclei (%d tech tags)...\n", len(targets), len(tags))
+// runNuclei scans targets with the fingerprint tags. With backoff (hosts
+// behind a WAF) noisy tags are excluded and requests are rate limited.
+func (w *FullWorkflow) runNuclei(targets []string, tags []string, backoff bool, headers []string, collect func(finding) bool) {
+	if len(targets) == 0 {
+		return
+	}
+	if backoff {
+		fmt.Printf("[*] Scanning %d WAF-protected targets with nuclei (%d req/s, without %s)...\n",
+			len(targets), waf.BackoffRateLimit, strings.Join(waf.NoisyTags, ","))
+	} else {
+		fmt.Printf("[*] Scanning %d targets with nuclei (%d tech tags)...\n", len(targets), len(tags))
+	}
+	filters := nuclei.TemplateFilters{
+		Severity: "medium,high,critical",
+		Tags:     tags,
+	}
+	var extra []nuclei.NucleiSDKOptions
+	if backoff {
+		filters.ExcludeTags = waf.NoisyTags
+		extra = append(extra, nuclei.WithGlobalRateLimit(waf.BackoffRateLimit, time.Second))
+	}
+	if len(headers) > 0 {
+		extra = append(extra, nuclei.WithHeaders(headers))
+	}
+	vulnCount := w.execNuclei(targets, filters, extra, collect)
+	fmt.Printf("[+] Nuclei: %d vulnerabilities found\n", vulnCount)
+}
+
+// runNetworkNuclei runs nuclei's network and javascript templates against
+// the non-HTTP services found on open ports (host:port targets), selected
+// by service tags (ssh, redis, mysql, …).
+func (w *FullWorkflow) runNetworkNuclei(targets []string, tags []string, collect func(finding) bool) {
+	if len(targets) == 0 {
+		return
+	}
+	fmt.Printf("[*] Scanning %d non-HTTP services with nuclei network templates (%s)...\n", len(targets), strings.Join(tags, ", "))
+	filters := nuclei.TemplateFilters{
+		Severity:      "medium,high,critical",
+		Tags:          tags,
+		ProtocolTypes: "network,javascript",
+	}
+	vulnCount := w.execNuclei(targets, filters, nil, collect)
+	fmt.Printf("[+] Nuclei (network): %d vulnerabilities found\n", vulnCount)
+}
+
+// execNuclei runs one nuclei engine over targets and collects every match.
+// It returns the number of matches.
+func (w *FullWorkflow) execNuclei(targets []string, filters nuclei.TemplateFilters, extra []nuclei.NucleiSDKOptions, collect func(finding) bool) int64 {
 	var vulnCount int64
 	ctx := context.Background()
 
 	tm := &installer.TemplateManager{}
 	if err := tm.FreshInstallIfNotExists(); err != nil {
 		fmt.Printf("[!] Could not install nuclei templates: %s\n", err)
-		return
+		return 0
 	}
 
-	ne, err := nuclei.NewNucleiEngineCtx(ctx,
-		nuclei.WithTemplateFilters(nuclei.TemplateFilters{
-			Severity: "medium,high,critical",
-			Tags:     tags,
-		}),
+	nucleiOpts := []nuclei.NucleiSDKOptions{
 		nuclei.WithConcurrency(nuclei.Concurrency{
 			TemplateConcurrency:           25,
 			HostConcurrency:               25,
@@ -571,16 +965,18 @@ func (w *FullWorkflow) runNuclei(targets []string, tags []string, collect func(f
 		}),
 		nuclei.WithVerbosity(nuclei.VerbosityOptions{Silent: true}),
 		nuclei.DisableUpdateCheck(),
-	)
+	}
+	nucleiOpts = append(nucleiOpts, extra...)
+	ne, err := nuclei.NewNucleiEngineCtx(ctx, append(nucleiOpts, nuclei.WithTemplateFilters(filters))...)
 	if err != nil {
 		fmt.Printf("[!] Could not create nuclei engine: %s\n", err)
-		return
+		return 0
 	}
 	defer ne.Close()
 
 	if err := ne.LoadAllTemplates(); err != nil {
 		fmt.Printf("[!] Could not load nuclei templates: %s\n", err)
-		return
+		return 0
 	}
 
 	ne.LoadTargets(targets, false)
@@ -595,18 +991,41 @@ func (w *FullWorkflow) runNuclei(targets []string, tags []string, collect func(f
 			VulnName:   event.Info.Name,
 			Severity:   severity,
 			VulnType:   event.Type,
-		})
+			ev:         nucleiEvidence(event),
+		}.withClassification(event))
 		atomic.AddInt64(&vulnCount, 1)
 	}); err != nil {
 		fmt.Printf("[!] Nuclei scan error: %s\n", err)
 	}
+	return vulnCount
+}
 
-	fmt.Printf("[+] Nuclei: %d vulnerabilities found\n", vulnCount)
+// nucleiEvidence returns the raw request/response nuclei recorded for a match.
+func nucleiEvidence(event *nuclei_output.ResultEvent) *evidence.Record {
+	if event.Request == "" && event.Response == "" {
+		return nil
+	}
+	return evidence.Raw(event.Request, event.Response)
+}
+
+// withClassification copies the CVE/CVSS/EPSS data of the matched template,
+// used by the run report to score risk.
+func (f finding) withClassification(event *nuclei_output.ResultEvent) finding {
+	c := event.Info.Classification
+	if c == nil {
+		return f
+	}
+	if ids := c.CVEID.ToSlice(); len(ids) > 0 {
+		f.CVE = strings.Join(ids, ",")
+	}
+	f.CVSS = c.CVSSScore
+	f.EPSS = c.EPSSScore
+	return f
 }
 
 // ─── Git Exposure + TruffleHog ──────────────────────────────────────────
 
-func (w *FullWorkflow) runGitExposureCheck(liveHosts []string, collect func(finding) bool) {
+func (w *FullWorkflow) runGitExposureCheck(liveHosts []string, sess *auth.Session, collect func(finding) bool) {
 	fmt.Printf("[*] Checking %d hosts for .git exposure...\n", len(liveHosts))
 
 	client := &http.Client{
@@ -621,6 +1040,7 @@ func (w *FullWorkflow) runGitExposureCheck(liveHosts []string, collect func(find
 			return http.ErrUseLastResponse
 		},
 	}
+	client.Transport = sess.Transport(client.Transport)
 
 	var count int64
 	var wg sync.WaitGroup
@@ -650,6 +1070,7 @@ func (w *FullWorkflow) runGitExposureCheck(liveHosts []string, collect func(find
 					Value:    h,
 					Severity: "high",
 					Detail:   ".git repository exposed — scanning for secrets",
+					ev:       evidence.HTTP(resp.Request, resp, buf[:n]),
 				})
 
 				results, err := secrets.ScanGitRepo(h)
@@ -687,7 +1108,7 @@ var requiredHeaders = []struct {
 	{"Permissions-Policy", "low"},
 }
 
-func (w *FullWorkflow) runSecurityHeaderChecks(liveHosts []string, collect func(finding) bool) {
+func (w *FullWorkflow) runSecurityHeaderChecks(liveHosts []string, sess *auth.Session, collect func(finding) bool) {
 	fmt.Printf("[*] Checking security headers on %d hosts...\n", len(liveHosts))
 
 	client := &http.Client{
@@ -699,6 +1120,7 @@ func (w *FullWorkflow) runSecurityHeaderChecks(liveHosts []string, collect func(
 			DialContext:         (&net.Dialer{Timeout: 3 * time.Second}).DialContext,
 		},
 	}
+	client.Transport = sess.Transport(client.Transport)
 
 	var count int64
 	var wg sync.WaitGroup
@@ -723,10 +1145,11 @@ func (w *FullWorkflow) runSecurityHeaderChecks(liveHosts []string, collect func(
 				return
 			}
 			defer resp.Body.Close()
+			ev := evidence.HTTP(req, resp, nil)
 
 			for _, hdr := range requiredHeaders {
 				if resp.Header.Get(hdr.Name) == "" {
-					if collect(finding{Phase: "header", Value: h, Severity: hdr.Severity, Detail: "Missing " + hdr.Name}) {
+					if collect(finding{Phase: "header", Value: h, Severity: hdr.Severity, Detail: "Missing " + hdr.Name, ev: ev}) {
 						atomic.AddInt64(&count, 1)
 					}
 				}
@@ -734,14 +1157,14 @@ func (w *FullWorkflow) runSecurityHeaderChecks(liveHosts []string, collect func(
 
 			acao := resp.Header.Get("Access-Control-Allow-Origin")
 			if acao == "*" || acao == "https://evil.com" {
-				if collect(finding{Phase: "header", Value: h, Severity: "high", Detail: fmt.Sprintf("CORS misconfiguration: Access-Control-Allow-Origin: %s", acao)}) {
+				if collect(finding{Phase: "header", Value: h, Severity: "high", Detail: fmt.Sprintf("CORS misconfiguration: Access-Control-Allow-Origin: %s", acao), ev: ev}) {
 					atomic.AddInt64(&count, 1)
 				}
 			}
 
 			acac := resp.Header.Get("Access-Control-Allow-Credentials")
 			if acac == "true" && (acao == "*" || acao == "https://evil.com") {
-				if collect(finding{Phase: "header", Value: h, Severity: "critical", Detail: "CORS with credentials: origin reflected + Allow-Credentials: true"}) {
+				if collect(finding{Phase: "header", Value: h, Severity: "critical", Detail: "CORS with credentials: origin reflected + Allow-Credentials: true", ev: ev}) {
 					atomic.AddInt64(&count, 1)
 				}
 			}
@@ -758,7 +1181,7 @@ func (w *FullWorkflow) runSecurityHeaderChecks(liveHosts []string, collect func(
 					issues = append(issues, "missing/weak SameSite")
 				}
 				if len(issues) > 0 {
-					if collect(finding{Phase: "header", Value: h, Severity: "low", Detail: fmt.Sprintf("Cookie '%s': %s", cookie.Name, strings.Join(issues, ", "))}) {
+					if collect(finding{Phase: "header", Value: h, Severity: "low", Detail: fmt.Sprintf("Cookie '%s': %s", cookie.Name, strings.Join(issues, ", ")), ev: ev}) {
 						atomic.AddInt64(&count, 1)
 					}
 				}
@@ -829,20 +1252,21 @@ func (w *FullWorkflow) runTLSChecks(liveHosts []string, collect func(finding) bo
 			defer conn.Close()
 
 			state := conn.ConnectionState()
+			ev := evidence.TLS(addr, state)
 
 			switch state.Version {
 			case tls.VersionTLS10:
-				if collect(finding{Phase: "tls", Value: h, Severity: "high", Detail: "TLS 1.0 supported (deprecated, vulnerable to BEAST/POODLE)"}) {
+				if collect(finding{Phase: "tls", Value: h, Severity: "high", Detail: "TLS 1.0 supported (deprecated, vulnerable to BEAST/POODLE)", ev: ev}) {
 					atomic.AddInt64(&count, 1)
 				}
 			case tls.VersionTLS11:
-				if collect(finding{Phase: "tls", Value: h, Severity: "medium", Detail: "TLS 1.1 supported (deprecated)"}) {
+				if collect(finding{Phase: "tls", Value: h, Severity: "medium", Detail: "TLS 1.1 supported (deprecated)", ev: ev}) {
 					atomic.AddInt64(&count, 1)
 				}
 			}
 
 			if name, weak := weakCiphers[state.CipherSuite]; weak {
-				if collect(finding{Phase: "tls", Value: h, Severity: "high", Detail: fmt.Sprintf("Weak cipher suite: %s", name)}) {
+				if collect(finding{Phase: "tls", Value: h, Severity: "high", Detail: fmt.Sprintf("Weak cipher suite: %s", name), ev: ev}) {
 					atomic.AddInt64(&count, 1)
 				}
 			}
@@ -852,13 +1276,13 @@ func (w *FullWorkflow) runTLSChecks(liveHosts []string, collect func(finding) bo
 				now := time.Now()
 
 				if now.After(cert.NotAfter) {
-					if collect(finding{Phase: "tls", Value: h, Severity: "high", Detail: fmt.Sprintf("Certificate expired: %s", cert.NotAfter.Format("2006-01-02"))}) {
+					if collect(finding{Phase: "tls", Value: h, Severity: "high", Detail: fmt.Sprintf("Certificate expired: %s", cert.NotAfter.Format("2006-01-02")), ev: ev}) {
 						atomic.AddInt64(&count, 1)
 					}
 				}
 
 				if now.Before(cert.NotAfter) && cert.NotAfter.Before(now.Add(30*24*time.Hour)) {
-					if collect(finding{Phase: "tls", Value: h, Severity: "medium", Detail: fmt.Sprintf("Certificate expiring soon: %s", cert.NotAfter.Format("2006-01-02"))}) {
+					if collect(finding{Phase: "tls", Value: h, Severity: "medium", Detail: fmt.Sprintf("Certificate expiring soon: %s", cert.NotAfter.Format("2006-01-02")), ev: ev}) {
 						atomic.AddInt64(&count, 1)
 					}
 				}
@@ -868,14 +1292,14 @@ func (w *FullWorkflow) runTLSChecks(liveHosts []string, collect func(finding) bo
 					pool.AddCert(cert)
 					_, verifyErr := cert.Verify(x509.VerifyOptions{Roots: pool})
 					if verifyErr == nil {
-						if collect(finding{Phase: "tls", Value: h, Severity: "medium", Detail: "Self-signed certificate"}) {
+						if collect(finding{Phase: "tls", Value: h, Severity: "medium", Detail: "Self-signed certificate", ev: ev}) {
 							atomic.AddInt64(&count, 1)
 						}
 					}
 				}
 
 				if err := cert.VerifyHostname(hostname); err != nil {
-					if collect(finding{Phase: "tls", Value: h, Severity: "high", Detail: fmt.Sprintf("Certificate hostname mismatch: cert for %s", strings.Join(cert.DNSNames, ", "))}) {
+					if collect(finding{Phase: "tls", Value: h, Severity: "high", Detail: fmt.Sprintf("Certificate hostname mismatch: cert for %s", strings.Join(cert.DNSNames, ", ")), ev: ev}) {
 						atomic.AddInt64(&count, 1)
 					}
 				}
@@ -895,7 +1319,12 @@ var openRedirectParams = []string{
 	"continue", "forward", "out", "view", "login_url", "callback",
 }
 
-func (w *FullWorkflow) runOpenRedirectChecks(liveHosts []string, collect func(finding) bool) {
+// maxRedirectProbes caps the parameters tried per host.
+const maxRedirectProbes = 40
+
+// runOpenRedirectChecks tries the common parameters on each host root, then
+// the redirect-like parameters gau and katana saw on that host.
+func (w *FullWorkflow) runOpenRedirectChecks(liveHosts []string, inv *params.Inventory, sess *auth.Session, collect func(finding) bool) {
 	fmt.Printf("[*] Checking %d hosts for open redirects...\n", len(liveHosts))
 
 	client := &http.Client{
@@ -908,6 +1337,7 @@ func (w *FullWorkflow) runOpenRedirectChecks(liveHosts []string, collect func(fi
 			return http.ErrUseLastResponse
 		},
 	}
+	client.Transport = sess.Transport(client.Transport)
 
 	var count int64
 	var wg sync.WaitGroup
@@ -921,9 +1351,8 @@ func (w *FullWorkflow) runOpenRedirectChecks(liveHosts []string, collect func(fi
 			sem <- struct{}{}
 			defer func() { <-sem }()
 
-			for _, param := range openRedirectParams {
-				testURL := fmt.Sprintf("%s/?%s=%s", strings.TrimRight(h, "/"), param, url.QueryEscape(canary))
-				resp, err := client.Get(testURL)
+			for _, t := range inv.Probes(params.ClassRedirect, h, openRedirectParams, maxRedirectProbes) {
+				resp, err := client.Get(t.With(canary))
 				if err != nil {
 					continue
 				}
@@ -932,7 +1361,11 @@ func (w *FullWorkflow) runOpenRedirectChecks(liveHosts []string, collect func(fi
 				if resp.StatusCode >= 300 && resp.StatusCode < 400 {
 					location := resp.Header.Get("Location")
 					if strings.HasPrefix(location, "https://evil.com") || strings.HasPrefix(location, "//evil.com") {
-						if collect(finding{Phase: "redirect", Value: h, Severity: "medium", Detail: fmt.Sprintf("Open redirect via ?%s= → %s (HTTP %d)", param, location, resp.StatusCode)}) {
+						if collect(finding{
+							Phase: "redirect", Value: h, Severity: "medium",
+							Detail: fmt.Sprintf("Open redirect via %s?%s= → %s (HTTP %d)", t.URL, t.Param, location, resp.StatusCode),
+							ev:     evidence.HTTP(resp.Request, resp, []byte{}), // the Location header is the proof
+						}) {
 							atomic.AddInt64(&count, 1)
 						}
 						break
@@ -946,6 +1379,73 @@ func (w *FullWorkflow) runOpenRedirectChecks(liveHosts []string, collect func(fi
 	fmt.Printf("[+] Open redirect checks: %d issues found\n", count)
 }
 
+// ─── GraphQL ────────────────────────────────────────────────────────────
+
+// runGraphQLChecks looks for a GraphQL endpoint on each live host and audits
+// it (see internal/graphql). Introspection schemas are written to schemaDir
+// when set.
+func (w *FullWorkflow) runGraphQLChecks(liveHosts []string, schemaDir string, sess *auth.Session, collect func(finding) bool) {
+	fmt.Printf("[*] Looking for GraphQL endpoints on %d hosts...\n", len(liveHosts))
+
+	client := &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			DialContext:     (&net.Dialer{Timeout: 3 * time.Second}).DialContext,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	client.Transport = sess.Transport(client.Transport)
+
+	var count, endpoints int64
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, 20)
+
+	for _, host := range liveHosts {
+		wg.Add(1)
+		go func(h string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			endpoint := graphql.Discover(client, h)
+			if endpoint == "" {
+				return
+			}
+			atomic.AddInt64(&endpoints, 1)
+			audit := graphql.Run(client, endpoint)
+
+			schema := ""
+			if audit.Schema != nil && schemaDir != "" {
+				path, err := graphql.SaveSchema(schemaDir, endpoint, audit.Schema)
+				if err != nil {
+					fmt.Printf("[!] Could not write GraphQL schema for %s: %s\n", endpoint, err)
+				} else {
+					schema = path
+				}
+			}
+			for _, issue := range audit.Issues {
+				r := finding{
+					Phase: "graphql", Value: endpoint + "#" + issue.Check, Severity: issue.Severity,
+					Detail: issue.Detail,
+					ev:     issue.Evidence,
+				}
+				if issue.Check == graphql.CheckIntrospection {
+					r.Schema = schema
+				}
+				if collect(r) {
+					atomic.AddInt64(&count, 1)
+				}
+			}
+		}(host)
+	}
+
+	wg.Wait()
+	fmt.Printf("[+] GraphQL checks: %d endpoints, %d issues found\n", endpoints, count)
+}
+
 // ─── HTTP Smuggling ─────────────────────────────────────────────────────
 
 func (w *FullWorkflow) runSmugglingChecks(liveHosts []string, collect func(finding) bool) {
@@ -986,14 +1486,14 @@ func (w *FullWorkflow) runSmugglingChecks(liveHosts []string, collect func(findi
 				"POST / HTTP/1.1\r\nHost: %s\r\nContent-Length: 6\r\nTransfer-Encoding: chunked\r\n\r\n0\r\n\r\nX",
 				hostname)
 
-			if w.testSmuggling(addr, isHTTPS, hostname, cltePayload) {
-				if collect(finding{Phase: "smuggling", Value: h, Severity: "critical", Detail: "Potential CL.TE HTTP request smuggling"}) {
+			if ev := w.testSmuggling(addr, isHTTPS, hostname, cltePayload); ev != nil {
+				if collect(finding{Phase: "smuggling", Value: h, Severity: "critical", Detail: "Potential CL.TE HTTP request smuggling", ev: ev}) {
 					atomic.AddInt64(&count, 1)
 				}
 			}
 
-			if w.testSmuggling(addr, isHTTPS, hostname, teclPayload) {
-				if collect(finding{Phase: "smuggling", Value: h, Severity: "critical", Detail: "Potential TE.CL HTTP request smuggling"}) {
+			if ev := w.testSmuggling(addr, isHTTPS, hostname, teclPayload); ev != nil {
+				if collect(finding{Phase: "smuggling", Value: h, Severity: "critical", Detail: "Potential TE.CL HTTP request smuggling", ev: ev}) {
 					atomic.AddInt64(&count, 1)
 				}
 			}
@@ -1004,7 +1504,7 @@ func (w *FullWorkflow) runSmugglingChecks(liveHosts []string, collect func(findi
 	fmt.Printf("[+] HTTP smuggling checks: %d issues found\n", count)
 }
 
-func (w *FullWorkflow) testSmuggling(addr string, isHTTPS bool, hostname, payload string) bool {
+func (w *FullWorkflow) testSmuggling(addr string, isHTTPS bool, hostname, payload string) *evidence.Record {
 	dialer := &net.Dialer{Timeout: 5 * time.Second}
 
 	var conn net.Conn
@@ -1018,30 +1518,32 @@ func (w *FullWorkflow) testSmuggling(addr string, isHTTPS bool, hostname, payloa
 		conn, err = dialer.Dial("tcp", addr)
 	}
 	if err != nil {
-		return false
+		return nil
 	}
 	defer conn.Close()
 
 	conn.SetDeadline(time.Now().Add(10 * time.Second))
 
 	if _, err = conn.Write([]byte(payload)); err != nil {
-		return false
+		return nil
 	}
 
 	reader := bufio.NewReader(conn)
 	resp1, err := http.ReadResponse(reader, nil)
 	if err != nil {
-		return false
+		return nil
 	}
+	dump1, _ := httputil.DumpResponse(resp1, false)
 	resp1.Body.Close()
 
 	conn.SetDeadline(time.Now().Add(3 * time.Second))
 	resp2, err := http.ReadResponse(reader, nil)
 	if err == nil && resp2 != nil {
+		dump2, _ := httputil.DumpResponse(resp2, false)
 		resp2.Body.Close()
-		return true
+		return evidence.Raw(payload, string(dump1)+"\r\n--- second response on the same connection ---\r\n"+string(dump2))
 	}
-	return false
+	return nil
 }
 
 // ─── Result + Report types ──────────────────────────────────────────────
@@ -1056,11 +1558,21 @@ type finding struct {
 	Webserver  string   `json:"webserver,omitempty"`
 	CDN        bool     `json:"cdn,omitempty"`
 	CDNName    string   `json:"cdn_name,omitempty"`
+	WAF        string   `json:"waf,omitempty"` // WAF name (waf phase)
 	TemplateID string   `json:"template_id,omitempty"`
 	VulnName   string   `json:"vuln_name,omitempty"`
 	Severity   string   `json:"severity,omitempty"`
 	VulnType   string   `json:"vuln_type,omitempty"`
 	Detail     string   `json:"detail,omitempty"`
+	IP         string   `json:"ip,omitempty"`       // resolved address (probes)
+	CVE        string   `json:"cve,omitempty"`      // nuclei classification
+	CVSS       float64  `json:"cvss,omitempty"`     // nuclei classification CVSS score
+	EPSS       float64  `json:"epss,omitempty"`     // nuclei classification EPSS score
+	Evidence   string   `json:"evidence,omitempty"` // path to raw request/response proof
+	Schema     string   `json:"schema,omitempty"`   // path to the dumped GraphQL schema
+	Service    string   `json:"service,omitempty"`  // service on an open port (port phase)
+
+	ev *evidence.Record // captured by the check, persisted by the collector
 }
 
 func (f finding) summary() string {
@@ -1077,6 +1589,8 @@ func (f finding) summary() string {
 			title = " - " + f.Title
 		}
 		return fmt.Sprintf("[LIVE] %s (%d)%s%s", f.Value, f.StatusCode, title, tech)
+	case "waf":
+		return fmt.Sprintf("[WAF] %s — %s", f.Value, f.Detail)
 	case "url":
 		return fmt.Sprintf("[URL] %s (%s)", f.Value, f.Detail)
 	case "port":
@@ -1085,6 +1599,8 @@ func (f finding) summary() string {
 		return fmt.Sprintf("[%s] %s — %s (%s)", strings.ToUpper(f.Severity), f.Value, f.VulnName, f.TemplateID)
 	case "secret":
 		return fmt.Sprintf("[SECRET] %s — %s", f.Value, f.Detail)
+	case "exposure":
+		return fmt.Sprintf("[EXPOSURE] %s — %s", f.Value, f.Detail)
 	case "header":
 		return fmt.Sprintf("[HEADER] %s — %s", f.Value, f.Detail)
 	case "tls":
@@ -1093,6 +1609,8 @@ func (f finding) summary() string {
 		return fmt.Sprintf("[REDIRECT] %s — %s", f.Value, f.Detail)
 	case "smuggling":
 		return fmt.Sprintf("[SMUGGLING-%s] %s — %s", strings.ToUpper(f.Severity), f.Value, f.Detail)
+	case "graphql":
+		return fmt.Sprintf("[GRAPHQL-%s] %s — %s", strings.ToUpper(f.Severity), f.Value, f.Detail)
 	default:
 		return f.Value
 	}
@@ -1113,10 +1631,12 @@ type fullReport struct {
 	Ports           []finding
 	Vulns           []finding
 	Secrets         []finding
+	Exposures       []finding
 	Headers         []finding
 	TLS             []finding
 	Redirects       []finding
 	Smuggling       []finding
+	GraphQL         []finding
 }
 
 func (rpt *fullReport) add(f finding) {
@@ -1125,7 +1645,7 @@ func (rpt *fullReport) add(f finding) {
 	switch f.Phase {
 	case "recon":
 		rpt.Recon = append(rpt.Recon, f)
-	case "probe":
+	case "probe", "waf":
 		rpt.Probes = append(rpt.Probes, f)
 	case "url":
 		rpt.URLs = append(rpt.URLs, f)
@@ -1135,6 +1655,8 @@ func (rpt *fullReport) add(f finding) {
 		rpt.Vulns = append(rpt.Vulns, f)
 	case "secret":
 		rpt.Secrets = append(rpt.Secrets, f)
+	case "exposure":
+		rpt.Exposures = append(rpt.Exposures, f)
 	case "header":
 		rpt.Headers = append(rpt.Headers, f)
 	case "tls":
@@ -1143,6 +1665,8 @@ func (rpt *fullReport) add(f finding) {
 		rpt.Redirects = append(rpt.Redirects, f)
 	case "smuggling":
 		rpt.Smuggling = append(rpt.Smuggling, f)
+	case "graphql":
+		rpt.GraphQL = append(rpt.GraphQL, f)
 	}
 }
 
@@ -1186,10 +1710,12 @@ type fullSummary struct {
 	OpenPorts       int `json:"open_ports"`
 	Vulnerabilities int `json:"vulnerabilities"`
 	Secrets         int `json:"secrets"`
+	Exposures       int `json:"exposures"`
 	HeaderIssues    int `json:"header_issues"`
 	TLSIssues       int `json:"tls_issues"`
 	Redirects       int `json:"redirects"`
 	Smuggling       int `json:"smuggling"`
+	GraphQL         int `json:"graphql"`
 }
 
 type fullReportPhases struct {
@@ -1199,10 +1725,12 @@ type fullReportPhases struct {
 	Ports           []finding `json:"ports"`
 	Vulnerabilities []finding `json:"vulnerabilities"`
 	Secrets         []finding `json:"secrets"`
+	Exposures       []finding `json:"exposures"`
 	Headers         []finding `json:"header_issues"`
 	TLS             []finding `json:"tls_issues"`
 	Redirects       []finding `json:"redirects"`
 	Smuggling       []finding `json:"smuggling"`
+	GraphQL         []finding `json:"graphql"`
 }
 
 func (rpt *fullReport) jsonData() fullReportJSON {
@@ -1223,10 +1751,12 @@ func (rpt *fullReport) jsonData() fullReportJSON {
 			OpenPorts:       len(rpt.Ports),
 			Vulnerabilities: len(rpt.Vulns),
 			Secrets:         len(rpt.Secrets),
+			Exposures:       len(rpt.Exposures),
 			HeaderIssues:    len(rpt.Headers),
 			TLSIssues:       len(rpt.TLS),
 			Redirects:       len(rpt.Redirects),
 			Smuggling:       len(rpt.Smuggling),
+			GraphQL:         len(rpt.GraphQL),
 		},
 		Phases: fullReportPhases{
 			Recon:           e(rpt.Recon),
@@ -1235,10 +1765,12 @@ func (rpt *fullReport) jsonData() fullReportJSON {
 			Ports:           e(rpt.Ports),
 			Vulnerabilities: e(rpt.Vulns),
 			Secrets:         e(rpt.Secrets),
+			Exposures:       e(rpt.Exposures),
 			Headers:         e(rpt.Headers),
 			TLS:             e(rpt.TLS),
 			Redirects:       e(rpt.Redirects),
 			Smuggling:       e(rpt.Smuggling),
+			GraphQL:         e(rpt.GraphQL),
 		},
 	}
 }
@@ -1329,12 +1861,15 @@ func (rpt *fullReport) formatText() string {
 	b.WriteString("\n" + line + "\n")
 	b.WriteString("  6. SECRETS & EXPOSURES\n")
 	b.WriteString(line + "\n")
-	if len(rpt.Secrets) == 0 {
+	if len(rpt.Secrets) == 0 && len(rpt.Exposures) == 0 {
 		b.WriteString("  No secrets or exposures found.\n")
 	} else {
 		for _, r := range rpt.Secrets {
 			b.WriteString("  " + r.summary() + "\n")
 		}
+		for _, r := range rpt.Exposures {
+			b.WriteString("  " + r.summary() + "\n")
+		}
 	}
 
 	// 7. Headers
@@ -1385,6 +1920,18 @@ func (rpt *fullReport) formatText() string {
 		}
 	}
 
+	// 11. GraphQL
+	b.WriteString("\n" + line + "\n")
+	b.WriteString("  11. GRAPHQL\n")
+	b.WriteString(line + "\n")
+	if len(rpt.GraphQL) == 0 {
+		b.WriteString("  No GraphQL issues found.\n")
+	} else {
+		for _, r := range rpt.GraphQL {
+			b.WriteString("  " + r.summary() + "\n")
+		}
+	}
+
 	// Summary
 	b.WriteString("\n" + doubleLine + "\n")
 	b.WriteString("  SUMMARY\n")
@@ -1395,10 +1942,12 @@ func (rpt *fullReport) formatText() string {
 	b.WriteString(fmt.Sprintf("  Open Ports:      %d\n", len(rpt.Ports)))
 	b.WriteString(fmt.Sprintf("  Vulnerabilities: %s\n", rpt.vulnBreakdown()))
 	b.WriteString(fmt.Sprintf("  Secrets:         %d\n", len(rpt.Secrets)))
+	b.WriteString(fmt.Sprintf("  Exposures:       %d\n", len(rpt.Exposures)))
 	b.WriteString(fmt.Sprintf("  Header Issues:   %d\n", len(rpt.Headers)))
 	b.WriteString(fmt.Sprintf("  TLS Issues:      %d\n", len(rpt.TLS)))
 	b.WriteString(fmt.Sprintf("  Redirects:       %d\n", len(rpt.Redirects)))
 	b.WriteString(fmt.Sprintf("  Smuggling:       %d\n", len(rpt.Smuggling)))
+	b.WriteString(fmt.Sprintf("  GraphQL:         %d\n", len(rpt.GraphQL)))
 	b.WriteString(doubleLine + "\n")
 
 	return b.String()
@@ -0,0 +1,335 @@
+package fuzz
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/FOUEN/narmol/internal/discover"
+	"github.com/FOUEN/narmol/internal/evidence"
+	"github.com/FOUEN/narmol/internal/fuzz"
+	"github.com/FOUEN/narmol/internal/scope"
+	"github.com/FOUEN/narmol/internal/waf"
+	"github.com/FOUEN/narmol/internal/workflows"
+
+	nuclei "github.com/projectdiscovery/nuclei/v3/lib"
+	"github.com/projectdiscovery/nuclei/v3/pkg/installer"
+	nuclei_output "github.com/projectdiscovery/nuclei/v3/pkg/output"
+)
+
+func init() {
+	workflows.Register(&FuzzWorkflow{})
+}
+
+// FuzzWorkflow discovers unlinked content (directories, backups, config
+// files) on every live host.
+// Pipeline: subfinder (wildcard scope) → httpx → per-host fuzzing with
+// soft-404 calibration, filters and rate limiting → classified findings →
+// nuclei on the confirmed paths.
+type FuzzWorkflow struct{}
+
+func (w *FuzzWorkflow) Name() string { return "fuzz" }
+
+func (w *FuzzWorkflow) Description() string {
+	return "Content discovery: in-process directory/file fuzzing with soft-404 calibration on live hosts."
+}
+
+// defaultConcurrency is the number of hosts fuzzed in parallel; each host
+// has its own rate limit and worker pool.
+const defaultConcurrency = 5
+
+// maxNucleiTargets caps the confirmed paths handed to nuclei.
+const maxNucleiTargets = 500
+
+// nucleiTags select the templates worth running on a discovered path.
+var nucleiTags = []string{"exposure", "config", "backup", "debug", "logs", "panel"}
+
+// fuzzResult is the JSON output format. It is a finding (category, url,
+// severity, detail) so the report, export and suppression pick it up.
+type fuzzResult struct {
+	URL        string `json:"url"`
+	Category   string `json:"category"` // "content", or "vuln" for nuclei matches
+	Severity   string `json:"severity"`
+	Detail     string `json:"detail"`
+	TemplateID string `json:"template_id,omitempty"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Length     int    `json:"content_length,omitempty"`
+	Words      int    `json:"words,omitempty"`
+	Location   string `json:"location,omitempty"`
+	Evidence   string `json:"evidence,omitempty"` // path to raw request/response proof
+
+	ev *evidence.Record // captured by the fuzzer, persisted by emit
+}
+
+func (r fuzzResult) summary() string {
+	if r.Category == "vuln" {
+		return fmt.Sprintf("[%s] %s — %s (%s)", strings.ToUpper(r.Severity), r.URL, r.Detail, r.TemplateID)
+	}
+	return fmt.Sprintf("[CONTENT-%s] %s [%d] [%d bytes] — %s", strings.ToUpper(r.Severity), r.URL, r.StatusCode, r.Length, r.Detail)
+}
+
+// sensitivePaths rank what a 200 on a path means. The first match wins.
+var sensitivePaths = []struct {
+	re       *regexp.Regexp
+	severity string
+	detail   string
+}{
+	{regexp.MustCompile(`(?i)(^|/)\.(env|git|svn|hg|bzr|aws|ssh|kube|docker|npmrc|pgpass|htpasswd|composer)([./]|$)|id_rsa|credentials|\.(key|pem)$`), "high", "Secrets or source control metadata exposed"},
+	{regexp.MustCompile(`(?i)\.(zip|tar|tgz|tar\.gz|rar|7z|sql|bak|old|save|swp)$|(^|/)(backup|dump)`), "high", "Backup or database dump exposed"},
+	{regexp.MustCompile(`(?i)actuator/(env|heapdump|mappings)|phpinfo|info\.php|server-(status|info)|_profiler|elmah\.axd|trace\.axd|telescope|laravel\.log|debug\.log`), "medium", "Debug or diagnostic endpoint exposed"},
+	{regexp.MustCompile(`(?i)(^|/)(web\.config|config\.(json|yml|xml|php|js|inc\.php)|application\.(properties|yml)|settings\.py|parameters\.yml|docker-compose\.yml|dockerfile|web\.xml)$`), "medium", "Configuration file exposed"},
+	{regexp.MustCompile(`(?i)admin|manager/html|jmx-console|phpmyadmin|pma|adminer|console|jenkins|kibana|zabbix|webmail|wp-login`), "low", "Administration interface reachable"},
+	{regexp.MustCompile(`(?i)swagger|api-docs|openapi|graphi?ql`), "low", "API documentation or console exposed"},
+}
+
+// classify returns the severity and detail of a discovered path. Only 200
+// responses can carry more than info: a 401/403 proves the path exists but
+// not that anything leaks.
+func classify(path string, status int) (string, string) {
+	if status == 200 {
+		for _, p := range sensitivePaths {
+			if p.re.MatchString(path) {
+				return p.severity, p.detail
+			}
+		}
+	}
+	switch {
+	case status == 401:
+		return "info", "Authentication required"
+	case status == 403:
+		return "info", "Forbidden path exists"
+	case status >= 300 && status < 400:
+		return "info", "Redirecting path"
+	case status == 500:
+		return "info", "Path raises a server error"
+	}
+	return "info", "Unlinked content"
+}
+
+func (w *FuzzWorkflow) Run(domain string, s *scope.Scope, opts workflows.OutputOptions) error {
+	if !s.IsInScope(domain) {
+		return fmt.Errorf("domain %s is not in scope", domain)
+	}
+
+	// ── Step 1: Hosts ─────────────────────────────────────────────────
+	hosts := []string{domain}
+	if s.HasWildcard(domain) {
+		hosts = append(hosts, discover.Subdomains(domain, s)...)
+	}
+
+	// ── Step 2: httpx → live base URLs ────────────────────────────────
+	bases := discover.Live(hosts, s)
+	if len(bases) == 0 {
+		return fmt.Errorf("no live hosts for %s", domain)
+	}
+
+	// ── Output files ──────────────────────────────────────────────────
+	var textFile, jsonFile *os.File
+	var err error
+	if opts.TextFile != "" {
+		textFile, err = os.OpenFile(opts.TextFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open text output: %w", err)
+		}
+		defer textFile.Close()
+	}
+	if opts.JSONFile != "" {
+		jsonFile, err = os.OpenFile(opts.JSONFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open JSON output: %w", err)
+		}
+		defer jsonFile.Close()
+	}
+
+	store, err := evidence.NewStore(opts.EvidenceDir, opts.EvidenceMaxBytes)
+	if err != nil {
+		return err
+	}
+
+	var mu sync.Mutex
+	emit := func(r fuzzResult) {
+		r.Evidence = store.Save(r.Category, r.URL, r.Detail, r.ev)
+		mu.Lock()
+		defer mu.Unlock()
+		if textFile == nil && jsonFile == nil {
+			fmt.Println(r.summary())
+		}
+		if textFile != nil {
+			fmt.Fprintln(textFile, r.summary())
+		}
+		if jsonFile != nil {
+			if js, jErr := json.Marshal(r); jErr == nil {
+				fmt.Fprintln(jsonFile, string(js))
+			}
+		}
+	}
+
+	// ── Step 3: Fuzzing ───────────────────────────────────────────────
+	fOpts := fuzz.Options{
+		Wordlist:     opts.Wordlist,
+		Extensions:   opts.Extensions,
+		Recursion:    opts.Recursion,
+		RateLimit:    opts.RateLimit,
+		FilterStatus: opts.FilterStatus,
+		FilterSize:   opts.FilterSize,
+		FilterWords:  opts.FilterWords,
+	}
+	words, err := fuzz.LoadWordlist(fOpts.Wordlist)
+	if err != nil {
+		return err
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	fmt.Printf("[*] Fuzzing %d hosts (%d words, %d extensions, recursion %d, %d hosts in parallel)...\n",
+		len(bases), len(words), len(fOpts.Extensions), fOpts.Recursion, concurrency)
+
+	// With --waf-backoff, hosts behind a WAF are fuzzed at a lower rate.
+	var wafs map[string]waf.Result
+	if opts.WAFBackoff {
+		wafs = waf.DetectAll(bases, 0)
+		fmt.Printf("[+] %d hosts behind a WAF — fuzzed at %d req/s\n", len(wafs), waf.BackoffRateLimit)
+	}
+
+	var found, requests, calibrated int64
+	var confirmed []string
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, base := range bases {
+		wg.Add(1)
+		go func(base string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			hostOpts := fOpts
+			if _, ok := wafs[base]; ok && (hostOpts.RateLimit <= 0 || hostOpts.RateLimit > waf.BackoffRateLimit) {
+				hostOpts.RateLimit = waf.BackoffRateLimit
+			}
+			stats, fErr := fuzz.Run(base, hostOpts, func(r fuzz.Result) {
+				if r.Status == 200 || r.Dir {
+					mu.Lock()
+					confirmed = append(confirmed, r.URL)
+					mu.Unlock()
+				}
+				severity, detail := classify(r.Path, r.Status)
+				emit(fuzzResult{
+					URL:        r.URL,
+					Category:   "content",
+					Severity:   severity,
+					Detail:     detail,
+					StatusCode: r.Status,
+					Length:     r.Size,
+					Words:      r.Words,
+					Location:   r.Location,
+					ev:         r.Evidence,
+				})
+			})
+			if fErr != nil {
+				fmt.Printf("[!] %s: %v\n", base, fErr)
+				return
+			}
+			atomic.AddInt64(&found, stats.Found)
+			atomic.AddInt64(&requests, stats.Requests)
+			atomic.AddInt64(&calibrated, stats.Calibrated)
+		}(base)
+	}
+	wg.Wait()
+	fmt.Printf("[+] %d requests — %d soft-404 answers dropped by calibration\n", requests, calibrated)
+
+	// ── Step 4: Nuclei on confirmed paths ─────────────────────────────
+	sort.Strings(confirmed)
+	if len(confirmed) > maxNucleiTargets {
+		fmt.Printf("[*] %d confirmed paths, nuclei runs on the first %d\n", len(confirmed), maxNucleiTargets)
+		confirmed = confirmed[:maxNucleiTargets]
+	}
+	var vulns int64
+	if len(wafs) == 0 {
+		vulns = runNuclei(confirmed, false, emit)
+	} else {
+		protected, open := waf.Split(confirmed, wafs)
+		vulns = runNuclei(open, false, emit) + runNuclei(protected, true, emit)
+	}
+
+	// ── Summary ───────────────────────────────────────────────────────
+	if opts.TextFile != "" {
+		fmt.Printf("[+] Text results saved to: %s\n", opts.TextFile)
+	}
+	if opts.JSONFile != "" {
+		fmt.Printf("[+] JSON results saved to: %s\n", opts.JSONFile)
+	}
+	fmt.Printf("[+] Workflow 'fuzz' completed — %d paths found, %d nuclei matches\n", found, vulns)
+	return nil
+}
+
+// runNuclei scans the confirmed paths with the exposure/config/backup/panel
+// templates. With backoff (hosts behind a WAF) noisy tags are excluded and
+// requests are rate limited.
+func runNuclei(targets []string, backoff bool, emit func(fuzzResult)) int64 {
+	if len(targets) == 0 {
+		return 0
+	}
+	fmt.Printf("[*] Scanning %d confirmed paths with nuclei...\n", len(targets))
+
+	ctx := context.Background()
+	tm := &installer.TemplateManager{}
+	if err := tm.FreshInstallIfNotExists(); err != nil {
+		fmt.Printf("[!] Could not install nuclei templates: %s\n", err)
+		return 0
+	}
+
+	filters := nuclei.TemplateFilters{
+		Severity: "low,medium,high,critical",
+		Tags:     nucleiTags,
+	}
+	nucleiOpts := []nuclei.NucleiSDKOptions{
+		nuclei.WithVerbosity(nuclei.VerbosityOptions{Silent: true}),
+		nuclei.DisableUpdateCheck(),
+	}
+	if backoff {
+		filters.ExcludeTags = waf.NoisyTags
+		nucleiOpts = append(nucleiOpts, nuclei.WithGlobalRateLimit(waf.BackoffRateLimit, time.Second))
+	}
+	ne, err := nuclei.NewNucleiEngineCtx(ctx, append(nucleiOpts, nuclei.WithTemplateFilters(filters))...)
+	if err != nil {
+		fmt.Printf("[!] Could not create nuclei engine: %s\n", err)
+		return 0
+	}
+	defer ne.Close()
+
+	if err := ne.LoadAllTemplates(); err != nil {
+		fmt.Printf("[!] Could not load nuclei templates: %s\n", err)
+		return 0
+	}
+	ne.LoadTargets(targets, false)
+
+	var count int64
+	if err := ne.ExecuteCallbackWithCtx(ctx, func(event *nuclei_output.ResultEvent) {
+		var ev *evidence.Record
+		if event.Request != "" || event.Response != "" {
+			ev = evidence.Raw(event.Request, event.Response)
+		}
+		emit(fuzzResult{
+			URL:        event.Matched,
+			Category:   "vuln",
+			Severity:   event.Info.SeverityHolder.Severity.String(),
+			Detail:     event.Info.Name,
+			TemplateID: event.TemplateID,
+			ev:         ev,
+		})
+		atomic.AddInt64(&count, 1)
+	}); err != nil {
+		fmt.Printf("[!] Nuclei scan error: %s\n", err)
+	}
+
+	fmt.Printf("[+] Nuclei found %d issues on confirmed paths\n", atomic.LoadInt64(&count))
+	return atomic.LoadInt64(&count)
+}
@@ -12,6 +12,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/FOUEN/narmol/internal/evidence"
 	"github.com/FOUEN/narmol/internal/scope"
 	"github.com/FOUEN/narmol/internal/workflows"
 	"github.com/FOUEN/narmol/internal/workflows/secrets"
@@ -37,6 +38,9 @@ type gitResult struct {
 	Phase    string `json:"phase"`    // "exposed", "secret"
 	Severity string `json:"severity"`
 	Detail   string `json:"detail"`
+	Evidence string `json:"evidence,omitempty"` // path to raw request/response proof
+
+	ev *evidence.Record // captured by the check, persisted by emit
 }
 
 func (r gitResult) summary() string {
@@ -68,12 +72,18 @@ func (w *GitExposeWorkflow) Run(domain string, s *scope.Scope, opts workflows.Ou
 		defer jsonFile.Close()
 	}
 
+	store, err := evidence.NewStore(opts.EvidenceDir, opts.EvidenceMaxBytes)
+	if err != nil {
+		return err
+	}
+
 	seen := &sync.Map{}
 	emit := func(r gitResult) bool {
 		key := r.Phase + ":" + r.URL + ":" + r.Detail
 		if _, loaded := seen.LoadOrStore(key, true); loaded {
 			return false
 		}
+		r.Evidence = store.Save(r.Phase, r.URL, r.Detail, r.ev)
 		if textFile == nil && jsonFile == nil {
 			fmt.Println(r.summary())
 		}
@@ -136,6 +146,10 @@ func (w *GitExposeWorkflow) Run(domain string, s *scope.Scope, opts workflows.Ou
 				if reqErr != nil {
 					continue
 				}
+				var ev *evidence.Record
+				if resp.StatusCode == 200 {
+					ev = evidence.HTTP(resp.Request, resp, nil)
+				}
 				resp.Body.Close()
 
 				if resp.StatusCode == 200 {
@@ -144,6 +158,7 @@ func (w *GitExposeWorkflow) Run(domain string, s *scope.Scope, opts workflows.Ou
 						Phase:    "exposed",
 						Severity: "high",
 						Detail:   fmt.Sprintf("Git repository exposed: %s (HTTP 200)", path),
+						ev:       ev,
 					}) {
 						atomic.AddInt64(&exposedCount, 1)
 					}
@@ -14,6 +14,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/FOUEN/narmol/internal/evidence"
 	"github.com/FOUEN/narmol/internal/scope"
 	"github.com/FOUEN/narmol/internal/workflows"
 )
@@ -38,6 +39,9 @@ type headerResult struct {
 	Category string `json:"category"` // "header", "cors", "cookie", "tls"
 	Severity string `json:"severity"`
 	Detail   string `json:"detail"`
+	Evidence string `json:"evidence,omitempty"` // path to raw request/response proof
+
+	ev *evidence.Record // captured by the check, persisted by emit
 }
 
 func (r headerResult) summary() string {
@@ -94,12 +98,18 @@ func (w *HeadersWorkflow) Run(domain string, s *scope.Scope, opts workflows.Outp
 		defer jsonFile.Close()
 	}
 
+	store, err := evidence.NewStore(opts.EvidenceDir, opts.EvidenceMaxBytes)
+	if err != nil {
+		return err
+	}
+
 	seen := &sync.Map{}
 	emit := func(r headerResult) bool {
 		key := r.Category + ":" + r.URL + ":" + r.Detail
 		if _, loaded := seen.LoadOrStore(key, true); loaded {
 			return false
 		}
+		r.Evidence = store.Save(r.Category, r.URL, r.Detail, r.ev)
 		if textFile == nil && jsonFile == nil {
 			fmt.Println(r.summary())
 		}
@@ -190,11 +200,12 @@ func (w *HeadersWorkflow) runHeaderChecks(hosts []string, emit func(headerResult
 				return
 			}
 			defer resp.Body.Close()
+			ev := evidence.HTTP(req, resp, nil)
 
 			// Missing security headers
 			for _, hdr := range requiredHeaders {
 				if resp.Header.Get(hdr.Name) == "" {
-					if emit(headerResult{URL: h, Category: "header", Severity: hdr.Severity, Detail: "Missing " + hdr.Name}) {
+					if emit(headerResult{URL: h, Category: "header", Severity: hdr.Severity, Detail: "Missing " + hdr.Name, ev: ev}) {
 						atomic.AddInt64(&count, 1)
 					}
 				}
@@ -203,13 +214,13 @@ func (w *HeadersWorkflow) runHeaderChecks(hosts []string, emit func(headerResult
 			// CORS misconfiguration
 			acao := resp.Header.Get("Access-Control-Allow-Origin")
 			if acao == "*" || acao == "https://evil.com" {
-				if emit(headerResult{URL: h, Category: "cors", Severity: "high", Detail: fmt.Sprintf("CORS misconfiguration: ACAO=%s", acao)}) {
+				if emit(headerResult{URL: h, Category: "cors", Severity: "high", Detail: fmt.Sprintf("CORS misconfiguration: ACAO=%s", acao), ev: ev}) {
 					atomic.AddInt64(&count, 1)
 				}
 			}
 			acac := resp.Header.Get("Access-Control-Allow-Credentials")
 			if acac == "true" && (acao == "*" || acao == "https://evil.com") {
-				if emit(headerResult{URL: h, Category: "cors", Severity: "critical", Detail: "CORS with credentials: origin reflected + Allow-Credentials: true"}) {
+				if emit(headerResult{URL: h, Category: "cors", Severity: "critical", Detail: "CORS with credentials: origin reflected + Allow-Credentials: true", ev: ev}) {
 					atomic.AddInt64(&count, 1)
 				}
 			}
@@ -227,7 +238,7 @@ func (w *HeadersWorkflow) runHeaderChecks(hosts []string, emit func(headerResult
 					issues = append(issues, "missing/weak SameSite")
 				}
 				if len(issues) > 0 {
-					if emit(headerResult{URL: h, Category: "cookie", Severity: "low", Detail: fmt.Sprintf("Cookie '%s': %s", cookie.Name, strings.Join(issues, ", "))}) {
+					if emit(headerResult{URL: h, Category: "cookie", Severity: "low", Detail: fmt.Sprintf("Cookie '%s': %s", cookie.Name, strings.Join(issues, ", ")), ev: ev}) {
 						atomic.AddInt64(&count, 1)
 					}
 				}
@@ -288,22 +299,23 @@ func (w *HeadersWorkflow) runTLSChecks(hosts []string, emit func(headerResult) b
 			defer conn.Close()
 
 			state := conn.ConnectionState()
+			ev := evidence.TLS(addr, state)
 
 			// Protocol version
 			switch state.Version {
 			case tls.VersionTLS10:
-				if emit(headerResult{URL: h, Category: "tls", Severity: "high", Detail: "TLS 1.0 supported (deprecated, vulnerable to BEAST/POODLE)"}) {
+				if emit(headerResult{URL: h, Category: "tls", Severity: "high", Detail: "TLS 1.0 supported (deprecated, vulnerable to BEAST/POODLE)", ev: ev}) {
 					atomic.AddInt64(&count, 1)
 				}
 			case tls.VersionTLS11:
-				if emit(headerResult{URL: h, Category: "tls", Severity: "medium", Detail: "TLS 1.1 supported (deprecated)"}) {
+				if emit(headerResult{URL: h, Category: "tls", Severity: "medium", Detail: "TLS 1.1 supported (deprecated)", ev: ev}) {
 					atomic.AddInt64(&count, 1)
 				}
 			}
 
 			// Weak cipher suite
 			if name, weak := weakCiphers[state.CipherSuite]; weak {
-				if emit(headerResult{URL: h, Category: "tls", Severity: "high", Detail: fmt.Sprintf("Weak cipher suite: %s", name)}) {
+				if emit(headerResult{URL: h, Category: "tls", Severity: "high", Detail: fmt.Sprintf("Weak cipher suite: %s", name), ev: ev}) {
 					atomic.AddInt64(&count, 1)
 				}
 			}
@@ -314,12 +326,12 @@ func (w *HeadersWorkflow) runTLSChecks(hosts []string, emit func(headerResult) b
 				now := time.Now()
 
 				if now.After(cert.NotAfter) {
-					if emit(headerResult{URL: h, Category: "tls", Severity: "high", Detail: fmt.Sprintf("Certificate expired: %s", cert.NotAfter.Format("2006-01-02"))}) {
+					if emit(headerResult{URL: h, Category: "tls", Severity: "high", Detail: fmt.Sprintf("Certificate expired: %s", cert.NotAfter.Format("2006-01-02")), ev: ev}) {
 						atomic.AddInt64(&count, 1)
 					}
 				}
 				if now.Before(cert.NotAfter) && cert.NotAfter.Before(now.Add(30*24*time.Hour)) {
-					if emit(headerResult{URL: h, Category: "tls", Severity: "medium", Detail: fmt.Sprintf("Certificate expiring soon: %s", cert.NotAfter.Format("2006-01-02"))}) {
+					if emit(headerResult{URL: h, Category: "tls", Severity: "medium", Detail: fmt.Sprintf("Certificate expiring soon: %s", cert.NotAfter.Format("2006-01-02")), ev: ev}) {
 						atomic.AddInt64(&count, 1)
 					}
 				}
@@ -327,13 +339,13 @@ func (w *HeadersWorkflow) runTLSChecks(hosts []string, emit func(headerResult) b
 					pool := x509.NewCertPool()
 					pool.AddCert(cert)
 					if _, verifyErr := cert.Verify(x509.VerifyOptions{Roots: pool}); verifyErr == nil {
-						if emit(headerResult{URL: h, Category: "tls", Severity: "medium", Detail: "Self-signed certificate"}) {
+						if emit(headerResult{URL: h, Category: "tls", Severity: "medium", Detail: "Self-signed certificate", ev: ev}) {
 							atomic.AddInt64(&count, 1)
 						}
 					}
 				}
 				if err := cert.VerifyHostname(hostname); err != nil {
-					if emit(headerResult{URL: h, Category: "tls", Severity: "high", Detail: fmt.Sprintf("Certificate hostname mismatch: cert for %s", strings.Join(cert.DNSNames, ", "))}) {
+					if emit(headerResult{URL: h, Category: "tls", Severity: "high", Detail: fmt.Sprintf("Certificate hostname mismatch: cert for %s", strings.Join(cert.DNSNames, ", ")), ev: ev}) {
 						atomic.AddInt64(&count, 1)
 					}
 				}
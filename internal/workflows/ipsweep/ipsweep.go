@@ -0,0 +1,242 @@
+package ipsweep
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/FOUEN/narmol/internal/scope"
+	"github.com/FOUEN/narmol/internal/workflows"
+
+	dns "github.com/miekg/dns"
+	"github.com/projectdiscovery/dnsx/libs/dnsx"
+)
+
+func init() {
+	workflows.Register(&IPSweepWorkflow{})
+}
+
+// IPSweepWorkflow discovers hostnames behind the IP/CIDR rules of the scope.
+// Pipeline: expand range (minus excludes) → PTR lookup (dnsx) + TLS
+// certificate SAN harvesting on :443 → scope filter on every name found.
+type IPSweepWorkflow struct{}
+
+func (w *IPSweepWorkflow) Name() string { return "ipsweep" }
+
+func (w *IPSweepWorkflow) Description() string {
+	return "Reverse DNS (PTR) + TLS SAN sweep of in-scope IPs/CIDRs to discover hostnames."
+}
+
+// TargetsIPs makes the CLI run ipsweep once per IP/CIDR scope rule.
+func (w *IPSweepWorkflow) TargetsIPs() bool { return true }
+
+const (
+	defaultConcurrency = 50
+	// maxSweep caps the addresses expanded from one rule (a /16).
+	maxSweep = 1 << 16
+)
+
+// sweepResult is the JSON output format: one hostname found on one IP.
+type sweepResult struct {
+	Host   string `json:"host"`
+	IP     string `json:"ip"`
+	Source string `json:"source"` // "ptr" or "tls-san"
+}
+
+func (r sweepResult) summary() string {
+	return fmt.Sprintf("%s → %s (%s)", r.IP, r.Host, r.Source)
+}
+
+func (w *IPSweepWorkflow) Run(target string, s *scope.Scope, opts workflows.OutputOptions) error {
+	ips, err := expand(target)
+	if err != nil {
+		return err
+	}
+
+	// Excludes (-10.0.0.5, -10.0.0.128/25) always win.
+	inScope := ips[:0]
+	for _, ip := range ips {
+		if s.IsInScope(ip) {
+			inScope = append(inScope, ip)
+		}
+	}
+	if len(inScope) == 0 {
+		return fmt.Errorf("no in-scope addresses in %s", target)
+	}
+
+	// ── Output files ──────────────────────────────────────────────────
+	var textFile, jsonFile *os.File
+	if opts.TextFile != "" {
+		textFile, err = os.OpenFile(opts.TextFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open text output: %w", err)
+		}
+		defer textFile.Close()
+	}
+	if opts.JSONFile != "" {
+		jsonFile, err = os.OpenFile(opts.JSONFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open JSON output: %w", err)
+		}
+		defer jsonFile.Close()
+	}
+
+	var found, outOfScope int64
+	seen := &sync.Map{}
+	emit := func(r sweepResult) {
+		if _, loaded := seen.LoadOrStore(r.IP+"|"+r.Host, true); loaded {
+			return
+		}
+		// Names outside the scope are only counted: they must never
+		// become targets.
+		if !s.IsInScope(r.Host) {
+			atomic.AddInt64(&outOfScope, 1)
+			return
+		}
+		atomic.AddInt64(&found, 1)
+		if textFile == nil && jsonFile == nil {
+			fmt.Println(r.summary())
+		}
+		if textFile != nil {
+			fmt.Fprintln(textFile, r.summary())
+		}
+		if jsonFile != nil {
+			if js, jErr := json.Marshal(r); jErr == nil {
+				fmt.Fprintln(jsonFile, string(js))
+			}
+		}
+	}
+
+	// ── PTR + TLS SAN sweep ───────────────────────────────────────────
+	dnsOpts := dnsx.DefaultOptions
+	dnsOpts.MaxRetries = 2
+	dnsOpts.QuestionTypes = []uint16{dns.TypePTR}
+	if len(opts.Resolvers) > 0 {
+		dnsOpts.BaseResolvers = opts.Resolvers
+	}
+	client, err := dnsx.New(dnsOpts)
+	if err != nil {
+		return fmt.Errorf("could not create dnsx client: %w", err)
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	fmt.Printf("[*] Sweeping %d addresses in %s (PTR + TLS SAN, %d workers)...\n", len(inScope), target, concurrency)
+
+	work := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ip := range work {
+				if data, err := client.QueryOne(ip); err == nil && data != nil {
+					for _, name := range data.PTR {
+						emit(sweepResult{Host: normalize(name), IP: ip, Source: "ptr"})
+					}
+				}
+				for _, name := range certNames(ip) {
+					emit(sweepResult{Host: name, IP: ip, Source: "tls-san"})
+				}
+			}
+		}()
+	}
+	for _, ip := range inScope {
+		work <- ip
+	}
+	close(work)
+	wg.Wait()
+
+	// ── Summary ───────────────────────────────────────────────────────
+	if opts.TextFile != "" {
+		fmt.Printf("[+] Text results saved to: %s\n", opts.TextFile)
+	}
+	if opts.JSONFile != "" {
+		fmt.Printf("[+] JSON results saved to: %s\n", opts.JSONFile)
+	}
+	fmt.Printf("[+] Workflow 'ipsweep' completed — %d in-scope hostnames, %d out-of-scope names ignored\n", found, outOfScope)
+	return nil
+}
+
+// expand returns every address of a single IP or CIDR rule. Network and
+// broadcast addresses of IPv4 ranges are kept: PTR records are sometimes set
+// on them and the TLS dial simply fails.
+func expand(target string) ([]string, error) {
+	if ip := net.ParseIP(target); ip != nil {
+		return []string{ip.String()}, nil
+	}
+	_, network, err := net.ParseCIDR(target)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not an IP or CIDR", target)
+	}
+	ones, bits := network.Mask.Size()
+	if bits-ones > 16 {
+		return nil, fmt.Errorf("%s is too large to sweep (max /%d for this address family)", target, bits-16)
+	}
+
+	ips := make([]string, 0, maxSweep)
+	ip := network.IP.Mask(network.Mask)
+	for ; network.Contains(ip); ip = next(ip) {
+		ips = append(ips, ip.String())
+		if len(ips) == maxSweep {
+			break
+		}
+	}
+	return ips, nil
+}
+
+// next returns ip + 1.
+func next(ip net.IP) net.IP {
+	out := make(net.IP, len(ip))
+	copy(out, ip)
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i]++
+		if out[i] != 0 {
+			break
+		}
+	}
+	return out
+}
+
+// certNames dials ip:443 and returns the hostnames of the leaf certificate
+// (SANs and subject CN). Wildcard names are reduced to their base domain.
+func certNames(ip string) []string {
+	conn, err := tls.DialWithDialer(
+		&net.Dialer{Timeout: 5 * time.Second},
+		"tcp", net.JoinHostPort(ip, "443"),
+		&tls.Config{InsecureSkipVerify: true},
+	)
+	if err != nil {
+		return nil
+	}
+	defer conn.Close()
+
+	certs := conn.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return nil
+	}
+	leaf := certs[0]
+
+	var names []string
+	for _, n := range append([]string{leaf.Subject.CommonName}, leaf.DNSNames...) {
+		n = normalize(n)
+		if n != "" && strings.Contains(n, ".") && net.ParseIP(n) == nil {
+			names = append(names, n)
+		}
+	}
+	return names
+}
+
+func normalize(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.TrimSuffix(name, ".")
+	return strings.TrimPrefix(name, "*.")
+}
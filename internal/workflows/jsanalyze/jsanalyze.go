@@ -0,0 +1,393 @@
+package jsanalyze
+
+import (
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/FOUEN/narmol/internal/scope"
+	"github.com/FOUEN/narmol/internal/workflows"
+	"github.com/FOUEN/narmol/internal/workflows/secrets"
+	"github.com/FOUEN/narmol/internal/workflows/urls"
+)
+
+func init() {
+	workflows.Register(&JSAnalyzeWorkflow{})
+}
+
+// JSAnalyzeWorkflow mines the JavaScript of a target.
+// Pipeline: gau + katana → in-scope .js URLs → download (+ exposed source
+// maps, unpacked) → extraction of API paths, URLs, GraphQL operations and
+// cloud buckets → TruffleHog over every file downloaded.
+type JSAnalyzeWorkflow struct{}
+
+func (w *JSAnalyzeWorkflow) Name() string { return "jsanalyze" }
+
+func (w *JSAnalyzeWorkflow) Description() string {
+	return "JavaScript analysis: endpoints, URLs, GraphQL operations, buckets, source maps and secrets (TruffleHog) from JS files."
+}
+
+const (
+	defaultConcurrency = 10
+	// maxJSSize caps each download; bundles above it are truncated.
+	maxJSSize = 10 * 1024 * 1024
+)
+
+// jsResult is the JSON output format. URL is always the JS file (or source
+// map) the item was found in. Secrets and exposed source maps carry a
+// phase and severity and are findings; the rest is inventory.
+type jsResult struct {
+	URL      string `json:"url"`
+	Type     string `json:"type"` // endpoint, url, graphql, bucket, sourcemap, secret
+	Match    string `json:"match"`
+	Phase    string `json:"phase,omitempty"`
+	Severity string `json:"severity,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+}
+
+func (r jsResult) summary() string {
+	if r.Severity != "" {
+		return fmt.Sprintf("[%s-%s] %s — %s", strings.ToUpper(r.Type), strings.ToUpper(r.Severity), r.URL, r.Detail)
+	}
+	return fmt.Sprintf("[%s] %s (%s)", strings.ToUpper(r.Type), r.Match, r.URL)
+}
+
+var (
+	jsURLRe = regexp.MustCompile(`(?i)\.m?js(\?|#|$)`)
+
+	fullURLRe = regexp.MustCompile(`https?://[a-zA-Z0-9.-]+(:\d+)?(/[^\s"'<>\x60\\)]*)?`)
+	// Quoted absolute paths with at least one letter: "/api/v1/users/{id}".
+	pathRe = regexp.MustCompile(`["'\x60](/[a-zA-Z0-9_\-./{}:$?=&%]*[a-zA-Z][a-zA-Z0-9_\-./{}:$?=&%]*)["'\x60]`)
+	// staticRe drops paths to assets, which carry no attack surface.
+	staticRe  = regexp.MustCompile(`(?i)\.(png|jpe?g|gif|svg|ico|webp|css|woff2?|ttf|eot|otf|mp4|mp3|webm)(\?|$)`)
+	graphqlRe = regexp.MustCompile(`\b(query|mutation|subscription)\s+([A-Za-z_][A-Za-z0-9_]*)\s*[({]`)
+
+	sourceMapRe = regexp.MustCompile(`//[#@]\s*sourceMappingURL=(\S+)`)
+)
+
+// bucketPatterns recognise cloud storage references. The first submatch is
+// the bucket (or account) name.
+var bucketPatterns = []struct {
+	provider string
+	re       *regexp.Regexp
+}{
+	{"s3", regexp.MustCompile(`([a-z0-9][a-z0-9.-]{1,61}[a-z0-9])\.s3[.-](?:[a-z0-9-]+\.)?amazonaws\.com`)},
+	{"s3", regexp.MustCompile(`s3[.-](?:[a-z0-9-]+\.)?amazonaws\.com/([a-z0-9][a-z0-9.-]{1,61}[a-z0-9])`)},
+	{"s3", regexp.MustCompile(`s3://([a-z0-9][a-z0-9.-]{1,61}[a-z0-9])`)},
+	{"gcs", regexp.MustCompile(`([a-z0-9][a-z0-9._-]{1,61}[a-z0-9])\.storage\.googleapis\.com`)},
+	{"gcs", regexp.MustCompile(`storage\.(?:googleapis|cloud\.google)\.com/([a-z0-9][a-z0-9._-]{1,61}[a-z0-9])`)},
+	{"gcs", regexp.MustCompile(`gs://([a-z0-9][a-z0-9._-]{1,61}[a-z0-9])`)},
+	{"azure", regexp.MustCompile(`([a-z0-9]{3,24})\.blob\.core\.windows\.net`)},
+	{"spaces", regexp.MustCompile(`([a-z0-9][a-z0-9-]{1,61}[a-z0-9])\.[a-z0-9]+\.digitaloceanspaces\.com`)},
+}
+
+// sourceMap is the part of a source map (v3) that matters here.
+type sourceMap struct {
+	Sources        []string `json:"sources"`
+	SourcesContent []string `json:"sourcesContent"`
+}
+
+func (w *JSAnalyzeWorkflow) Run(domain string, s *scope.Scope, opts workflows.OutputOptions) error {
+	if !s.IsInScope(domain) {
+		return fmt.Errorf("domain %s is not in scope", domain)
+	}
+
+	// ── Step 1: JS URLs from gau + katana ─────────────────────────────
+	var mu sync.Mutex
+	var jsFiles []string
+	urls.Collect(domain, s, opts.Gau, func(u, source string) {
+		if jsURLRe.MatchString(u) {
+			mu.Lock()
+			jsFiles = append(jsFiles, u)
+			mu.Unlock()
+		}
+	})
+	if len(jsFiles) == 0 {
+		return fmt.Errorf("no in-scope JavaScript files found for %s", domain)
+	}
+	sort.Strings(jsFiles)
+	fmt.Printf("[+] %d JavaScript files to analyze\n", len(jsFiles))
+
+	// ── Output files ──────────────────────────────────────────────────
+	var textFile, jsonFile *os.File
+	var err error
+	if opts.TextFile != "" {
+		textFile, err = os.OpenFile(opts.TextFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open text output: %w", err)
+		}
+		defer textFile.Close()
+	}
+	if opts.JSONFile != "" {
+		jsonFile, err = os.OpenFile(opts.JSONFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open JSON output: %w", err)
+		}
+		defer jsonFile.Close()
+	}
+
+	counts := map[string]int{}
+	seen := map[string]bool{}
+	emit := func(r jsResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		// Inventory is deduplicated across files: an endpoint found in ten
+		// chunks is one endpoint.
+		key := r.Type + "|" + r.Match
+		if r.Severity != "" {
+			key += "|" + r.URL
+		}
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		counts[r.Type]++
+		if textFile == nil && jsonFile == nil {
+			fmt.Println(r.summary())
+		}
+		if textFile != nil {
+			fmt.Fprintln(textFile, r.summary())
+		}
+		if jsonFile != nil {
+			if js, jErr := json.Marshal(r); jErr == nil {
+				fmt.Fprintln(jsonFile, string(js))
+			}
+		}
+	}
+
+	// Every downloaded file (and unpacked source) lands here for TruffleHog;
+	// files maps each one back to the URL it came from.
+	workDir, err := os.MkdirTemp("", "narmol-js-*")
+	if err != nil {
+		return fmt.Errorf("could not create work directory: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+	files := map[string]string{}
+	save := func(name, origin string, data []byte) {
+		path := filepath.Join(workDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return
+		}
+		if err := os.WriteFile(path, data, 0644); err == nil {
+			mu.Lock()
+			files[path] = origin
+			mu.Unlock()
+		}
+	}
+
+	// ── Step 2: Download + extract + source maps ──────────────────────
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig:     &tls.Config{InsecureSkipVerify: true},
+			MaxIdleConnsPerHost: 10,
+			DialContext: (&net.Dialer{
+				Timeout: 5 * time.Second,
+			}).DialContext,
+		},
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	fmt.Printf("[*] Downloading and analyzing %d files (%d workers)...\n", len(jsFiles), concurrency)
+
+	var downloaded, maps int64
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, jsURL := range jsFiles {
+		wg.Add(1)
+		go func(jsURL string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			body, header, err := fetch(client, jsURL)
+			if err != nil {
+				return
+			}
+			atomic.AddInt64(&downloaded, 1)
+			save(fileKey(jsURL)+".js", jsURL, body)
+			extract(jsURL, string(body), s, emit)
+
+			mapURL := sourceMapURL(jsURL, string(body), header)
+			if mapURL == "" || !s.IsInScope(mapURL) {
+				return
+			}
+			raw, _, err := fetch(client, mapURL)
+			if err != nil {
+				return
+			}
+			var sm sourceMap
+			if json.Unmarshal(raw, &sm) != nil || len(sm.Sources) == 0 {
+				return
+			}
+			atomic.AddInt64(&maps, 1)
+
+			unpacked := 0
+			for i, src := range sm.Sources {
+				if i >= len(sm.SourcesContent) || sm.SourcesContent[i] == "" {
+					continue
+				}
+				name := sourcePath(src)
+				if name == "" {
+					name = fmt.Sprintf("source-%d.js", i)
+				}
+				save(filepath.Join(fileKey(mapURL), name), mapURL, []byte(sm.SourcesContent[i]))
+				extract(mapURL, sm.SourcesContent[i], s, emit)
+				unpacked++
+			}
+			severity, detail := "low", fmt.Sprintf("Source map exposed (%d sources listed)", len(sm.Sources))
+			if unpacked > 0 {
+				severity, detail = "medium", fmt.Sprintf("Source map exposed with original source code (%d files unpacked)", unpacked)
+			}
+			emit(jsResult{URL: mapURL, Type: "sourcemap", Match: jsURL, Phase: "js", Severity: severity, Detail: detail})
+		}(jsURL)
+	}
+	wg.Wait()
+	fmt.Printf("[+] Downloaded %d files, %d source maps\n", downloaded, maps)
+
+	// ── Step 3: TruffleHog ────────────────────────────────────────────
+	if len(files) > 0 {
+		fmt.Printf("[*] Scanning %d files with TruffleHog...\n", len(files))
+		results, err := secrets.ScanPath(workDir)
+		if err != nil {
+			fmt.Printf("[!] TruffleHog error: %s\n", err)
+		}
+		for _, sr := range results {
+			origin := files[sr.File]
+			if origin == "" {
+				origin = domain
+			}
+			severity := "high"
+			if sr.Verified {
+				severity = "critical"
+			}
+			emit(jsResult{
+				URL:      origin,
+				Type:     "secret",
+				Match:    sr.Redacted,
+				Phase:    "secret",
+				Severity: severity,
+				Detail:   fmt.Sprintf("[%s] %s", sr.DetectorType, sr.Redacted),
+			})
+		}
+	}
+
+	// ── Summary ───────────────────────────────────────────────────────
+	if opts.TextFile != "" {
+		fmt.Printf("[+] Text results saved to: %s\n", opts.TextFile)
+	}
+	if opts.JSONFile != "" {
+		fmt.Printf("[+] JSON results saved to: %s\n", opts.JSONFile)
+	}
+	fmt.Printf("[+] Workflow 'jsanalyze' completed — %d endpoints, %d URLs, %d GraphQL operations, %d buckets, %d source maps, %d secrets\n",
+		counts["endpoint"], counts["url"], counts["graphql"], counts["bucket"], counts["sourcemap"], counts["secret"])
+	return nil
+}
+
+// extract emits the endpoints, in-scope URLs, GraphQL operations and
+// buckets referenced in content.
+func extract(origin, content string, s *scope.Scope, emit func(jsResult)) {
+	for _, m := range pathRe.FindAllStringSubmatch(content, -1) {
+		p := m[1]
+		if strings.HasPrefix(p, "//") || staticRe.MatchString(p) {
+			continue
+		}
+		emit(jsResult{URL: origin, Type: "endpoint", Match: p})
+	}
+	for _, u := range fullURLRe.FindAllString(content, -1) {
+		if staticRe.MatchString(u) || !s.IsInScope(u) {
+			continue
+		}
+		emit(jsResult{URL: origin, Type: "url", Match: u})
+	}
+	for _, m := range graphqlRe.FindAllStringSubmatch(content, -1) {
+		emit(jsResult{URL: origin, Type: "graphql", Match: m[1] + " " + m[2]})
+	}
+	lower := strings.ToLower(content)
+	for _, p := range bucketPatterns {
+		for _, m := range p.re.FindAllStringSubmatch(lower, -1) {
+			emit(jsResult{URL: origin, Type: "bucket", Match: p.provider + ":" + m[1]})
+		}
+	}
+}
+
+func fetch(client *http.Client, u string) ([]byte, http.Header, error) {
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxJSSize))
+	return body, resp.Header, err
+}
+
+// sourceMapURL returns the absolute URL of the source map announced by the
+// SourceMap header or a sourceMappingURL comment, or jsURL + ".map" when
+// neither is present. Inline (data:) maps are skipped.
+func sourceMapURL(jsURL, body string, header http.Header) string {
+	ref := header.Get("SourceMap")
+	if ref == "" {
+		ref = header.Get("X-SourceMap")
+	}
+	if ref == "" {
+		if m := sourceMapRe.FindAllStringSubmatch(body, -1); len(m) > 0 {
+			ref = m[len(m)-1][1]
+		}
+	}
+	if strings.HasPrefix(ref, "data:") {
+		return ""
+	}
+	base, err := url.Parse(jsURL)
+	if err != nil {
+		return ""
+	}
+	if ref == "" {
+		base.RawQuery, base.Fragment = "", ""
+		return base.String() + ".map"
+	}
+	u, err := base.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	return u.String()
+}
+
+// sourcePath turns a source map entry ("webpack:///./src/api.js") into a
+// relative path that cannot escape the work directory.
+func sourcePath(src string) string {
+	if i := strings.Index(src, "://"); i >= 0 {
+		src = src[i+3:]
+	}
+	clean := filepath.Clean("/" + strings.ReplaceAll(src, "..", "_"))
+	return strings.TrimPrefix(clean, "/")
+}
+
+// fileKey is a short, filesystem-safe name for a URL.
+func fileKey(u string) string {
+	sum := sha1.Sum([]byte(u))
+	return hex.EncodeToString(sum[:8])
+}
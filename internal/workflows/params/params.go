@@ -0,0 +1,307 @@
+package params
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	paraminv "github.com/FOUEN/narmol/internal/params"
+	"github.com/FOUEN/narmol/internal/scope"
+	"github.com/FOUEN/narmol/internal/workflows"
+	"github.com/FOUEN/narmol/internal/workflows/urls"
+)
+
+func init() {
+	workflows.Register(&ParamsWorkflow{})
+}
+
+// ParamsWorkflow builds a per-endpoint parameter inventory.
+// Pipeline: gau + katana URLs (query strings) → HTML pages (forms, links,
+// inline scripts) → JS files → hidden parameter mining with response
+// diffing on host roots and known endpoints.
+type ParamsWorkflow struct{}
+
+func (w *ParamsWorkflow) Name() string { return "params" }
+
+func (w *ParamsWorkflow) Description() string {
+	return "Parameter discovery: names from URLs, forms and JS + hidden parameter mining (Arjun-style diffing), per endpoint."
+}
+
+const (
+	defaultConcurrency = 5
+	// maxPages and maxScripts bound how many pages and JS files are
+	// downloaded for form and script extraction.
+	maxPages   = 200
+	maxScripts = 100
+	// maxMined bounds how many endpoints are brute-forced.
+	maxMined = 50
+	maxBody  = 2 * 1024 * 1024
+)
+
+var (
+	scriptRe = regexp.MustCompile(`(?is)<script\b[^>]*>(.*?)</script>`)
+	jsURLRe  = regexp.MustCompile(`(?i)\.m?js(\?|#|$)`)
+	staticRe = regexp.MustCompile(`(?i)\.(png|jpe?g|gif|svg|ico|webp|css|woff2?|ttf|eot|otf|mp4|mp3|webm|pdf|zip|gz|map|json|xml|txt)(\?|$)`)
+)
+
+// paramResult is the JSON output format: one endpoint and its parameters.
+type paramResult struct {
+	URL     string              `json:"url"`
+	Method  string              `json:"method,omitempty"`
+	Params  map[string][]string `json:"params"`            // name → sources (gau, katana, form, link, js, mined)
+	Classes map[string][]string `json:"classes,omitempty"` // redirect/ssrf → candidate names
+}
+
+func (r paramResult) summary() string {
+	names := make([]string, 0, len(r.Params))
+	for n := range r.Params {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	line := fmt.Sprintf("%s ?%s", r.URL, strings.Join(names, "&"))
+	if r.Method != "" {
+		line += " [" + r.Method + "]"
+	}
+	for _, c := range []string{paraminv.ClassRedirect, paraminv.ClassSSRF} {
+		if len(r.Classes[c]) > 0 {
+			line += fmt.Sprintf(" (%s: %s)", c, strings.Join(r.Classes[c], ", "))
+		}
+	}
+	return line
+}
+
+func (w *ParamsWorkflow) Run(domain string, s *scope.Scope, opts workflows.OutputOptions) error {
+	if !s.IsInScope(domain) {
+		return fmt.Errorf("domain %s is not in scope", domain)
+	}
+	words, err := paraminv.LoadWords(opts.Wordlist)
+	if err != nil {
+		return err
+	}
+
+	inv := paraminv.NewInventory()
+
+	// ── Step 1: URLs from gau + katana ────────────────────────────────
+	var mu sync.Mutex
+	pageSet := map[string]bool{}
+	var pages, scripts []string
+	roots := map[string]bool{}
+	urls.Collect(domain, s, opts.Gau, func(u, source string) {
+		inv.AddURL(u, source)
+		endpoint := paraminv.Normalize(u)
+		if endpoint == "" {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if parsed, err := url.Parse(endpoint); err == nil {
+			roots[parsed.Scheme+"://"+parsed.Host+"/"] = true
+		}
+		switch {
+		case jsURLRe.MatchString(u):
+			scripts = append(scripts, u)
+		case !staticRe.MatchString(u) && !pageSet[endpoint]:
+			pageSet[endpoint] = true
+			pages = append(pages, endpoint)
+		}
+	})
+	fmt.Printf("[+] %d endpoints with parameters from URLs, %d pages, %d scripts\n", inv.Len(), len(pages), len(scripts))
+
+	// Host roots first: they are the most likely to carry forms.
+	for r := range roots {
+		if !pageSet[r] {
+			pageSet[r] = true
+			pages = append(pages, r)
+		}
+	}
+	sort.Strings(pages)
+	sort.SliceStable(pages, func(i, j int) bool { return roots[pages[i]] && !roots[pages[j]] })
+	if len(pages) > maxPages {
+		pages = pages[:maxPages]
+	}
+	if len(scripts) > maxScripts {
+		scripts = scripts[:maxScripts]
+	}
+
+	client := &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig:     &tls.Config{InsecureSkipVerify: true},
+			MaxIdleConnsPerHost: 10,
+			DialContext: (&net.Dialer{
+				Timeout: 5 * time.Second,
+			}).DialContext,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+
+	// ── Step 2: Forms, links and scripts ──────────────────────────────
+	fmt.Printf("[*] Extracting forms and script parameters from %d pages and %d scripts...\n", len(pages), len(scripts))
+	sem := make(chan struct{}, concurrency*4)
+	var wg sync.WaitGroup
+	for _, page := range pages {
+		wg.Add(1)
+		go func(page string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			body, ctype, err := get(client, page)
+			if err != nil || !strings.Contains(ctype, "html") {
+				return
+			}
+			inv.AddPage(page, body)
+			for _, m := range scriptRe.FindAllSubmatch(body, -1) {
+				inv.AddJS(page, m[1])
+			}
+		}(page)
+	}
+	for _, script := range scripts {
+		wg.Add(1)
+		go func(script string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			body, _, err := get(client, script)
+			if err != nil {
+				return
+			}
+			// Script names are attributed to the root of the host serving it.
+			if u, err := url.Parse(script); err == nil {
+				inv.AddJS(u.Scheme+"://"+u.Host+"/", body)
+			}
+		}(script)
+	}
+	wg.Wait()
+
+	// ── Step 3: Hidden parameter mining ───────────────────────────────
+	var targets []string
+	for r := range roots {
+		targets = append(targets, r)
+	}
+	sort.Strings(targets)
+	for _, e := range inv.Endpoints() {
+		if e.Method == "" && !roots[e.URL] {
+			targets = append(targets, e.URL)
+		}
+	}
+	if len(targets) > maxMined {
+		targets = targets[:maxMined]
+	}
+
+	fmt.Printf("[*] Mining hidden parameters on %d endpoints (%d names, %d in parallel)...\n", len(targets), len(words), concurrency)
+	known := map[string][]string{}
+	for _, e := range inv.Endpoints() {
+		known[e.URL] = e.Names()
+	}
+	var mined, requests int64
+	sem = make(chan struct{}, concurrency)
+	for _, target := range targets {
+		if !s.IsInScope(target) {
+			continue
+		}
+		wg.Add(1)
+		go func(target string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			hits, n, err := paraminv.Mine(client, target, words, known[target], 0)
+			atomic.AddInt64(&requests, int64(n))
+			if err != nil {
+				return
+			}
+			for _, h := range hits {
+				inv.Add(target, h.Param, paraminv.SourceMined)
+				atomic.AddInt64(&mined, 1)
+			}
+		}(target)
+	}
+	wg.Wait()
+	fmt.Printf("[+] %d hidden parameters mined (%d requests)\n", mined, requests)
+
+	// ── Output ────────────────────────────────────────────────────────
+	var textFile, jsonFile *os.File
+	if opts.TextFile != "" {
+		textFile, err = os.OpenFile(opts.TextFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open text output: %w", err)
+		}
+		defer textFile.Close()
+	}
+	if opts.JSONFile != "" {
+		jsonFile, err = os.OpenFile(opts.JSONFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open JSON output: %w", err)
+		}
+		defer jsonFile.Close()
+	}
+
+	var total int
+	endpoints := inv.Endpoints()
+	for _, e := range endpoints {
+		r := paramResult{URL: e.URL, Method: e.Method, Params: e.Params, Classes: map[string][]string{}}
+		for _, n := range e.Names() {
+			total++
+			for _, c := range paraminv.Classes(n) {
+				if c != paraminv.ClassXSS {
+					r.Classes[c] = append(r.Classes[c], n)
+				}
+			}
+		}
+		if textFile == nil && jsonFile == nil {
+			fmt.Println(r.summary())
+		}
+		if textFile != nil {
+			fmt.Fprintln(textFile, r.summary())
+		}
+		if jsonFile != nil {
+			if js, jErr := json.Marshal(r); jErr == nil {
+				fmt.Fprintln(jsonFile, string(js))
+			}
+		}
+	}
+
+	// ── Summary ───────────────────────────────────────────────────────
+	if opts.TextFile != "" {
+		fmt.Printf("[+] Text results saved to: %s\n", opts.TextFile)
+	}
+	if opts.JSONFile != "" {
+		fmt.Printf("[+] JSON results saved to: %s\n", opts.JSONFile)
+	}
+	fmt.Printf("[+] Workflow 'params' completed — %d parameters on %d endpoints\n", total, len(endpoints))
+	return nil
+}
+
+func get(client *http.Client, u string) ([]byte, string, error) {
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBody))
+	return body, resp.Header.Get("Content-Type"), err
+}
@@ -0,0 +1,387 @@
+package ports
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/FOUEN/narmol/internal/brute"
+	"github.com/FOUEN/narmol/internal/discover"
+	"github.com/FOUEN/narmol/internal/scope"
+	"github.com/FOUEN/narmol/internal/workflows"
+
+	"github.com/projectdiscovery/cdncheck"
+	"github.com/projectdiscovery/goflags"
+	naabu_privileges "github.com/projectdiscovery/naabu/v2/pkg/privileges"
+	naabu_result "github.com/projectdiscovery/naabu/v2/pkg/result"
+	naabu_runner "github.com/projectdiscovery/naabu/v2/pkg/runner"
+)
+
+func init() {
+	workflows.Register(&PortsWorkflow{})
+}
+
+// PortsWorkflow port-scans the hosts of a scope domain.
+// Pipeline: subfinder (wildcard scope) + dnsx brute-force (--brute) → A
+// records (dnsx) → one scan per unique IP, CDN/WAF IPs skipped unless
+// --include-cdn → naabu (connect, or SYN with --syn when privileged) → every
+// open port reported for each hostname on the IP.
+type PortsWorkflow struct{}
+
+func (w *PortsWorkflow) Name() string { return "ports" }
+
+func (w *PortsWorkflow) Description() string {
+	return "Port scan of in-scope hosts with naabu, deduplicated by IP, CDN IPs skipped. --ports top-100|top-1000|full|<list>."
+}
+
+const (
+	defaultConcurrency = 50
+	// defaultPorts matches the port scan of the full workflow.
+	defaultPorts = "top-1000"
+	defaultRate  = 1500
+)
+
+// portResult is the JSON output format: one open port on one hostname.
+type portResult struct {
+	Host     string `json:"host"`
+	IP       string `json:"ip"`
+	Port     int    `json:"port"`
+	Protocol string `json:"protocol"`
+	CDN      bool   `json:"cdn,omitempty"` // only with --include-cdn
+	CDNName  string `json:"cdn_name,omitempty"`
+}
+
+func (r portResult) summary() string {
+	cdn := ""
+	if r.CDN {
+		cdn = " [cdn: " + r.CDNName + "]"
+	}
+	return fmt.Sprintf("%s:%d/%s (%s)%s", r.Host, r.Port, r.Protocol, r.IP, cdn)
+}
+
+func (w *PortsWorkflow) Run(domain string, s *scope.Scope, opts workflows.OutputOptions) error {
+	if !s.IsInScope(domain) {
+		return fmt.Errorf("domain %s is not in scope", domain)
+	}
+
+	topPorts, portList, err := parsePorts(opts.Ports)
+	if err != nil {
+		return err
+	}
+
+	// ── Step 1: Subdomains ────────────────────────────────────────────
+	hosts := []string{domain}
+	if s.HasWildcard(domain) {
+		hosts = append(hosts, discover.Subdomains(domain, s)...)
+		if opts.Brute {
+			hosts = append(hosts, runBrute(domain, s, opts)...)
+		}
+	}
+	hosts = dedup(hosts)
+
+	// ── Output files ──────────────────────────────────────────────────
+	var textFile, jsonFile *os.File
+	if opts.TextFile != "" {
+		textFile, err = os.OpenFile(opts.TextFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open text output: %w", err)
+		}
+		defer textFile.Close()
+	}
+	if opts.JSONFile != "" {
+		jsonFile, err = os.OpenFile(opts.JSONFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open JSON output: %w", err)
+		}
+		defer jsonFile.Close()
+	}
+
+	// ── Step 2: Resolve and group by IP ───────────────────────────────
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	byIP, ipv6, err := resolveAll(hosts, opts.Resolvers, concurrency)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("[+] %d hosts resolve to %d unique IPs\n", len(hosts), len(byIP))
+	if ipv6 > 0 {
+		fmt.Printf("[*] Skipping %d IPv6 addresses — only IPv4 is scanned\n", ipv6)
+	}
+
+	// Hosts in scope may resolve to IPs the scope excludes.
+	var excluded int
+	for ip := range byIP {
+		if s.IsExcluded(ip) {
+			delete(byIP, ip)
+			excluded++
+		}
+	}
+	if excluded > 0 {
+		fmt.Printf("[*] Skipping %d IPs excluded by scope\n", excluded)
+	}
+
+	// ── Step 3: CDN/WAF IPs ───────────────────────────────────────────
+	// A CDN edge answers on the same ports for every customer: scanning it
+	// says nothing about the target.
+	cdn := checkCDN(byIP)
+	var targets []string
+	var skipped, skippedHosts int
+	for ip := range byIP {
+		if _, ok := cdn[ip]; ok && !opts.IncludeCDN {
+			skipped++
+			skippedHosts += len(byIP[ip])
+			continue
+		}
+		targets = append(targets, ip)
+	}
+	sort.Strings(targets)
+	if skipped > 0 {
+		fmt.Printf("[*] Skipping %d CDN/WAF IPs (%d hosts) — use --include-cdn to scan them\n", skipped, skippedHosts)
+	}
+	if len(targets) == 0 {
+		fmt.Println("[!] No IPs left to scan")
+		return nil
+	}
+
+	// ── Step 4: naabu ─────────────────────────────────────────────────
+	scanType := naabu_runner.ConnectScan
+	if opts.SynScan {
+		if naabu_privileges.IsPrivileged {
+			scanType = naabu_runner.SynScan
+		} else {
+			fmt.Println("[!] SYN scan needs root/CAP_NET_RAW — falling back to connect scan")
+		}
+	}
+	rate := opts.RateLimit
+	if rate <= 0 {
+		rate = defaultRate
+	}
+
+	portsLabel := portList
+	if topPorts != "" {
+		portsLabel = "top " + topPorts
+	}
+	fmt.Printf("[*] Scanning %d IPs with naabu (%s, %s scan, %d pps)...\n",
+		len(targets), portsLabel, scanName(scanType), rate)
+
+	var mu sync.Mutex
+	var count int64
+	emit := func(r portResult) {
+		atomic.AddInt64(&count, 1)
+		mu.Lock()
+		defer mu.Unlock()
+		if textFile == nil && jsonFile == nil {
+			fmt.Println(r.summary())
+		}
+		if textFile != nil {
+			fmt.Fprintln(textFile, r.summary())
+		}
+		if jsonFile != nil {
+			if js, jErr := json.Marshal(r); jErr == nil {
+				fmt.Fprintln(jsonFile, string(js))
+			}
+		}
+	}
+
+	var openPorts int64
+	options := &naabu_runner.Options{
+		Host:               goflags.StringSlice(targets),
+		TopPorts:           topPorts,
+		Ports:              portList,
+		ScanType:           scanType,
+		Rate:               rate,
+		Threads:            25,
+		Retries:            2,
+		Timeout:            3 * time.Second,
+		Silent:             true,
+		DisableStdout:      true,
+		NoColor:            true,
+		DisableUpdateCheck: true,
+		OnResult: func(hr *naabu_result.HostResult) {
+			ip := hr.IP
+			if ip == "" {
+				ip = hr.Host
+			}
+			cdnName, onCDN := cdn[ip]
+			for _, p := range hr.Ports {
+				atomic.AddInt64(&openPorts, 1)
+				// Map the port back to every hostname on the IP.
+				for _, host := range byIP[ip] {
+					emit(portResult{
+						Host:     host,
+						IP:       ip,
+						Port:     p.Port,
+						Protocol: p.Protocol.String(),
+						CDN:      onCDN,
+						CDNName:  cdnName,
+					})
+				}
+			}
+		},
+	}
+
+	runner, err := naabu_runner.NewRunner(options)
+	if err != nil {
+		return fmt.Errorf("could not create naabu runner: %w", err)
+	}
+	defer runner.Close()
+
+	if err := runner.RunEnumeration(context.Background()); err != nil {
+		fmt.Printf("[!] Naabu scan error: %s\n", err)
+	}
+
+	// ── Summary ───────────────────────────────────────────────────────
+	if opts.TextFile != "" {
+		fmt.Printf("[+] Text results saved to: %s\n", opts.TextFile)
+	}
+	if opts.JSONFile != "" {
+		fmt.Printf("[+] JSON results saved to: %s\n", opts.JSONFile)
+	}
+	fmt.Printf("[+] Workflow 'ports' completed — %d open ports on %d IPs, %d host:port results\n",
+		atomic.LoadInt64(&openPorts), len(targets), atomic.LoadInt64(&count))
+	return nil
+}
+
+// parsePorts turns the --ports value into naabu's top-ports or ports
+// option: "top-100"/"100", "top-1000"/"1000", "full"/"-" (1-65535) or a
+// list of ports and ranges ("80,443,8000-8100").
+func parsePorts(spec string) (topPorts, list string, err error) {
+	spec = strings.ToLower(strings.TrimSpace(spec))
+	if spec == "" {
+		spec = defaultPorts
+	}
+	switch strings.TrimPrefix(spec, "top-") {
+	case "100":
+		return "100", "", nil
+	case "1000":
+		return "1000", "", nil
+	case "full", "all", "-":
+		return "full", "", nil
+	}
+	for _, part := range strings.Split(spec, ",") {
+		if strings.Trim(strings.TrimSpace(part), "0123456789-") != "" {
+			return "", "", fmt.Errorf("invalid port list %q: use top-100, top-1000, full or ports/ranges like 80,443,8000-8100", spec)
+		}
+	}
+	return "", spec, nil
+}
+
+func scanName(scanType string) string {
+	if scanType == naabu_runner.SynScan {
+		return "SYN"
+	}
+	return "connect"
+}
+
+// resolveAll looks up the addresses of every host and groups the hosts by
+// IPv4 address. IPv6 addresses are counted, not scanned.
+func resolveAll(hosts []string, resolvers []string, concurrency int) (map[string][]string, int, error) {
+	client, err := brute.NewClient(resolvers)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	fmt.Printf("[*] Resolving %d hosts...\n", len(hosts))
+	byIP := map[string][]string{}
+	ipv6 := map[string]bool{}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+
+	for _, host := range hosts {
+		wg.Add(1)
+		go func(h string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			ips, err := client.Lookup(h)
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for _, ip := range ips {
+				if net.ParseIP(ip).To4() != nil {
+					byIP[ip] = appendUnique(byIP[ip], h)
+				} else {
+					ipv6[ip] = true
+				}
+			}
+		}(host)
+	}
+	wg.Wait()
+
+	for ip := range byIP {
+		sort.Strings(byIP[ip])
+	}
+	return byIP, len(ipv6), nil
+}
+
+// checkCDN returns the IPs that belong to a CDN or WAF, with the provider.
+func checkCDN(byIP map[string][]string) map[string]string {
+	client := cdncheck.New()
+	out := map[string]string{}
+	for ip := range byIP {
+		matched, provider, itemType, err := client.Check(net.ParseIP(ip))
+		if err != nil || !matched {
+			continue
+		}
+		if itemType == "cdn" || itemType == "waf" {
+			out[ip] = provider
+		}
+	}
+	return out
+}
+
+// runBrute adds brute-forced names under the wildcard roots.
+func runBrute(domain string, s *scope.Scope, opts workflows.OutputOptions) []string {
+	fmt.Println("[*] Brute-forcing subdomains with dnsx...")
+	var mu sync.Mutex
+	var hosts []string
+	stats, err := brute.Run(s.WildcardRoots(domain), s, brute.Options{
+		Wordlist:  opts.Wordlist,
+		Resolvers: opts.Resolvers,
+	}, func(r brute.Result) {
+		mu.Lock()
+		hosts = append(hosts, r.Subdomain)
+		mu.Unlock()
+	})
+	if err != nil {
+		fmt.Printf("[!] DNS brute-force failed: %v\n", err)
+		return nil
+	}
+	fmt.Printf("[+] Brute-force found %d subdomains (%d wildcard answers dropped)\n", stats.Found, stats.Wildcard)
+	return hosts
+}
+
+func dedup(hosts []string) []string {
+	seen := make(map[string]bool, len(hosts))
+	out := hosts[:0]
+	for _, h := range hosts {
+		h = strings.ToLower(strings.TrimSpace(h))
+		if h != "" && !seen[h] {
+			seen[h] = true
+			out = append(out, h)
+		}
+	}
+	return out
+}
+
+func appendUnique(slice []string, item string) []string {
+	for _, s := range slice {
+		if s == item {
+			return slice
+		}
+	}
+	return append(slice, item)
+}
@@ -2,7 +2,6 @@ package recon
 
 import (
 	"context"
-	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -11,13 +10,13 @@ import (
 	"sync"
 	"sync/atomic"
 
+	"github.com/FOUEN/narmol/internal/brute"
+	"github.com/FOUEN/narmol/internal/gauconf"
 	"github.com/FOUEN/narmol/internal/scope"
+	"github.com/FOUEN/narmol/internal/urlclass"
 	"github.com/FOUEN/narmol/internal/workflows"
 
-	mapset "github.com/deckarep/golang-set/v2"
-	gau_providers "github.com/lc/gau/v2/pkg/providers"
 	gau_runner "github.com/lc/gau/v2/runner"
-	"github.com/valyala/fasthttp"
 
 	"github.com/projectdiscovery/goflags"
 	"github.com/projectdiscovery/subfinder/v2/pkg/resolve"
@@ -33,6 +32,8 @@ func init() {
 // - If scope is exact domain (example.com): runs only gau.
 // - If scope has IPs/CIDRs: passes them through as known targets.
 // This workflow NEVER touches the target directly — only external data sources.
+// With --permute, alterations of discovered names are resolved through DNS
+// resolvers (opt-in, as it queries the target's name servers).
 type ReconWorkflow struct{}
 
 func (w *ReconWorkflow) Name() string {
@@ -118,11 +119,16 @@ func (w *ReconWorkflow) Run(domain string, s *scope.Scope, opts workflows.Output
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		w.runGau(domain, s, emitUnique, &urlCount)
+		w.runGau(domain, s, opts.Gau, emitUnique, &urlCount)
 	}()
 
 	wg.Wait()
 
+	// ── Step 3: Permutations (--permute) ──────────────────────────────
+	if opts.Permute && len(subs) > 0 {
+		w.runPermutations(domain, subs, s, opts, emitUnique, &subdomainCount)
+	}
+
 	// ── Summary ───────────────────────────────────────────────────────
 	subCount := atomic.LoadInt64(&subdomainCount)
 	urls := atomic.LoadInt64(&urlCount)
@@ -189,6 +195,27 @@ func (w *ReconWorkflow) runSubfinder(domain string, s *scope.Scope, emitUnique f
 	return hosts
 }
 
+// runPermutations resolves alterations of the discovered subdomains and
+// emits the ones that exist.
+func (w *ReconWorkflow) runPermutations(domain string, subs []string, s *scope.Scope, opts workflows.OutputOptions, emitUnique func(reconResult) bool, count *int64) {
+	candidates := brute.Permutations(subs, s.WildcardRoots(domain), s, opts.PermuteMax)
+	fmt.Printf("[*] Resolving %d permutations of discovered subdomains...\n", len(candidates))
+
+	stats, err := brute.Resolve(candidates, brute.Options{
+		Resolvers: opts.Resolvers,
+		RateLimit: opts.RateLimit,
+	}, func(r brute.Result) {
+		if emitUnique(reconResult{Type: "subdomain", Value: r.Subdomain, Source: "permutation", Domain: domain}) {
+			atomic.AddInt64(count, 1)
+		}
+	})
+	if err != nil {
+		fmt.Printf("[!] Permutation resolution failed: %s\n", err)
+		return
+	}
+	fmt.Printf("[+] Permutations: %d resolved (%d wildcard answers dropped)\n", stats.Found, stats.Wildcard)
+}
+
 // runSubfinderRecursive takes already-discovered subdomains and feeds them back
 // to subfinder to find deeper subdomain levels (e.g. sub.sub.example.com).
 func (w *ReconWorkflow) runSubfinderRecursive(seeds []string, s *scope.Scope, emitUnique func(reconResult) bool, count *int64) {
@@ -242,28 +269,13 @@ func (w *ReconWorkflow) runSubfinderRecursive(seeds []string, s *scope.Scope, em
 	fmt.Printf("[+] Recursive subfinder found %d new subdomains\n", newFound)
 }
 
-// runGau collects historical URLs from Wayback Machine, Common Crawl, OTX, URLScan.
-func (w *ReconWorkflow) runGau(domain string, s *scope.Scope, emitUnique func(reconResult) bool, count *int64) {
-	fmt.Printf("[*] Running gau on %s...\n", domain)
-
-	config := &gau_providers.Config{
-		Threads:           5,
-		Timeout:           45,
-		MaxRetries:        3,
-		IncludeSubdomains: true,
-		RemoveParameters:  false,
-		Client: &fasthttp.Client{
-			TLSConfig: &tls.Config{
-				InsecureSkipVerify: true,
-			},
-		},
-		Blacklist: mapset.NewThreadUnsafeSet(""),
-	}
-
-	providerNames := []string{"wayback", "otx", "urlscan"}
+// runGau collects historical URLs from the selected gau providers (Wayback
+// Machine, OTX, URLScan by default; Common Crawl on request).
+func (w *ReconWorkflow) runGau(domain string, s *scope.Scope, opts gauconf.Options, emitUnique func(reconResult) bool, count *int64) {
+	fmt.Printf("[*] Running gau on %s (%s)...\n", domain, opts.Describe())
 
 	gau := &gau_runner.Runner{}
-	if err := gau.Init(config, providerNames, gau_providers.Filters{}); err != nil {
+	if err := gau.Init(opts.Config(), opts.ProviderNames(), opts.Filters()); err != nil {
 		fmt.Printf("[!] Could not initialize gau: %s\n", err)
 		return
 	}
@@ -284,6 +296,7 @@ func (w *ReconWorkflow) runGau(domain string, s *scope.Scope, emitUnique func(re
 	}()
 
 	// Collect results in background
+	tally := map[string]int{}
 	var wg sync.WaitGroup
 	wg.Add(1)
 	go func() {
@@ -293,12 +306,16 @@ func (w *ReconWorkflow) runGau(domain string, s *scope.Scope, emitUnique func(re
 			if urlStr == "" {
 				continue
 			}
-			// Scope filter the URL
-			if !s.IsInScope(urlStr) {
+			// Scope filter the URL, then drop blacklisted extensions
+			if !s.IsInScope(urlStr) || !opts.Keep(urlStr) {
 				continue
 			}
-			if emitUnique(reconResult{Type: "url", Value: urlStr, Source: "gau", Domain: domain}) {
+			categories := urlclass.Categories(urlclass.Classify(urlStr))
+			if emitUnique(reconResult{Type: "url", Value: urlStr, Source: "gau", Domain: domain, Categories: categories}) {
 				atomic.AddInt64(count, 1)
+				for _, c := range categories {
+					tally[c]++
+				}
 			}
 		}
 	}()
@@ -309,12 +326,24 @@ func (w *ReconWorkflow) runGau(domain string, s *scope.Scope, emitUnique func(re
 	wg.Wait()
 
 	fmt.Printf("[+] Gau collected %d unique URLs for %s\n", atomic.LoadInt64(count), domain)
+	var interesting []string
+	for _, c := range urlclass.Order {
+		if tally[c] > 0 {
+			interesting = append(interesting, fmt.Sprintf("%s %d", c, tally[c]))
+		}
+	}
+	if len(interesting) > 0 {
+		fmt.Printf("[+] Interesting URLs: %s (use the urls workflow to re-check them)\n", strings.Join(interesting, ", "))
+	}
 }
 
 // reconResult represents a single finding from the recon workflow.
 type reconResult struct {
 	Type   string `json:"type"`   // "subdomain", "url", "ip"
 	Value  string `json:"value"`  // the actual subdomain, URL, or IP
-	Source string `json:"source"` // "subfinder", "subfinder-recursive", "gau", "scope"
+	Source string `json:"source"` // "subfinder", "subfinder-recursive", "permutation", "gau", "scope"
 	Domain string `json:"domain"` // parent domain this was found for
+
+	// Categories tags interesting URLs (see internal/urlclass).
+	Categories []string `json:"categories,omitempty"`
 }
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"sort"
 
+	"github.com/FOUEN/narmol/internal/auth"
+	"github.com/FOUEN/narmol/internal/gauconf"
 	"github.com/FOUEN/narmol/internal/scope"
 )
 
@@ -11,6 +13,81 @@ import (
 type OutputOptions struct {
 	TextFile string
 	JSONFile string
+
+	// EvidenceDir, when set, receives one file per finding with the raw
+	// request/response that proves it. EvidenceMaxBytes caps each section
+	// (0 = evidence.DefaultMaxBytes).
+	EvidenceDir      string
+	EvidenceMaxBytes int
+
+	// ArtifactDir receives files referenced by findings that are not
+	// evidence (GraphQL schemas): EvidenceDir when set, else the directory
+	// of the report files, else the working directory.
+	ArtifactDir string
+
+	// Brute adds active DNS brute-forcing to subdomains, active, takeover
+	// and ports.
+	// Wordlist, Resolvers and RateLimit tune it, the dnsbrute workflow and
+	// permutation resolution (empty = built-in wordlist, dnsx default
+	// resolvers, default rate).
+	Brute     bool
+	Wordlist  string
+	Resolvers []string
+	RateLimit int
+
+	// Permute adds resolved subdomain permutations to recon (subdomains and
+	// full always run them). PermuteMax caps the generated candidates
+	// (0 = brute.DefaultMaxPermutations).
+	Permute    bool
+	PermuteMax int
+
+	// Concurrency overrides the default number of parallel workers of
+	// workflows that support it (0 = workflow default).
+	Concurrency int
+
+	// Extensions, Recursion and the Filter* lists tune the fuzz workflow,
+	// which also reads Wordlist (path list) and RateLimit (requests per
+	// second per host).
+	Extensions   []string
+	Recursion    int
+	FilterStatus []int
+	FilterSize   []int
+	FilterWords  []int
+
+	// WAFBackoff makes active workflows slow down on hosts where a WAF was
+	// detected: lower rate limit and no noisy nuclei tags (see internal/waf).
+	WAFBackoff bool
+
+	// Keywords are extra base names (brands, products) for the buckets
+	// workflow. BucketEndpoints overrides its provider endpoint templates
+	// (provider → URL, see buckets.DefaultEndpoints), e.g. to point S3 at a
+	// local stand-in. BucketMax caps the candidate names
+	// (0 = buckets.DefaultMaxNames).
+	Keywords        []string
+	BucketEndpoints map[string]string
+	BucketMax       int
+
+	// Ports, SynScan and IncludeCDN tune the ports workflow: port set
+	// ("top-100", "top-1000", "full" or a list like "80,443,8000-8100";
+	// empty = top-1000), SYN scan when privileged, and scanning IPs of
+	// CDN/WAF providers (skipped by default). RateLimit is packets per
+	// second there.
+	Ports      string
+	SynScan    bool
+	IncludeCDN bool
+
+	// Gau configures historical URL collection (providers, date range,
+	// filters, extension blacklist, urlscan key) for every workflow that
+	// runs gau.
+	Gau gauconf.Options
+
+	// Headless makes crawl use katana's headless (Chrome) engine when a
+	// local browser is available, with XHR capture and form filling.
+	Headless bool
+
+	// Auth is the session web, full and crawl scan with (headers, cookies,
+	// bearer token or form login), sent only to its hosts.
+	Auth auth.Options
 }
 
 // Workflow defines the interface that all narmol workflows must implement.
@@ -23,6 +100,14 @@ type Workflow interface {
 	Run(domain string, s *scope.Scope, opts OutputOptions) error
 }
 
+// IPWorkflow is implemented by workflows that run once per IP/CIDR scope
+// rule instead of once per domain. Run then receives the rule ("10.0.0.0/24")
+// as its target.
+type IPWorkflow interface {
+	Workflow
+	TargetsIPs() bool
+}
+
 // registry holds all registered workflows.
 var registry = map[string]Workflow{}
 
@@ -249,6 +249,7 @@ func resultToSecret(r detectors.ResultWithMetadata, source, target string) Secre
 		Source:       source,
 		Target:       target,
 		SourceName:   r.SourceName,
+		File:         r.SourceMetadata.GetFilesystem().GetFile(),
 		ExtraData:    r.ExtraData,
 	}
 }
@@ -281,6 +282,7 @@ type SecretResult struct {
 	Source       string            `json:"source"`
 	Target       string            `json:"target"`
 	SourceName   string            `json:"source_name"`
+	File         string            `json:"file,omitempty"` // file the secret was found in (filesystem scans)
 	ExtraData    map[string]string `json:"extra_data,omitempty"`
 }
 
@@ -10,6 +10,7 @@ import (
 	"sync"
 	"sync/atomic"
 
+	"github.com/FOUEN/narmol/internal/brute"
 	"github.com/FOUEN/narmol/internal/scope"
 	"github.com/FOUEN/narmol/internal/workflows"
 
@@ -25,7 +26,8 @@ func init() {
 }
 
 // SubdomainsWorkflow enumerates subdomains (passive + DNS resolution), no probing.
-// Pipeline: subfinder (recursive) → dnsx resolution → dedup + scope filter.
+// Pipeline: subfinder (recursive) [+ dnsx brute-force] → dnsx resolution →
+// permutations of discovered names (resolved) → dedup + scope filter.
 type SubdomainsWorkflow struct{}
 
 func (w *SubdomainsWorkflow) Name() string { return "subdomains" }
@@ -75,11 +77,13 @@ func (w *SubdomainsWorkflow) Run(domain string, s *scope.Scope, opts workflows.O
 	}
 
 	seen := &sync.Map{}
+	var total int64
 	emit := func(r subdomainResult) {
 		key := r.Subdomain
 		if _, loaded := seen.LoadOrStore(key, true); loaded {
 			return
 		}
+		atomic.AddInt64(&total, 1)
 		if textFile == nil && jsonFile == nil {
 			fmt.Println(r.summary())
 		}
@@ -97,12 +101,33 @@ func (w *SubdomainsWorkflow) Run(domain string, s *scope.Scope, opts workflows.O
 	fmt.Println("[*] Running subfinder (recursive)...")
 
 	allSubs := w.runSubfinderRecursive(domain, s)
+	fmt.Printf("[+] Subfinder found %d unique subdomains\n", len(allSubs))
+
+	// ── Step 1b: DNS brute-force (--brute) ────────────────────────────
+	var bruted []brute.Result
+	if opts.Brute {
+		fmt.Println("[*] Brute-forcing subdomains with dnsx...")
+		var mu sync.Mutex
+		stats, bErr := brute.Run(s.WildcardRoots(domain), s, brute.Options{
+			Wordlist:  opts.Wordlist,
+			Resolvers: opts.Resolvers,
+			RateLimit: opts.RateLimit,
+		}, func(r brute.Result) {
+			mu.Lock()
+			bruted = append(bruted, r)
+			mu.Unlock()
+		})
+		if bErr != nil {
+			fmt.Printf("[!] DNS brute-force failed: %v\n", bErr)
+		} else {
+			fmt.Printf("[+] Brute-force found %d subdomains (%d wildcard answers dropped)\n", stats.Found, stats.Wildcard)
+		}
+	}
 
-	if len(allSubs) == 0 {
+	if len(allSubs) == 0 && len(bruted) == 0 {
 		fmt.Println("[!] No subdomains found")
 		return nil
 	}
-	fmt.Printf("[+] Subfinder found %d unique subdomains\n", len(allSubs))
 
 	// ── Step 2: DNS resolution (dnsx) ─────────────────────────────────
 	fmt.Printf("[*] Resolving %d subdomains with dnsx...\n", len(allSubs))
@@ -110,6 +135,9 @@ func (w *SubdomainsWorkflow) Run(domain string, s *scope.Scope, opts workflows.O
 	dnsOpts := dnsx.DefaultOptions
 	dnsOpts.MaxRetries = 3
 	dnsOpts.QuestionTypes = []uint16{dns.TypeA, dns.TypeAAAA}
+	if len(opts.Resolvers) > 0 {
+		dnsOpts.BaseResolvers = opts.Resolvers
+	}
 
 	client, err := dnsx.New(dnsOpts)
 	if err != nil {
@@ -145,6 +173,28 @@ func (w *SubdomainsWorkflow) Run(domain string, s *scope.Scope, opts workflows.O
 		fmt.Printf("[+] DNS resolution: %d resolved, %d without records\n", resolved, failed)
 	}
 
+	// Brute-forced names are already resolved and wildcard-filtered.
+	known := append([]string{}, allSubs...)
+	for _, r := range bruted {
+		emit(subdomainResult{Subdomain: r.Subdomain, IPs: r.IPs, Source: "dnsbrute"})
+		known = append(known, r.Subdomain)
+	}
+
+	// ── Step 3: Permutations ──────────────────────────────────────────
+	candidates := brute.Permutations(known, s.WildcardRoots(domain), s, opts.PermuteMax)
+	fmt.Printf("[*] Resolving %d permutations of discovered names...\n", len(candidates))
+	stats, pErr := brute.Resolve(candidates, brute.Options{
+		Resolvers: opts.Resolvers,
+		RateLimit: opts.RateLimit,
+	}, func(r brute.Result) {
+		emit(subdomainResult{Subdomain: r.Subdomain, IPs: r.IPs, Source: "permutation"})
+	})
+	if pErr != nil {
+		fmt.Printf("[!] Permutation resolution failed: %v\n", pErr)
+	} else {
+		fmt.Printf("[+] Permutations: %d resolved (%d wildcard answers dropped)\n", stats.Found, stats.Wildcard)
+	}
+
 	// ── Summary ───────────────────────────────────────────────────────
 	if opts.TextFile != "" {
 		fmt.Printf("[+] Text results saved to: %s\n", opts.TextFile)
@@ -152,7 +202,7 @@ func (w *SubdomainsWorkflow) Run(domain string, s *scope.Scope, opts workflows.O
 	if opts.JSONFile != "" {
 		fmt.Printf("[+] JSON results saved to: %s\n", opts.JSONFile)
 	}
-	fmt.Printf("[+] Workflow 'subdomains' completed — %d subdomains\n", len(allSubs))
+	fmt.Printf("[+] Workflow 'subdomains' completed — %d subdomains\n", total)
 	return nil
 }
 
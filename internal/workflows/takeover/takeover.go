@@ -3,13 +3,16 @@ package takeover
 import (
 	"encoding/json"
 	"fmt"
-	"net"
 	"os"
 	"strings"
 	"sync"
 	"sync/atomic"
 
+	"github.com/FOUEN/narmol/internal/brute"
+	"github.com/FOUEN/narmol/internal/discover"
+	"github.com/FOUEN/narmol/internal/evidence"
 	"github.com/FOUEN/narmol/internal/scope"
+	tko "github.com/FOUEN/narmol/internal/takeover"
 	"github.com/FOUEN/narmol/internal/workflows"
 )
 
@@ -17,86 +20,37 @@ func init() {
 	workflows.Register(&TakeoverWorkflow{})
 }
 
-// TakeoverWorkflow checks subdomains for potential subdomain takeover.
-// Resolves CNAME records and checks if the target service is abandoned/claimable.
+// TakeoverWorkflow checks subdomains for subdomain takeover.
+// Pipeline: subfinder (wildcard scope) + dnsx brute-force (--brute) → full
+// CNAME chain per name → match against the service list (internal/takeover)
+// → confirm by HTTP body fingerprint or NXDOMAIN. CNAMEs to services that
+// are in use are not reported.
 type TakeoverWorkflow struct{}
 
 func (w *TakeoverWorkflow) Name() string { return "takeover" }
 
 func (w *TakeoverWorkflow) Description() string {
-	return "Subdomain takeover detection: resolve CNAMEs and check for abandoned services."
+	return "Subdomain takeover detection: enumerate subdomains, resolve CNAME chains and confirm abandoned services by HTTP fingerprint or NXDOMAIN."
 }
 
+const defaultConcurrency = 30
+
 // takeoverResult is the JSON output format.
 type takeoverResult struct {
-	Subdomain string `json:"subdomain"`
-	CNAME     string `json:"cname"`
-	Service   string `json:"service"`
-	Severity  string `json:"severity"`
-	Detail    string `json:"detail"`
+	Subdomain string   `json:"subdomain"`
+	CNAME     string   `json:"cname"`
+	Chain     []string `json:"chain,omitempty"` // every CNAME hop, in order
+	Service   string   `json:"service"`
+	Method    string   `json:"method"` // fingerprint, nxdomain or dangling
+	Severity  string   `json:"severity"`
+	Detail    string   `json:"detail"`
+	Evidence  string   `json:"evidence,omitempty"`
+
+	ev *evidence.Record
 }
 
 func (r takeoverResult) summary() string {
-	return fmt.Sprintf("[%s] %s → %s (%s) — %s", strings.ToUpper(r.Severity), r.Subdomain, r.CNAME, r.Service, r.Detail)
-}
-
-// vulnerableServices maps CNAME patterns to service names that may be vulnerable to takeover.
-var vulnerableServices = []struct {
-	Pattern string
-	Service string
-	Detail  string
-}{
-	// Cloud providers
-	{".s3.amazonaws.com", "AWS S3", "S3 bucket may be claimable"},
-	{".s3-website", "AWS S3 Website", "S3 website bucket may be claimable"},
-	{".elasticbeanstalk.com", "AWS Elastic Beanstalk", "Environment may be claimable"},
-	{".cloudfront.net", "AWS CloudFront", "Distribution may be claimable"},
-	// Azure
-	{".azurewebsites.net", "Azure App Service", "App may be claimable"},
-	{".cloudapp.azure.com", "Azure Cloud App", "Cloud app may be claimable"},
-	{".azurefd.net", "Azure Front Door", "Front door may be claimable"},
-	{".blob.core.windows.net", "Azure Blob", "Blob storage may be claimable"},
-	{".trafficmanager.net", "Azure Traffic Manager", "Traffic manager may be claimable"},
-	{".azure-api.net", "Azure API Management", "API management may be claimable"},
-	// Google
-	{".storage.googleapis.com", "Google Cloud Storage", "Bucket may be claimable"},
-	{".appspot.com", "Google App Engine", "App may be claimable"},
-	// Hosting / PaaS
-	{".herokuapp.com", "Heroku", "Heroku app may be claimable"},
-	{".herokudns.com", "Heroku DNS", "Heroku DNS may be claimable"},
-	{".github.io", "GitHub Pages", "GitHub Pages may be claimable"},
-	{".netlify.app", "Netlify", "Netlify site may be claimable"},
-	{".netlify.com", "Netlify", "Netlify site may be claimable"},
-	{".vercel.app", "Vercel", "Vercel deployment may be claimable"},
-	{".now.sh", "Vercel (legacy)", "Vercel deployment may be claimable"},
-	{".surge.sh", "Surge.sh", "Surge site may be claimable"},
-	{".firebaseapp.com", "Firebase", "Firebase app may be claimable"},
-	{".web.app", "Firebase Hosting", "Firebase hosting may be claimable"},
-	{".fly.dev", "Fly.io", "Fly app may be claimable"},
-	// CDN
-	{".fastly.net", "Fastly", "Fastly service may be claimable"},
-	{".ghost.io", "Ghost", "Ghost blog may be claimable"},
-	{".myshopify.com", "Shopify", "Shopify store may be claimable"},
-	{".pantheonsite.io", "Pantheon", "Pantheon site may be claimable"},
-	{".zendesk.com", "Zendesk", "Zendesk instance may be claimable"},
-	{".teamwork.com", "Teamwork", "Teamwork instance may be claimable"},
-	{".helpjuice.com", "Helpjuice", "Helpjuice instance may be claimable"},
-	{".helpscoutdocs.com", "HelpScout", "HelpScout docs may be claimable"},
-	{".statuspage.io", "Statuspage", "Statuspage may be claimable"},
-	{".uservoice.com", "UserVoice", "UserVoice instance may be claimable"},
-	{".freshdesk.com", "Freshdesk", "Freshdesk instance may be claimable"},
-	// Buckets / Storage
-	{".digitaloceanspaces.com", "DigitalOcean Spaces", "Space may be claimable"},
-	{".backblazeb2.com", "Backblaze B2", "Bucket may be claimable"},
-	// Misc
-	{".wordpress.com", "WordPress.com", "WordPress site may be claimable"},
-	{".tumblr.com", "Tumblr", "Tumblr blog may be claimable"},
-	{".cargocollective.com", "Cargo", "Cargo site may be claimable"},
-	{".bitbucket.io", "Bitbucket", "Bitbucket pages may be claimable"},
-	{".readme.io", "ReadMe", "ReadMe docs may be claimable"},
-	{".tictail.com", "Tictail", "Tictail store may be claimable"},
-	{".ngrok.io", "ngrok", "ngrok tunnel may be claimable"},
-	{".unbouncepages.com", "Unbounce", "Unbounce page may be claimable"},
+	return fmt.Sprintf("[%s] %s → %s (%s) — %s", strings.ToUpper(r.Severity), r.Subdomain, strings.Join(r.Chain, " → "), r.Service, r.Detail)
 }
 
 func (w *TakeoverWorkflow) Run(domain string, s *scope.Scope, opts workflows.OutputOptions) error {
@@ -104,7 +58,15 @@ func (w *TakeoverWorkflow) Run(domain string, s *scope.Scope, opts workflows.Out
 		return fmt.Errorf("domain %s is not in scope", domain)
 	}
 
+	// ── Step 1: Subdomains ────────────────────────────────────────────
 	hosts := []string{domain}
+	if s.HasWildcard(domain) {
+		hosts = append(hosts, discover.Subdomains(domain, s)...)
+		if opts.Brute {
+			hosts = append(hosts, runBrute(domain, s, opts)...)
+		}
+	}
+	hosts = dedup(hosts)
 
 	// ── Output files ──────────────────────────────────────────────────
 	var textFile, jsonFile *os.File
@@ -124,11 +86,49 @@ func (w *TakeoverWorkflow) Run(domain string, s *scope.Scope, opts workflows.Out
 		defer jsonFile.Close()
 	}
 
-	fmt.Printf("[*] Checking %d hosts for subdomain takeover...\n", len(hosts))
+	store, err := evidence.NewStore(opts.EvidenceDir, opts.EvidenceMaxBytes)
+	if err != nil {
+		return err
+	}
+
+	var mu sync.Mutex
+	emit := func(r takeoverResult) {
+		r.Evidence = store.Save("takeover", r.Subdomain, r.Detail, r.ev)
+		mu.Lock()
+		defer mu.Unlock()
+		if textFile == nil && jsonFile == nil {
+			fmt.Println(r.summary())
+		}
+		if textFile != nil {
+			fmt.Fprintln(textFile, r.summary())
+		}
+		if jsonFile != nil {
+			if js, jErr := json.Marshal(r); jErr == nil {
+				fmt.Fprintln(jsonFile, string(js))
+			}
+		}
+	}
+
+	// ── Step 2: CNAME chains + confirmation ───────────────────────────
+	services, source, err := tko.Load()
+	if err != nil {
+		return err
+	}
+	checker, err := tko.NewChecker(services, opts.Resolvers)
+	if err != nil {
+		return err
+	}
+	checker.Scope = s
+	fmt.Printf("[*] Checking %d hosts for subdomain takeover (%d services, %s)...\n", len(hosts), len(services), source)
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
 
 	var count int64
 	var wg sync.WaitGroup
-	sem := make(chan struct{}, 30)
+	sem := make(chan struct{}, concurrency)
 
 	for _, host := range hosts {
 		wg.Add(1)
@@ -137,55 +137,21 @@ func (w *TakeoverWorkflow) Run(domain string, s *scope.Scope, opts workflows.Out
 			sem <- struct{}{}
 			defer func() { <-sem }()
 
-			// Strip scheme if present
-			hostname := h
-			if idx := strings.Index(hostname, "://"); idx != -1 {
-				hostname = hostname[idx+3:]
-			}
-			hostname = strings.TrimRight(hostname, "/")
-
-			// Resolve CNAME
-			cname, err := net.LookupCNAME(hostname)
-			if err != nil || cname == "" || cname == hostname+"." {
-				return // no CNAME or self-referencing
-			}
-			cname = strings.TrimRight(cname, ".")
-
-			// Check if CNAME points to a vulnerable service
-			for _, svc := range vulnerableServices {
-				if strings.Contains(cname, svc.Pattern) || strings.HasSuffix(cname, svc.Pattern) {
-					// Verify: check if the CNAME resolves (NXDOMAIN = likely takeover)
-					_, lookupErr := net.LookupHost(cname)
-					severity := "medium"
-					detail := svc.Detail
-					if lookupErr != nil {
-						severity = "high"
-						detail = svc.Detail + " (NXDOMAIN — strong indicator)"
-					}
-
-					result := takeoverResult{
-						Subdomain: hostname,
-						CNAME:     cname,
-						Service:   svc.Service,
-						Severity:  severity,
-						Detail:    detail,
-					}
-					atomic.AddInt64(&count, 1)
-
-					if textFile == nil && jsonFile == nil {
-						fmt.Println(result.summary())
-					}
-					if textFile != nil {
-						fmt.Fprintln(textFile, result.summary())
-					}
-					if jsonFile != nil {
-						if js, jErr := json.Marshal(result); jErr == nil {
-							fmt.Fprintln(jsonFile, string(js))
-						}
-					}
-					break
-				}
+			res, ok := checker.Check(h)
+			if !ok {
+				return
 			}
+			atomic.AddInt64(&count, 1)
+			emit(takeoverResult{
+				Subdomain: res.Host,
+				CNAME:     res.CNAME,
+				Chain:     res.Chain,
+				Service:   res.Service,
+				Method:    res.Method,
+				Severity:  res.Severity,
+				Detail:    res.Detail,
+				ev:        res.Evidence,
+			})
 		}(host)
 	}
 
@@ -199,6 +165,42 @@ func (w *TakeoverWorkflow) Run(domain string, s *scope.Scope, opts workflows.Out
 	if opts.JSONFile != "" {
 		fmt.Printf("[+] JSON results saved to: %s\n", opts.JSONFile)
 	}
-	fmt.Printf("[+] Workflow 'takeover' completed — %d potential takeovers found\n", total)
+	fmt.Printf("[+] Workflow 'takeover' completed — %d takeovers found\n", total)
 	return nil
 }
+
+// runBrute adds brute-forced names under the wildcard roots. Only names
+// that resolve are found this way; dangling CNAMEs come from subfinder.
+func runBrute(domain string, s *scope.Scope, opts workflows.OutputOptions) []string {
+	fmt.Println("[*] Brute-forcing subdomains with dnsx...")
+	var mu sync.Mutex
+	var hosts []string
+	stats, err := brute.Run(s.WildcardRoots(domain), s, brute.Options{
+		Wordlist:  opts.Wordlist,
+		Resolvers: opts.Resolvers,
+		RateLimit: opts.RateLimit,
+	}, func(r brute.Result) {
+		mu.Lock()
+		hosts = append(hosts, r.Subdomain)
+		mu.Unlock()
+	})
+	if err != nil {
+		fmt.Printf("[!] DNS brute-force failed: %v\n", err)
+		return nil
+	}
+	fmt.Printf("[+] Brute-force found %d subdomains (%d wildcard answers dropped)\n", stats.Found, stats.Wildcard)
+	return hosts
+}
+
+func dedup(hosts []string) []string {
+	seen := make(map[string]bool, len(hosts))
+	out := hosts[:0]
+	for _, h := range hosts {
+		h = strings.ToLower(strings.TrimSpace(h))
+		if h != "" && !seen[h] {
+			seen[h] = true
+			out = append(out, h)
+		}
+	}
+	return out
+}
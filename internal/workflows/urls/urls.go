@@ -2,21 +2,20 @@ package urls
 
 import (
 	"context"
-	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
 
+	"github.com/FOUEN/narmol/internal/gauconf"
 	"github.com/FOUEN/narmol/internal/scope"
+	"github.com/FOUEN/narmol/internal/urlclass"
 	"github.com/FOUEN/narmol/internal/workflows"
 
-	mapset "github.com/deckarep/golang-set/v2"
-	gau_providers "github.com/lc/gau/v2/pkg/providers"
 	gau_runner "github.com/lc/gau/v2/runner"
-	"github.com/valyala/fasthttp"
 
 	katana_standard "github.com/projectdiscovery/katana/pkg/engine/standard"
 	katana_output "github.com/projectdiscovery/katana/pkg/output"
@@ -28,19 +27,54 @@ func init() {
 }
 
 // URLsWorkflow collects URLs from historical sources (gau) + live crawling (katana).
-// Both run in parallel.
+// Both run in parallel. Every URL is tagged by category (internal/urlclass);
+// the interesting historical ones are then re-requested and reported grouped
+// by category with their current status.
 type URLsWorkflow struct{}
 
 func (w *URLsWorkflow) Name() string { return "urls" }
 
 func (w *URLsWorkflow) Description() string {
-	return "Collect URLs: historical (gau: Wayback, OTX, URLScan) + live crawl (katana). Parallel."
+	return "Collect URLs: historical (gau: Wayback, OTX, URLScan) + live crawl (katana). Interesting ones tagged and re-checked (query dropped on URLs with secrets or action params)."
 }
 
+// maxRecheck caps the historical URLs re-requested per domain.
+const maxRecheck = 2000
+
 // urlResult is the JSON output format.
 type urlResult struct {
-	URL    string `json:"url"`
-	Source string `json:"source"` // "gau", "katana"
+	URL        string   `json:"url"`
+	Source     string   `json:"source"`               // "gau", "katana"
+	Categories []string `json:"categories,omitempty"` // see internal/urlclass
+
+	tags []urlclass.Tag
+}
+
+// interestingResult is the JSON output format of the re-check: one line per
+// category an interesting historical URL falls into. Exposed files and
+// credentials still served (200) carry a severity.
+type interestingResult struct {
+	Category      string `json:"category"`
+	URL           string `json:"url"`
+	Requested     string `json:"requested,omitempty"` // set when the query was dropped (urlclass.Replay)
+	Match         string `json:"match"`
+	StatusCode    int    `json:"status_code"`
+	ContentLength int64  `json:"content_length"`
+	Live          bool   `json:"live"`
+	SoftNotFound  bool   `json:"soft_404,omitempty"`
+	Severity      string `json:"severity,omitempty"`
+	Detail        string `json:"detail,omitempty"`
+}
+
+func (r interestingResult) summary() string {
+	status := "dead"
+	if r.StatusCode > 0 {
+		status = fmt.Sprintf("%d", r.StatusCode)
+	}
+	if r.SoftNotFound {
+		status += " soft-404"
+	}
+	return fmt.Sprintf("[%s] %s (%s)", status, r.URL, r.Match)
 }
 
 func (w *URLsWorkflow) Run(domain string, s *scope.Scope, opts workflows.OutputOptions) error {
@@ -66,11 +100,20 @@ func (w *URLsWorkflow) Run(domain string, s *scope.Scope, opts workflows.OutputO
 		defer jsonFile.Close()
 	}
 
+	var mu sync.Mutex
+	var interesting []urlResult
 	seen := &sync.Map{}
 	emit := func(r urlResult) bool {
 		if _, loaded := seen.LoadOrStore(r.URL, true); loaded {
 			return false
 		}
+		r.tags = urlclass.Classify(r.URL)
+		r.Categories = urlclass.Categories(r.tags)
+		mu.Lock()
+		defer mu.Unlock()
+		if len(r.tags) > 0 && r.Source == "gau" {
+			interesting = append(interesting, r)
+		}
 		if textFile == nil && jsonFile == nil {
 			fmt.Println(r.URL)
 		}
@@ -92,7 +135,7 @@ func (w *URLsWorkflow) Run(domain string, s *scope.Scope, opts workflows.OutputO
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		gauCount = w.runGau(domain, s, emit)
+		gauCount = w.runGau(domain, s, opts.Gau, emit)
 	}()
 
 	// ── katana (live crawl) ───────────────────────────────────────────
@@ -104,6 +147,9 @@ func (w *URLsWorkflow) Run(domain string, s *scope.Scope, opts workflows.OutputO
 
 	wg.Wait()
 
+	// ── Interesting historical URLs: re-check, grouped by category ────
+	live := w.recheck(interesting, opts.Concurrency, textFile, jsonFile)
+
 	// ── Summary ───────────────────────────────────────────────────────
 	if opts.TextFile != "" {
 		fmt.Printf("[+] Text results saved to: %s\n", opts.TextFile)
@@ -111,29 +157,131 @@ func (w *URLsWorkflow) Run(domain string, s *scope.Scope, opts workflows.OutputO
 	if opts.JSONFile != "" {
 		fmt.Printf("[+] JSON results saved to: %s\n", opts.JSONFile)
 	}
-	fmt.Printf("[+] Workflow 'urls' completed — %d from gau, %d from katana\n", gauCount, katanaCount)
+	fmt.Printf("[+] Workflow 'urls' completed — %d from gau, %d from katana, %d/%d interesting historical URLs still live\n",
+		gauCount, katanaCount, live, len(interesting))
 	return nil
 }
 
-func (w *URLsWorkflow) runGau(domain string, s *scope.Scope, emit func(urlResult) bool) int64 {
-	fmt.Printf("[*] Running gau on %s...\n", domain)
-
-	config := &gau_providers.Config{
-		Threads:           5,
-		Timeout:           45,
-		MaxRetries:        3,
-		IncludeSubdomains: true,
-		RemoveParameters:  false,
-		Client: &fasthttp.Client{
-			TLSConfig: &tls.Config{InsecureSkipVerify: true},
-		},
-		Blacklist: mapset.NewThreadUnsafeSet(""),
+// recheck requests the interesting historical URLs again and writes them
+// grouped by category, live ones first. It returns how many are live.
+func (w *URLsWorkflow) recheck(interesting []urlResult, concurrency int, textFile, jsonFile *os.File) int {
+	if len(interesting) == 0 {
+		return 0
+	}
+	if len(interesting) > maxRecheck {
+		fmt.Printf("[!] %d interesting historical URLs, re-checking the first %d\n", len(interesting), maxRecheck)
+		interesting = interesting[:maxRecheck]
 	}
+	fmt.Printf("[*] Re-checking %d interesting historical URLs...\n", len(interesting))
+
+	urls := make([]string, len(interesting))
+	for i, r := range interesting {
+		urls[i] = r.URL
+	}
+	answers := urlclass.Recheck(urls, concurrency)
+
+	groups := map[string][]interestingResult{}
+	var live int
+	for i, r := range interesting {
+		a := answers[i]
+		if a.Live {
+			live++
+		}
+		for _, t := range r.tags {
+			res := interestingResult{
+				Category:      t.Category,
+				URL:           r.URL,
+				Match:         t.Match,
+				StatusCode:    a.StatusCode,
+				ContentLength: a.ContentLength,
+				Live:          a.Live,
+				SoftNotFound:  a.SoftNotFound,
+			}
+			if a.Requested != r.URL {
+				res.Requested = a.Requested
+			}
+			// A redirect or 403 on a backup path is usually the app's
+			// catch-all, not the file; so is a 200 that looks like the
+			// host's answer to a random path.
+			if a.Live && a.StatusCode == 200 && urlclass.Sensitive(t.Category) {
+				res.Severity = "medium"
+				res.Detail = fmt.Sprintf("Historical %s URL still served (HTTP %d, %s)", t.Category, a.StatusCode, t.Match)
+				if res.Requested != "" {
+					res.Detail += "; re-checked without the query string"
+				}
+			}
+			groups[t.Category] = append(groups[t.Category], res)
+		}
+	}
+
+	for _, category := range urlclass.Order {
+		group := groups[category]
+		if len(group) == 0 {
+			continue
+		}
+		sort.SliceStable(group, func(i, j int) bool { return group[i].Live && !group[j].Live })
+		var groupLive int
+		for _, r := range group {
+			if r.Live {
+				groupLive++
+			}
+		}
+		header := fmt.Sprintf("── %s (%d URLs, %d live) ──", category, len(group), groupLive)
+		if textFile == nil && jsonFile == nil {
+			fmt.Println(header)
+		}
+		if textFile != nil {
+			fmt.Fprintln(textFile, header)
+		}
+		for _, r := range group {
+			if textFile == nil && jsonFile == nil {
+				fmt.Println(r.summary())
+			}
+			if textFile != nil {
+				fmt.Fprintln(textFile, r.summary())
+			}
+			if jsonFile != nil {
+				if js, jErr := json.Marshal(r); jErr == nil {
+					fmt.Fprintln(jsonFile, string(js))
+				}
+			}
+		}
+	}
+	return live
+}
+
+// Collect runs gau (configured by gau) and katana in parallel on domain and
+// calls found once per unique in-scope URL. found may be called concurrently.
+// This is the public API for use by other workflows.
+func Collect(domain string, s *scope.Scope, gau gauconf.Options, found func(url, source string)) {
+	w := &URLsWorkflow{}
+	seen := &sync.Map{}
+	emit := func(r urlResult) bool {
+		if _, loaded := seen.LoadOrStore(r.URL, true); loaded {
+			return false
+		}
+		found(r.URL, r.Source)
+		return true
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		w.runGau(domain, s, gau, emit)
+	}()
+	go func() {
+		defer wg.Done()
+		w.runKatana(domain, s, emit)
+	}()
+	wg.Wait()
+}
 
-	providerNames := []string{"wayback", "otx", "urlscan"}
+func (w *URLsWorkflow) runGau(domain string, s *scope.Scope, opts gauconf.Options, emit func(urlResult) bool) int64 {
+	fmt.Printf("[*] Running gau on %s (%s)...\n", domain, opts.Describe())
 
 	gau := &gau_runner.Runner{}
-	if err := gau.Init(config, providerNames, gau_providers.Filters{}); err != nil {
+	if err := gau.Init(opts.Config(), opts.ProviderNames(), opts.Filters()); err != nil {
 		fmt.Printf("[!] Could not initialize gau: %s\n", err)
 		return 0
 	}
@@ -159,7 +307,7 @@ func (w *URLsWorkflow) runGau(domain string, s *scope.Scope, emit func(urlResult
 		defer wg.Done()
 		for urlStr := range results {
 			urlStr = strings.TrimSpace(urlStr)
-			if urlStr == "" || !s.IsInScope(urlStr) {
+			if urlStr == "" || !s.IsInScope(urlStr) || !opts.Keep(urlStr) {
 				continue
 			}
 			if emit(urlResult{URL: urlStr, Source: "gau"}) {
@@ -188,16 +336,17 @@ func (w *URLsWorkflow) runKatana(domain string, s *scope.Scope, emit func(urlRes
 	var count int64
 
 	katanaOpts := &katana_types.Options{
-		MaxDepth:    3,
-		FieldScope:  "rdn",
-		Concurrency: 10,
-		Parallelism: 10,
-		Timeout:     10,
-		RateLimit:   100,
-		Strategy:    "breadth-first",
-		KnownFiles:  "all",
-		NoColors:    true,
-		Silent:      true,
+		MaxDepth:     3,
+		BodyReadSize: 4 << 20, // katana reads no body when 0
+		FieldScope:   "rdn",
+		Concurrency:  10,
+		Parallelism:  10,
+		Timeout:      10,
+		RateLimit:    100,
+		Strategy:     "breadth-first",
+		KnownFiles:   "all",
+		NoColors:     true,
+		Silent:       true,
 		OnResult: func(result katana_output.Result) {
 			u := result.Request.URL
 			if u == "" || !s.IsInScope(u) {
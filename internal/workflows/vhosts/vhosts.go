@@ -0,0 +1,415 @@
+package vhosts
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/FOUEN/narmol/internal/brute"
+	"github.com/FOUEN/narmol/internal/discover"
+	"github.com/FOUEN/narmol/internal/scope"
+	"github.com/FOUEN/narmol/internal/simhash"
+	"github.com/FOUEN/narmol/internal/workflows"
+
+	"github.com/projectdiscovery/goflags"
+	httpx_runner "github.com/projectdiscovery/httpx/runner"
+)
+
+func init() {
+	workflows.Register(&VHostsWorkflow{})
+}
+
+// VHostsWorkflow finds virtual hosts served by live IPs but absent from DNS.
+// Pipeline: subfinder (wildcard scope) → httpx (IPs + certificate SANs) →
+// candidate names (known hosts, SANs, wordlist, permutations) → one request
+// per candidate Host header on every IP, compared against a random-name
+// baseline → distinct in-scope vhosts.
+type VHostsWorkflow struct{}
+
+func (w *VHostsWorkflow) Name() string { return "vhosts" }
+
+func (w *VHostsWorkflow) Description() string {
+	return "Virtual host discovery: Host header fuzzing on live IPs, compared against a baseline (status, length, title, simhash)."
+}
+
+const (
+	defaultConcurrency = 20
+	// defaultMaxPermutations is lower than brute.DefaultMaxPermutations:
+	// every candidate costs one request per IP, not one DNS query.
+	defaultMaxPermutations = 1000
+	// maxBody is how much of each response is read for the comparison.
+	maxBody = 512 * 1024
+)
+
+var titleRe = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+// vhostResult is the JSON output format: one name answering differently
+// from the baseline on one IP.
+type vhostResult struct {
+	Host       string `json:"host"`
+	IP         string `json:"ip"`
+	Port       string `json:"port"`
+	URL        string `json:"url"`
+	StatusCode int    `json:"status_code"`
+	Length     int    `json:"content_length"`
+	Title      string `json:"title,omitempty"`
+	Source     string `json:"source"` // subdomain, san, wordlist, permutation
+}
+
+func (r vhostResult) summary() string {
+	title := ""
+	if r.Title != "" {
+		title = " [" + r.Title + "]"
+	}
+	return fmt.Sprintf("%s @ %s:%s [%d] [%d bytes]%s (%s)", r.Host, r.IP, r.Port, r.StatusCode, r.Length, title, r.Source)
+}
+
+// endpoint is a live scheme://ip:port found by httpx. Names holds the hosts
+// whose DNS already points at it: they are not hidden and are not tested.
+type endpoint struct {
+	scheme, ip, port string
+	names            map[string]bool
+}
+
+// response is what a request looks like for the comparison.
+type response struct {
+	status   int
+	length   int
+	title    string
+	location string // Location header with the requested name masked
+	hash     uint64
+}
+
+func (w *VHostsWorkflow) Run(domain string, s *scope.Scope, opts workflows.OutputOptions) error {
+	if !s.IsInScope(domain) {
+		return fmt.Errorf("domain %s is not in scope", domain)
+	}
+
+	// ── Step 1: Known hosts ───────────────────────────────────────────
+	hosts := []string{domain}
+	if s.HasWildcard(domain) {
+		hosts = append(hosts, discover.Subdomains(domain, s)...)
+	}
+
+	// ── Step 2: httpx → live IPs + certificate SANs ───────────────────
+	fmt.Printf("[*] Probing %d hosts with httpx...\n", len(hosts))
+
+	endpoints := map[string]*endpoint{}
+	sans := map[string]bool{}
+	var cdnSkipped int
+	var mu sync.Mutex
+
+	hxOptions := &httpx_runner.Options{
+		InputTargetHost:    goflags.StringSlice(hosts),
+		Silent:             true,
+		DisableStdout:      true,
+		Threads:            50,
+		Timeout:            10,
+		DisableUpdateCheck: true,
+		DisableStdin:       true,
+		NoColor:            true,
+		RateLimit:          150,
+		Retries:            0,
+		HostMaxErrors:      30,
+		RandomAgent:        true,
+		OutputCDN:          "true",
+		TLSGrab:            true,
+		OnResult: func(r httpx_runner.Result) {
+			if r.Err != nil || r.HostIP == "" {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if r.TLSData != nil && r.TLSData.CertificateResponse != nil {
+				for _, n := range append([]string{r.TLSData.SubjectCN}, r.TLSData.SubjectAN...) {
+					sans[normalize(n)] = true
+				}
+			}
+			// CDN edges answer for thousands of unrelated names.
+			if r.CDN {
+				cdnSkipped++
+				return
+			}
+			key := r.Scheme + "://" + net.JoinHostPort(r.HostIP, r.Port)
+			ep, ok := endpoints[key]
+			if !ok {
+				ep = &endpoint{scheme: r.Scheme, ip: r.HostIP, port: r.Port, names: map[string]bool{}}
+				endpoints[key] = ep
+			}
+			ep.names[normalize(r.Input)] = true
+		},
+	}
+	if err := hxOptions.ValidateOptions(); err != nil {
+		return fmt.Errorf("httpx options validation failed: %w", err)
+	}
+	hxRunner, err := httpx_runner.New(hxOptions)
+	if err != nil {
+		return fmt.Errorf("could not create httpx runner: %w", err)
+	}
+	hxRunner.RunEnumeration()
+	hxRunner.Close()
+
+	if len(endpoints) == 0 {
+		return fmt.Errorf("no live non-CDN endpoints for %s (%d CDN endpoints skipped)", domain, cdnSkipped)
+	}
+	fmt.Printf("[+] %d live endpoints (%d CDN skipped), %d certificate names\n", len(endpoints), cdnSkipped, len(sans))
+
+	// ── Step 3: Candidate names ───────────────────────────────────────
+	candidates, sources := buildCandidates(domain, hosts, sans, s, opts)
+	if len(candidates) == 0 {
+		return fmt.Errorf("no in-scope vhost candidates for %s", domain)
+	}
+
+	// ── Output files ──────────────────────────────────────────────────
+	var textFile, jsonFile *os.File
+	if opts.TextFile != "" {
+		textFile, err = os.OpenFile(opts.TextFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open text output: %w", err)
+		}
+		defer textFile.Close()
+	}
+	if opts.JSONFile != "" {
+		jsonFile, err = os.OpenFile(opts.JSONFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open JSON output: %w", err)
+		}
+		defer jsonFile.Close()
+	}
+
+	var found int64
+	var outMu sync.Mutex
+	emit := func(r vhostResult) {
+		atomic.AddInt64(&found, 1)
+		outMu.Lock()
+		defer outMu.Unlock()
+		if textFile == nil && jsonFile == nil {
+			fmt.Println(r.summary())
+		}
+		if textFile != nil {
+			fmt.Fprintln(textFile, r.summary())
+		}
+		if jsonFile != nil {
+			if js, jErr := json.Marshal(r); jErr == nil {
+				fmt.Fprintln(jsonFile, string(js))
+			}
+		}
+	}
+
+	// ── Step 4: Host header fuzzing ───────────────────────────────────
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	fmt.Printf("[*] Testing %d candidates on %d endpoints (%d workers)...\n", len(candidates), len(endpoints), concurrency)
+
+	keys := make([]string, 0, len(endpoints))
+	for k := range endpoints {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		ep := endpoints[key]
+		client := endpointClient(ep)
+
+		// Two random names: when they disagree the endpoint is not stable
+		// enough to compare against.
+		base, err := fetch(client, ep, "narmol-"+randomHex()+"."+domain)
+		if err != nil {
+			fmt.Printf("[!] %s: baseline failed: %v\n", key, err)
+			continue
+		}
+		if again, err := fetch(client, ep, "narmol-"+randomHex()+"."+domain); err != nil || !same(base, again) {
+			fmt.Printf("[!] %s: unstable baseline, skipped\n", key)
+			continue
+		}
+
+		work := make(chan string)
+		var wg sync.WaitGroup
+		for i := 0; i < concurrency; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for host := range work {
+					resp, err := fetch(client, ep, host)
+					// 421 Misdirected Request: the server refuses the name.
+					if err != nil || resp.status == http.StatusMisdirectedRequest || same(base, resp) {
+						continue
+					}
+					emit(vhostResult{
+						Host:       host,
+						IP:         ep.ip,
+						Port:       ep.port,
+						URL:        ep.url(host),
+						StatusCode: resp.status,
+						Length:     resp.length,
+						Title:      resp.title,
+						Source:     sources[host],
+					})
+				}
+			}()
+		}
+		for _, host := range candidates {
+			if !ep.names[host] {
+				work <- host
+			}
+		}
+		close(work)
+		wg.Wait()
+	}
+
+	// ── Summary ───────────────────────────────────────────────────────
+	if opts.TextFile != "" {
+		fmt.Printf("[+] Text results saved to: %s\n", opts.TextFile)
+	}
+	if opts.JSONFile != "" {
+		fmt.Printf("[+] JSON results saved to: %s\n", opts.JSONFile)
+	}
+	fmt.Printf("[+] Workflow 'vhosts' completed — %d virtual hosts found\n", atomic.LoadInt64(&found))
+	return nil
+}
+
+// buildCandidates returns the in-scope names to try as Host headers and the
+// source of each: known subdomains, certificate SANs, wordlist labels under
+// the wildcard roots and permutations of everything known.
+func buildCandidates(domain string, hosts []string, sans map[string]bool, s *scope.Scope, opts workflows.OutputOptions) ([]string, map[string]string) {
+	sources := map[string]string{}
+	var out []string
+	add := func(host, source string) {
+		host = normalize(host)
+		if host == "" || sources[host] != "" || !s.IsInScope(host) {
+			return
+		}
+		sources[host] = source
+		out = append(out, host)
+	}
+
+	for _, h := range hosts {
+		add(h, "subdomain")
+	}
+	sanNames := make([]string, 0, len(sans))
+	for n := range sans {
+		sanNames = append(sanNames, n)
+	}
+	sort.Strings(sanNames)
+	for _, n := range sanNames {
+		add(n, "san")
+	}
+
+	roots := s.WildcardRoots(domain)
+	if words, err := brute.LoadWordlist(opts.Wordlist); err != nil {
+		fmt.Printf("[!] Wordlist: %v\n", err)
+	} else {
+		for _, root := range roots {
+			for _, word := range words {
+				add(word+"."+root, "wordlist")
+			}
+		}
+	}
+
+	max := opts.PermuteMax
+	if max <= 0 {
+		max = defaultMaxPermutations
+	}
+	for _, p := range brute.Permutations(out, roots, s, max) {
+		add(p, "permutation")
+	}
+	return out, sources
+}
+
+// url returns scheme://host[:port] for host on the endpoint. The port is
+// left out when it is the scheme's default, so the Host header is the bare
+// name the virtual host is configured for.
+func (ep *endpoint) url(host string) string {
+	if (ep.scheme == "http" && ep.port == "80") || (ep.scheme == "https" && ep.port == "443") {
+		return ep.scheme + "://" + host
+	}
+	return ep.scheme + "://" + net.JoinHostPort(host, ep.port)
+}
+
+// endpointClient returns a client whose connections all go to the
+// endpoint's IP, whatever the URL host: the candidate name then ends up in
+// both the Host header and the TLS SNI.
+func endpointClient(ep *endpoint) *http.Client {
+	addr := net.JoinHostPort(ep.ip, ep.port)
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	return &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig:     &tls.Config{InsecureSkipVerify: true},
+			MaxIdleConnsPerHost: 2,
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return dialer.DialContext(ctx, network, addr)
+			},
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse // a redirect is part of the answer
+		},
+	}
+}
+
+func fetch(client *http.Client, ep *endpoint, host string) (response, error) {
+	req, err := http.NewRequest("GET", ep.url(host)+"/", nil)
+	if err != nil {
+		return response{}, err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
+	resp, err := client.Do(req)
+	if err != nil {
+		return response{}, err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxBody))
+
+	r := response{status: resp.StatusCode, length: len(body), hash: simhash.Hash(string(body))}
+	if m := titleRe.FindSubmatch(body); m != nil {
+		r.title = strings.TrimSpace(html.UnescapeString(string(m[1])))
+	}
+	// Default vhosts often redirect to the requested name itself.
+	r.location = strings.ReplaceAll(resp.Header.Get("Location"), host, "{host}")
+	return r, nil
+}
+
+// same reports whether b is the baseline page a again: same status and
+// title (or redirect target), and a body of near-identical length or
+// content.
+func same(a, b response) bool {
+	if a.status != b.status || a.title != b.title || a.location != b.location {
+		return false
+	}
+	diff := a.length - b.length
+	if diff < 0 {
+		diff = -diff
+	}
+	if diff <= a.length/20 {
+		return true
+	}
+	return simhash.Distance(a.hash, b.hash) <= simhash.NearDuplicate
+}
+
+func normalize(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.TrimSuffix(name, ".")
+	return strings.TrimPrefix(name, "*.")
+}
+
+func randomHex() string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,216 @@
+package waf
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/FOUEN/narmol/internal/discover"
+	"github.com/FOUEN/narmol/internal/scope"
+	wafid "github.com/FOUEN/narmol/internal/waf"
+	"github.com/FOUEN/narmol/internal/workflows"
+
+	"github.com/projectdiscovery/goflags"
+	httpx_runner "github.com/projectdiscovery/httpx/runner"
+)
+
+func init() {
+	workflows.Register(&WAFWorkflow{})
+}
+
+// WAFWorkflow records which live hosts sit behind which WAF.
+// Pipeline: subfinder (wildcard scope) → httpx (CDN) → WAF fingerprint
+// (headers, cookies, block page, reaction to a malicious-looking probe).
+type WAFWorkflow struct{}
+
+func (w *WAFWorkflow) Name() string { return "waf" }
+
+func (w *WAFWorkflow) Description() string {
+	return "WAF/CDN detection: fingerprints the WAF in front of every live host (headers, cookies, block pages, probe)."
+}
+
+const defaultConcurrency = 20
+
+// wafResult is the JSON output format. The report reads the waf field as
+// an exposure hint for the host.
+type wafResult struct {
+	URL     string   `json:"url"`
+	WAF     string   `json:"waf"`
+	Signals []string `json:"signals"`
+	Blocked bool     `json:"blocked"`               // the probe was blocked
+	CDN     bool     `json:"cdn,omitempty"`         // behind a CDN (httpx)
+	CDNName string   `json:"cdn_name,omitempty"`    // CDN name (httpx)
+	Status  int      `json:"status_code,omitempty"` // status of the probe
+}
+
+func (r wafResult) summary() string {
+	line := fmt.Sprintf("%s [%s] %s", r.URL, r.WAF, strings.Join(r.Signals, ", "))
+	if r.CDNName != "" {
+		line += " (CDN: " + r.CDNName + ")"
+	}
+	return line
+}
+
+func (w *WAFWorkflow) Run(domain string, s *scope.Scope, opts workflows.OutputOptions) error {
+	if !s.IsInScope(domain) {
+		return fmt.Errorf("domain %s is not in scope", domain)
+	}
+
+	// ── Step 1: Hosts ─────────────────────────────────────────────────
+	hosts := []string{domain}
+	if s.HasWildcard(domain) {
+		hosts = append(hosts, discover.Subdomains(domain, s)...)
+	}
+
+	// ── Step 2: httpx → live base URLs ────────────────────────────────
+	bases, cdns := runHttpx(hosts, s)
+	if len(bases) == 0 {
+		return fmt.Errorf("no live hosts for %s", domain)
+	}
+
+	// ── Step 3: WAF fingerprint ───────────────────────────────────────
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	fmt.Printf("[*] Fingerprinting WAFs on %d hosts...\n", len(bases))
+	found := wafid.DetectAll(bases, concurrency)
+
+	// ── Output ────────────────────────────────────────────────────────
+	var textFile, jsonFile *os.File
+	var err error
+	if opts.TextFile != "" {
+		textFile, err = os.OpenFile(opts.TextFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open text output: %w", err)
+		}
+		defer textFile.Close()
+	}
+	if opts.JSONFile != "" {
+		jsonFile, err = os.OpenFile(opts.JSONFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open JSON output: %w", err)
+		}
+		defer jsonFile.Close()
+	}
+
+	byWAF := map[string]int{}
+	for _, base := range bases {
+		res, ok := found[base]
+		if !ok {
+			continue
+		}
+		byWAF[res.Name]++
+		r := wafResult{
+			URL:     base,
+			WAF:     res.Name,
+			Signals: res.Signals,
+			Blocked: res.Blocked,
+			Status:  res.Status,
+		}
+		if cdn, ok := cdns[base]; ok {
+			r.CDN = true
+			if cdn != "yes" {
+				r.CDNName = cdn
+			}
+		}
+		if textFile == nil && jsonFile == nil {
+			fmt.Println(r.summary())
+		}
+		if textFile != nil {
+			fmt.Fprintln(textFile, r.summary())
+		}
+		if jsonFile != nil {
+			if js, jErr := json.Marshal(r); jErr == nil {
+				fmt.Fprintln(jsonFile, string(js))
+			}
+		}
+	}
+
+	// ── Summary ───────────────────────────────────────────────────────
+	names := make([]string, 0, len(byWAF))
+	for n := range byWAF {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		fmt.Printf("[+] %s: %d hosts\n", n, byWAF[n])
+	}
+	if opts.TextFile != "" {
+		fmt.Printf("[+] Text results saved to: %s\n", opts.TextFile)
+	}
+	if opts.JSONFile != "" {
+		fmt.Printf("[+] JSON results saved to: %s\n", opts.JSONFile)
+	}
+	fmt.Printf("[+] Workflow 'waf' completed — %d of %d hosts behind a WAF\n", len(found), len(bases))
+	return nil
+}
+
+// runHttpx returns the live scheme://host[:port] bases among hosts and, for
+// those behind a CDN, its name ("yes" when httpx could not name it).
+// Redirects are not followed: the WAF is fingerprinted on the host asked for.
+func runHttpx(hosts []string, s *scope.Scope) ([]string, map[string]string) {
+	fmt.Printf("[*] Probing %d hosts with httpx...\n", len(hosts))
+
+	var mu sync.Mutex
+	seen := map[string]bool{}
+	cdns := map[string]string{}
+	var bases []string
+
+	hxOptions := &httpx_runner.Options{
+		InputTargetHost:    goflags.StringSlice(hosts),
+		Silent:             true,
+		DisableStdout:      true,
+		Threads:            50,
+		Timeout:            10,
+		DisableUpdateCheck: true,
+		DisableStdin:       true,
+		NoColor:            true,
+		RateLimit:          150,
+		Retries:            0,
+		HostMaxErrors:      30,
+		RandomAgent:        true,
+		OutputCDN:          "true",
+		OnResult: func(r httpx_runner.Result) {
+			if r.Err != nil {
+				return
+			}
+			u, err := url.Parse(r.URL)
+			if err != nil || !s.IsInScope(u.Hostname()) {
+				return
+			}
+			base := u.Scheme + "://" + u.Host
+			mu.Lock()
+			if !seen[base] {
+				seen[base] = true
+				bases = append(bases, base)
+				if r.CDN {
+					cdns[base] = "yes"
+					if r.CDNName != "" {
+						cdns[base] = r.CDNName
+					}
+				}
+			}
+			mu.Unlock()
+		},
+	}
+	if err := hxOptions.ValidateOptions(); err != nil {
+		fmt.Printf("[!] httpx options error: %s\n", err)
+		return nil, cdns
+	}
+	hxRunner, err := httpx_runner.New(hxOptions)
+	if err != nil {
+		fmt.Printf("[!] Could not create httpx runner: %s\n", err)
+		return nil, cdns
+	}
+	hxRunner.RunEnumeration()
+	hxRunner.Close()
+
+	sort.Strings(bases)
+	fmt.Printf("[+] %d live hosts\n", len(bases))
+	return bases, cdns
+}
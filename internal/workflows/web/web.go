@@ -10,6 +10,7 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"net/http/httputil"
 	"net/url"
 	"os"
 	"sort"
@@ -18,11 +19,17 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/FOUEN/narmol/internal/auth"
+	"github.com/FOUEN/narmol/internal/evidence"
+	"github.com/FOUEN/narmol/internal/graphql"
+	"github.com/FOUEN/narmol/internal/params"
 	"github.com/FOUEN/narmol/internal/scope"
+	"github.com/FOUEN/narmol/internal/waf"
 	"github.com/FOUEN/narmol/internal/workflows"
 	"github.com/FOUEN/narmol/internal/workflows/secrets"
 
 	"github.com/projectdiscovery/goflags"
+	"github.com/projectdiscovery/httpx/common/customheader"
 	httpx_runner "github.com/projectdiscovery/httpx/runner"
 	nuclei "github.com/projectdiscovery/nuclei/v3/lib"
 	"github.com/projectdiscovery/nuclei/v3/pkg/installer"
@@ -60,12 +67,27 @@ func (w *WebWorkflow) Run(domain string, s *scope.Scope, opts workflows.OutputOp
 		Date:   time.Now().UTC().Format(time.RFC3339),
 	}
 
+	store, err := evidence.NewStore(opts.EvidenceDir, opts.EvidenceMaxBytes)
+	if err != nil {
+		return err
+	}
+
+	sess, err := auth.New(opts.Auth, domain, s)
+	if err != nil {
+		return err
+	}
+	if sess != nil {
+		fmt.Printf("[+] Authenticated session: %s\n", sess)
+		evidence.RedactHeaders(sess.HeaderNames()...)
+	}
+
 	seen := &sync.Map{}
 	collect := func(r webResult) bool {
 		key := r.Phase + ":" + r.Value
 		if _, loaded := seen.LoadOrStore(key, true); loaded {
 			return false
 		}
+		r.Evidence = store.Save(r.Phase, r.Value, r.Detail+r.TemplateID, r.ev)
 		report.add(r)
 		return true
 	}
@@ -81,7 +103,7 @@ func (w *WebWorkflow) Run(domain string, s *scope.Scope, opts workflows.OutputOp
 	fmt.Printf("[+] %d hosts to probe\n", len(hosts))
 
 	// ── Step 2: httpx — probe + fingerprint ───────────────────────────
-	liveHosts, techSet := w.runHttpx(hosts, s, collect)
+	liveHosts, techSet := w.runHttpx(hosts, s, sess, collect)
 
 	if len(liveHosts) == 0 {
 		fmt.Println("[!] No live hosts found — stopping workflow")
@@ -89,31 +111,50 @@ func (w *WebWorkflow) Run(domain string, s *scope.Scope, opts workflows.OutputOp
 	}
 	fmt.Printf("[+] %d live hosts found, %d unique technologies detected\n", len(liveHosts), len(techSet))
 
+	// ── Step 2b: WAF fingerprint ──────────────────────────────────────
+	wafs := w.runWAFDetection(liveHosts, collect)
+	if len(wafs) > 0 && !opts.WAFBackoff {
+		fmt.Println("[!] Hosts behind a WAF are scanned at full rate — use --waf-backoff to slow down")
+	}
+
 	// ── Step 3: nuclei + trufflehog + security checks IN PARALLEL ─────
 	tags := buildNucleiTags(techSet)
 	fmt.Printf("[+] Nuclei tags from fingerprint: %s\n", strings.Join(tags, ", "))
 
 	var wg sync.WaitGroup
 
-	// 3a. Nuclei — targeted vulnerability scan
+	// 3a. Nuclei — targeted vulnerability scan (WAF-protected hosts apart
+	// when backing off, session hosts apart with the session headers)
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		w.runNuclei(liveHosts, tags, collect)
+		authed, other := sess.Split(liveHosts)
+		for _, group := range []struct {
+			targets []string
+			headers []string
+		}{{other, nil}, {authed, sess.HeaderLines()}} {
+			if !opts.WAFBackoff || len(wafs) == 0 {
+				w.runNuclei(group.targets, tags, false, group.headers, collect)
+				continue
+			}
+			protected, open := waf.Split(group.targets, wafs)
+			w.runNuclei(open, tags, false, group.headers, collect)
+			w.runNuclei(protected, tags, true, group.headers, collect)
+		}
 	}()
 
 	// 3b. TruffleHog — check for exposed .git repos and scan for secrets
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		w.runGitExposureCheck(liveHosts, collect)
+		w.runGitExposureCheck(liveHosts, sess, collect)
 	}()
 
 	// 3c. Security header checks — CORS, missing headers, cookies (stdlib)
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		w.runSecurityHeaderChecks(liveHosts, collect)
+		w.runSecurityHeaderChecks(liveHosts, sess, collect)
 	}()
 
 	// 3d. SSL/TLS configuration checks (stdlib crypto/tls)
@@ -127,7 +168,7 @@ func (w *WebWorkflow) Run(domain string, s *scope.Scope, opts workflows.OutputOp
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		w.runOpenRedirectChecks(liveHosts, collect)
+		w.runOpenRedirectChecks(liveHosts, sess, collect)
 	}()
 
 	// 3f. HTTP request smuggling detection (stdlib net)
@@ -137,6 +178,13 @@ func (w *WebWorkflow) Run(domain string, s *scope.Scope, opts workflows.OutputOp
 		w.runSmugglingChecks(liveHosts, collect)
 	}()
 
+	// 3g. GraphQL endpoint discovery + introspection audit (stdlib)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		w.runGraphQLChecks(liveHosts, opts.ArtifactDir, sess, collect)
+	}()
+
 	wg.Wait()
 
 	// ── Generate & write report ───────────────────────────────────────
@@ -190,12 +238,32 @@ func (w *WebWorkflow) runSubfinder(domain string, s *scope.Scope) []string {
 
 // ─── Step 2: httpx ──────────────────────────────────────────────────────
 
-func (w *WebWorkflow) runHttpx(hosts []string, s *scope.Scope, emitUnique func(webResult) bool) ([]string, map[string]struct{}) {
+func (w *WebWorkflow) runHttpx(hosts []string, s *scope.Scope, sess *auth.Session, emitUnique func(webResult) bool) ([]string, map[string]struct{}) {
+	// Session hosts are probed apart so only they get the session headers.
+	authed, other := sess.Split(hosts)
+	liveHosts, techSet := w.probeHttpx(other, nil, emitUnique)
+	if len(authed) > 0 {
+		fmt.Printf("[*] Probing %d session hosts with credentials...\n", len(authed))
+		more, moreTech := w.probeHttpx(authed, sess.HeaderLines(), emitUnique)
+		liveHosts = append(liveHosts, more...)
+		for t := range moreTech {
+			techSet[t] = struct{}{}
+		}
+	}
+	return liveHosts, techSet
+}
+
+// probeHttpx runs httpx on hosts with optional custom headers. With
+// headers, redirects are only followed on the same host.
+func (w *WebWorkflow) probeHttpx(hosts []string, headers []string, emitUnique func(webResult) bool) ([]string, map[string]struct{}) {
+	techSet := make(map[string]struct{})
+	if len(hosts) == 0 {
+		return nil, techSet
+	}
 	fmt.Printf("[*] Probing %d hosts with httpx...\n", len(hosts))
 
 	var mu sync.Mutex
 	var liveHosts []string
-	techSet := make(map[string]struct{})
 
 	hxOptions := &httpx_runner.Options{
 		InputTargetHost:    goflags.StringSlice(hosts),
@@ -215,6 +283,7 @@ func (w *WebWorkflow) runHttpx(hosts []string, s *scope.Scope, emitUnique func(w
 		TechDetect:         true,
 		OutputCDN:          "true",
 		ExtractTitle:       true,
+		CustomHeaders:      customheader.CustomHeaders(headers),
 		OnResult: func(r httpx_runner.Result) {
 			if r.Err != nil {
 				return
@@ -230,6 +299,7 @@ func (w *WebWorkflow) runHttpx(hosts []string, s *scope.Scope, emitUnique func(w
 				Webserver:  r.WebServer,
 				CDN:        r.CDN,
 				CDNName:    r.CDNName,
+				IP:         r.HostIP,
 			})
 
 			mu.Lock()
@@ -246,6 +316,11 @@ func (w *WebWorkflow) runHttpx(hosts []string, s *scope.Scope, emitUnique func(w
 		},
 	}
 
+	if len(headers) > 0 {
+		hxOptions.FollowRedirects = false
+		hxOptions.FollowHostRedirects = true
+	}
+
 	if err := hxOptions.ValidateOptions(); err != nil {
 		fmt.Printf("[!] httpx options error: %s\n", err)
 		return nil, techSet
@@ -263,10 +338,37 @@ func (w *WebWorkflow) runHttpx(hosts []string, s *scope.Scope, emitUnique func(w
 	return liveHosts, techSet
 }
 
+// ─── Step 2b: WAF fingerprint ───────────────────────────────────────────
+
+// runWAFDetection fingerprints the WAF in front of each live host and
+// records it as a "waf" discovery entry (read by the run report).
+func (w *WebWorkflow) runWAFDetection(liveHosts []string, emitUnique func(webResult) bool) map[string]waf.Result {
+	fmt.Printf("[*] Fingerprinting WAFs on %d hosts...\n", len(liveHosts))
+	found := waf.DetectAll(liveHosts, 20)
+	for _, h := range liveHosts {
+		if r, ok := found[h]; ok {
+			emitUnique(webResult{Phase: "waf", Value: h, WAF: r.Name, Detail: r.String()})
+		}
+	}
+	fmt.Printf("[+] %d hosts behind a WAF\n", len(found))
+	return found
+}
+
 // ─── Step 3: Nuclei (targeted by fingerprint) ───────────────────────────
 
-func (w *WebWorkflow) runNuclei(targets []string, tags []string, emitUnique func(webResult) bool) int64 {
-	fmt.Printf("[*] Scanning %d targets with nuclei (%d tech tags)...\n", len(targets), len(tags))
+// runNuclei scans targets with the fingerprint tags. With backoff (hosts
+// behind a WAF) noisy tags are excluded and requests are rate limited;
+// headers (the session) are sent with every request.
+func (w *WebWorkflow) runNuclei(targets []string, tags []string, backoff bool, headers []string, emitUnique func(webResult) bool) int64 {
+	if len(targets) == 0 {
+		return 0
+	}
+	if backoff {
+		fmt.Printf("[*] Scanning %d WAF-protected targets with nuclei (%d req/s, without %s)...\n",
+			len(targets), waf.BackoffRateLimit, strings.Join(waf.NoisyTags, ","))
+	} else {
+		fmt.Printf("[*] Scanning %d targets with nuclei (%d tech tags)...\n", len(targets), len(tags))
+	}
 
 	var vulnCount int64
 	ctx := context.Background()
@@ -278,11 +380,11 @@ func (w *WebWorkflow) runNuclei(targets []string, tags []string, emitUnique func
 		return 0
 	}
 
-	ne, err := nuclei.NewNucleiEngineCtx(ctx,
-		nuclei.WithTemplateFilters(nuclei.TemplateFilters{
-			Severity: "medium,high,critical",
-			Tags:     tags,
-		}),
+	filters := nuclei.TemplateFilters{
+		Severity: "medium,high,critical",
+		Tags:     tags,
+	}
+	nucleiOpts := []nuclei.NucleiSDKOptions{
 		nuclei.WithConcurrency(nuclei.Concurrency{
 			TemplateConcurrency:           25,
 			HostConcurrency:               25,
@@ -294,7 +396,15 @@ func (w *WebWorkflow) runNuclei(targets []string, tags []string, emitUnique func
 		}),
 		nuclei.WithVerbosity(nuclei.VerbosityOptions{Silent: true}),
 		nuclei.DisableUpdateCheck(),
-	)
+	}
+	if backoff {
+		filters.ExcludeTags = waf.NoisyTags
+		nucleiOpts = append(nucleiOpts, nuclei.WithGlobalRateLimit(waf.BackoffRateLimit, time.Second))
+	}
+	if len(headers) > 0 {
+		nucleiOpts = append(nucleiOpts, nuclei.WithHeaders(headers))
+	}
+	ne, err := nuclei.NewNucleiEngineCtx(ctx, append(nucleiOpts, nuclei.WithTemplateFilters(filters))...)
 	if err != nil {
 		fmt.Printf("[!] Could not create nuclei engine: %s\n", err)
 		return 0
@@ -318,7 +428,8 @@ func (w *WebWorkflow) runNuclei(targets []string, tags []string, emitUnique func
 			VulnName:   event.Info.Name,
 			Severity:   severity,
 			VulnType:   event.Type,
-		})
+			ev:         nucleiEvidence(event),
+		}.withClassification(event))
 		atomic.AddInt64(&vulnCount, 1)
 	}); err != nil {
 		fmt.Printf("[!] Nuclei scan error: %s\n", err)
@@ -328,6 +439,29 @@ func (w *WebWorkflow) runNuclei(targets []string, tags []string, emitUnique func
 	return atomic.LoadInt64(&vulnCount)
 }
 
+// nucleiEvidence returns the raw request/response nuclei recorded for a match.
+func nucleiEvidence(event *nuclei_output.ResultEvent) *evidence.Record {
+	if event.Request == "" && event.Response == "" {
+		return nil
+	}
+	return evidence.Raw(event.Request, event.Response)
+}
+
+// withClassification copies the CVE/CVSS/EPSS data of the matched template,
+// used by the run report to score risk.
+func (r webResult) withClassification(event *nuclei_output.ResultEvent) webResult {
+	c := event.Info.Classification
+	if c == nil {
+		return r
+	}
+	if ids := c.CVEID.ToSlice(); len(ids) > 0 {
+		r.CVE = strings.Join(ids, ",")
+	}
+	r.CVSS = c.CVSSScore
+	r.EPSS = c.EPSSScore
+	return r
+}
+
 // ─── Result types ───────────────────────────────────────────────────────
 
 // webResult is the unified result type for all phases of the web workflow.
@@ -341,11 +475,20 @@ type webResult struct {
 	Webserver  string   `json:"webserver,omitempty"`   // server header
 	CDN        bool     `json:"cdn,omitempty"`         // behind CDN
 	CDNName    string   `json:"cdn_name,omitempty"`    // CDN name
+	WAF        string   `json:"waf,omitempty"`         // WAF name (waf phase)
 	TemplateID string   `json:"template_id,omitempty"` // nuclei template
 	VulnName   string   `json:"vuln_name,omitempty"`   // vulnerability name
 	Severity   string   `json:"severity,omitempty"`    // low/medium/high/critical
 	VulnType   string   `json:"vuln_type,omitempty"`   // http/dns/network/etc
 	Detail     string   `json:"detail,omitempty"`      // extra detail for header/secret findings
+	IP         string   `json:"ip,omitempty"`          // resolved address (probes)
+	CVE        string   `json:"cve,omitempty"`         // nuclei classification
+	CVSS       float64  `json:"cvss,omitempty"`        // nuclei classification CVSS score
+	EPSS       float64  `json:"epss,omitempty"`        // nuclei classification EPSS score
+	Evidence   string   `json:"evidence,omitempty"`    // path to raw request/response proof
+	Schema     string   `json:"schema,omitempty"`      // path to the dumped GraphQL schema
+
+	ev *evidence.Record // captured by the check, persisted by the collector
 }
 
 func (r webResult) summary() string {
@@ -360,6 +503,8 @@ func (r webResult) summary() string {
 			title = " - " + r.Title
 		}
 		return fmt.Sprintf("[LIVE] %s (%d)%s%s", r.Value, r.StatusCode, title, tech)
+	case "waf":
+		return fmt.Sprintf("[WAF] %s — %s", r.Value, r.Detail)
 	case "vuln":
 		return fmt.Sprintf("[%s] %s — %s (%s)", strings.ToUpper(r.Severity), r.Value, r.VulnName, r.TemplateID)
 	case "secret":
@@ -372,6 +517,8 @@ func (r webResult) summary() string {
 		return fmt.Sprintf("[REDIRECT] %s — %s", r.Value, r.Detail)
 	case "smuggling":
 		return fmt.Sprintf("[SMUGGLING-%s] %s — %s", strings.ToUpper(r.Severity), r.Value, r.Detail)
+	case "graphql":
+		return fmt.Sprintf("[GRAPHQL-%s] %s — %s", strings.ToUpper(r.Severity), r.Value, r.Detail)
 	default:
 		return r.Value
 	}
@@ -405,13 +552,14 @@ type webReport struct {
 	TLS             []webResult
 	Redirects       []webResult
 	Smuggling       []webResult
+	GraphQL         []webResult
 }
 
 func (rpt *webReport) add(r webResult) {
 	rpt.mu.Lock()
 	defer rpt.mu.Unlock()
 	switch r.Phase {
-	case "probe":
+	case "probe", "waf":
 		rpt.Probes = append(rpt.Probes, r)
 	case "vuln":
 		rpt.Vulns = append(rpt.Vulns, r)
@@ -425,6 +573,8 @@ func (rpt *webReport) add(r webResult) {
 		rpt.Redirects = append(rpt.Redirects, r)
 	case "smuggling":
 		rpt.Smuggling = append(rpt.Smuggling, r)
+	case "graphql":
+		rpt.GraphQL = append(rpt.GraphQL, r)
 	}
 }
 
@@ -475,6 +625,7 @@ type reportSummary struct {
 	TLSIssues       int `json:"tls_issues"`
 	Redirects       int `json:"redirects"`
 	Smuggling       int `json:"smuggling"`
+	GraphQL         int `json:"graphql"`
 }
 
 type reportPhases struct {
@@ -485,6 +636,7 @@ type reportPhases struct {
 	TLS             []webResult `json:"tls_issues"`
 	Redirects       []webResult `json:"redirects"`
 	Smuggling       []webResult `json:"smuggling"`
+	GraphQL         []webResult `json:"graphql"`
 }
 
 func (rpt *webReport) jsonData() reportJSON {
@@ -507,6 +659,7 @@ func (rpt *webReport) jsonData() reportJSON {
 			TLSIssues:       len(rpt.TLS),
 			Redirects:       len(rpt.Redirects),
 			Smuggling:       len(rpt.Smuggling),
+			GraphQL:         len(rpt.GraphQL),
 		},
 		Phases: reportPhases{
 			Discovery:       ensureSlice(rpt.Probes),
@@ -516,6 +669,7 @@ func (rpt *webReport) jsonData() reportJSON {
 			TLS:             ensureSlice(rpt.TLS),
 			Redirects:       ensureSlice(rpt.Redirects),
 			Smuggling:       ensureSlice(rpt.Smuggling),
+			GraphQL:         ensureSlice(rpt.GraphQL),
 		},
 	}
 }
@@ -619,6 +773,18 @@ func (rpt *webReport) formatText() string {
 		}
 	}
 
+	// ── Phase 8: GraphQL ──
+	b.WriteString("\n" + line + "\n")
+	b.WriteString("  8. GRAPHQL\n")
+	b.WriteString(line + "\n")
+	if len(rpt.GraphQL) == 0 {
+		b.WriteString("  No GraphQL issues found.\n")
+	} else {
+		for _, r := range rpt.GraphQL {
+			b.WriteString("  " + r.summary() + "\n")
+		}
+	}
+
 	// ── Summary ──
 	b.WriteString("\n" + doubleLine + "\n")
 	b.WriteString("  SUMMARY\n")
@@ -631,6 +797,7 @@ func (rpt *webReport) formatText() string {
 	b.WriteString(fmt.Sprintf("  TLS Issues:      %d\n", len(rpt.TLS)))
 	b.WriteString(fmt.Sprintf("  Redirects:       %d\n", len(rpt.Redirects)))
 	b.WriteString(fmt.Sprintf("  Smuggling:       %d\n", len(rpt.Smuggling)))
+	b.WriteString(fmt.Sprintf("  GraphQL:         %d\n", len(rpt.GraphQL)))
 	b.WriteString(doubleLine + "\n")
 
 	return b.String()
@@ -784,7 +951,7 @@ func buildNucleiTags(techSet map[string]struct{}) []string {
 
 // runGitExposureCheck checks each live host for exposed .git/HEAD.
 // If found, runs TruffleHog to scan for leaked secrets in the exposed repo.
-func (w *WebWorkflow) runGitExposureCheck(liveHosts []string, emitUnique func(webResult) bool) int64 {
+func (w *WebWorkflow) runGitExposureCheck(liveHosts []string, sess *auth.Session, emitUnique func(webResult) bool) int64 {
 	fmt.Printf("[*] Checking %d hosts for .git exposure...\n", len(liveHosts))
 
 	client := &http.Client{
@@ -801,6 +968,7 @@ func (w *WebWorkflow) runGitExposureCheck(liveHosts []string, emitUnique func(we
 			return http.ErrUseLastResponse // don't follow redirects
 		},
 	}
+	client.Transport = sess.Transport(client.Transport)
 
 	var count int64
 	var wg sync.WaitGroup
@@ -831,6 +999,7 @@ func (w *WebWorkflow) runGitExposureCheck(liveHosts []string, emitUnique func(we
 					Value:    h,
 					Severity: "high",
 					Detail:   ".git repository exposed — scanning for secrets",
+					ev:       evidence.HTTP(resp.Request, resp, buf[:n]),
 				})
 
 				// Run TruffleHog on the exposed git repo
@@ -875,7 +1044,7 @@ var requiredHeaders = []struct {
 
 // runSecurityHeaderChecks performs fast HTTP requests to check for missing security
 // headers, CORS misconfigurations, and insecure cookies. Pure stdlib, no external tools.
-func (w *WebWorkflow) runSecurityHeaderChecks(liveHosts []string, emitUnique func(webResult) bool) int64 {
+func (w *WebWorkflow) runSecurityHeaderChecks(liveHosts []string, sess *auth.Session, emitUnique func(webResult) bool) int64 {
 	fmt.Printf("[*] Checking security headers on %d hosts...\n", len(liveHosts))
 
 	client := &http.Client{
@@ -889,6 +1058,7 @@ func (w *WebWorkflow) runSecurityHeaderChecks(liveHosts []string, emitUnique fun
 			}).DialContext,
 		},
 	}
+	client.Transport = sess.Transport(client.Transport)
 
 	var count int64
 	var wg sync.WaitGroup
@@ -913,6 +1083,7 @@ func (w *WebWorkflow) runSecurityHeaderChecks(liveHosts []string, emitUnique fun
 				return
 			}
 			defer resp.Body.Close()
+			ev := evidence.HTTP(req, resp, nil)
 
 			// Check missing security headers
 			for _, hdr := range requiredHeaders {
@@ -922,6 +1093,7 @@ func (w *WebWorkflow) runSecurityHeaderChecks(liveHosts []string, emitUnique fun
 						Value:    h,
 						Severity: hdr.Severity,
 						Detail:   "Missing " + hdr.Name,
+						ev:       ev,
 					}) {
 						atomic.AddInt64(&count, 1)
 					}
@@ -936,6 +1108,7 @@ func (w *WebWorkflow) runSecurityHeaderChecks(liveHosts []string, emitUnique fun
 					Value:    h,
 					Severity: "high",
 					Detail:   fmt.Sprintf("CORS misconfiguration: Access-Control-Allow-Origin: %s", acao),
+					ev:       ev,
 				}) {
 					atomic.AddInt64(&count, 1)
 				}
@@ -949,6 +1122,7 @@ func (w *WebWorkflow) runSecurityHeaderChecks(liveHosts []string, emitUnique fun
 					Value:    h,
 					Severity: "critical",
 					Detail:   "CORS with credentials: origin reflected + Allow-Credentials: true",
+					ev:       ev,
 				}) {
 					atomic.AddInt64(&count, 1)
 				}
@@ -972,6 +1146,7 @@ func (w *WebWorkflow) runSecurityHeaderChecks(liveHosts []string, emitUnique fun
 						Value:    h,
 						Severity: "low",
 						Detail:   fmt.Sprintf("Cookie '%s': %s", cookie.Name, strings.Join(issues, ", ")),
+						ev:       ev,
 					}) {
 						atomic.AddInt64(&count, 1)
 					}
@@ -1052,6 +1227,7 @@ func (w *WebWorkflow) runTLSChecks(liveHosts []string, emitUnique func(webResult
 			defer conn.Close()
 
 			state := conn.ConnectionState()
+			ev := evidence.TLS(addr, state)
 
 			// Check protocol version
 			switch state.Version {
@@ -1059,6 +1235,7 @@ func (w *WebWorkflow) runTLSChecks(liveHosts []string, emitUnique func(webResult
 				if emitUnique(webResult{
 					Phase: "tls", Value: h, Severity: "high",
 					Detail: "TLS 1.0 supported (deprecated, vulnerable to BEAST/POODLE)",
+					ev:     ev,
 				}) {
 					atomic.AddInt64(&count, 1)
 				}
@@ -1066,6 +1243,7 @@ func (w *WebWorkflow) runTLSChecks(liveHosts []string, emitUnique func(webResult
 				if emitUnique(webResult{
 					Phase: "tls", Value: h, Severity: "medium",
 					Detail: "TLS 1.1 supported (deprecated)",
+					ev:     ev,
 				}) {
 					atomic.AddInt64(&count, 1)
 				}
@@ -1076,6 +1254,7 @@ func (w *WebWorkflow) runTLSChecks(liveHosts []string, emitUnique func(webResult
 				if emitUnique(webResult{
 					Phase: "tls", Value: h, Severity: "high",
 					Detail: fmt.Sprintf("Weak cipher suite: %s", name),
+					ev:     ev,
 				}) {
 					atomic.AddInt64(&count, 1)
 				}
@@ -1091,6 +1270,7 @@ func (w *WebWorkflow) runTLSChecks(liveHosts []string, emitUnique func(webResult
 					if emitUnique(webResult{
 						Phase: "tls", Value: h, Severity: "high",
 						Detail: fmt.Sprintf("Certificate expired: %s", cert.NotAfter.Format("2006-01-02")),
+						ev:     ev,
 					}) {
 						atomic.AddInt64(&count, 1)
 					}
@@ -1101,6 +1281,7 @@ func (w *WebWorkflow) runTLSChecks(liveHosts []string, emitUnique func(webResult
 					if emitUnique(webResult{
 						Phase: "tls", Value: h, Severity: "medium",
 						Detail: fmt.Sprintf("Certificate expiring soon: %s", cert.NotAfter.Format("2006-01-02")),
+						ev:     ev,
 					}) {
 						atomic.AddInt64(&count, 1)
 					}
@@ -1115,6 +1296,7 @@ func (w *WebWorkflow) runTLSChecks(liveHosts []string, emitUnique func(webResult
 						if emitUnique(webResult{
 							Phase: "tls", Value: h, Severity: "medium",
 							Detail: "Self-signed certificate",
+							ev:     ev,
 						}) {
 							atomic.AddInt64(&count, 1)
 						}
@@ -1126,6 +1308,7 @@ func (w *WebWorkflow) runTLSChecks(liveHosts []string, emitUnique func(webResult
 					if emitUnique(webResult{
 						Phase: "tls", Value: h, Severity: "high",
 						Detail: fmt.Sprintf("Certificate hostname mismatch: cert for %s", strings.Join(cert.DNSNames, ", ")),
+						ev:     ev,
 					}) {
 						atomic.AddInt64(&count, 1)
 					}
@@ -1149,8 +1332,13 @@ var openRedirectParams = []string{
 	"continue", "forward", "out", "view", "login_url", "callback",
 }
 
-// runOpenRedirectChecks tests each live host for basic open redirect via common parameters.
-func (w *WebWorkflow) runOpenRedirectChecks(liveHosts []string, emitUnique func(webResult) bool) int64 {
+// maxRedirectProbes caps the parameters tried per host.
+const maxRedirectProbes = 40
+
+// runOpenRedirectChecks tests each live host for open redirect: the common
+// parameters on the root path, then the redirect-like parameters of the
+// root page's forms and links (see internal/params).
+func (w *WebWorkflow) runOpenRedirectChecks(liveHosts []string, sess *auth.Session, emitUnique func(webResult) bool) int64 {
 	fmt.Printf("[*] Checking %d hosts for open redirects...\n", len(liveHosts))
 
 	client := &http.Client{
@@ -1165,6 +1353,7 @@ func (w *WebWorkflow) runOpenRedirectChecks(liveHosts []string, emitUnique func(
 			return http.ErrUseLastResponse // don't follow — we inspect the Location header
 		},
 	}
+	client.Transport = sess.Transport(client.Transport)
 
 	var count int64
 	var wg sync.WaitGroup
@@ -1179,10 +1368,16 @@ func (w *WebWorkflow) runOpenRedirectChecks(liveHosts []string, emitUnique func(
 			sem <- struct{}{}
 			defer func() { <-sem }()
 
-			for _, param := range openRedirectParams {
-				testURL := fmt.Sprintf("%s/?%s=%s", strings.TrimRight(h, "/"), param, url.QueryEscape(canary))
+			inv := params.NewInventory()
+			root := strings.TrimRight(h, "/") + "/"
+			if resp, err := client.Get(root); err == nil {
+				body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024*1024))
+				resp.Body.Close()
+				inv.AddPage(root, body)
+			}
 
-				resp, err := client.Get(testURL)
+			for _, t := range inv.Probes(params.ClassRedirect, h, openRedirectParams, maxRedirectProbes) {
+				resp, err := client.Get(t.With(canary))
 				if err != nil {
 					continue
 				}
@@ -1193,7 +1388,8 @@ func (w *WebWorkflow) runOpenRedirectChecks(liveHosts []string, emitUnique func(
 					if strings.HasPrefix(location, "https://evil.com") || strings.HasPrefix(location, "//evil.com") {
 						if emitUnique(webResult{
 							Phase: "redirect", Value: h, Severity: "medium",
-							Detail: fmt.Sprintf("Open redirect via ?%s= → %s (HTTP %d)", param, location, resp.StatusCode),
+							Detail: fmt.Sprintf("Open redirect via %s?%s= → %s (HTTP %d)", t.URL, t.Param, location, resp.StatusCode),
+							ev:     evidence.HTTP(resp.Request, resp, []byte{}), // the Location header is the proof
 						}) {
 							atomic.AddInt64(&count, 1)
 						}
@@ -1210,6 +1406,75 @@ func (w *WebWorkflow) runOpenRedirectChecks(liveHosts []string, emitUnique func(
 	return total
 }
 
+// ─── GraphQL ────────────────────────────────────────────────────────────
+
+// runGraphQLChecks looks for a GraphQL endpoint on each live host and audits
+// it (see internal/graphql). Introspection schemas are written to schemaDir
+// when set.
+func (w *WebWorkflow) runGraphQLChecks(liveHosts []string, schemaDir string, sess *auth.Session, emitUnique func(webResult) bool) int64 {
+	fmt.Printf("[*] Looking for GraphQL endpoints on %d hosts...\n", len(liveHosts))
+
+	client := &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			DialContext:     (&net.Dialer{Timeout: 3 * time.Second}).DialContext,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	client.Transport = sess.Transport(client.Transport)
+
+	var count, endpoints int64
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, 20)
+
+	for _, host := range liveHosts {
+		wg.Add(1)
+		go func(h string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			endpoint := graphql.Discover(client, h)
+			if endpoint == "" {
+				return
+			}
+			atomic.AddInt64(&endpoints, 1)
+			audit := graphql.Run(client, endpoint)
+
+			schema := ""
+			if audit.Schema != nil && schemaDir != "" {
+				path, err := graphql.SaveSchema(schemaDir, endpoint, audit.Schema)
+				if err != nil {
+					fmt.Printf("[!] Could not write GraphQL schema for %s: %s\n", endpoint, err)
+				} else {
+					schema = path
+				}
+			}
+			for _, issue := range audit.Issues {
+				r := webResult{
+					Phase: "graphql", Value: endpoint + "#" + issue.Check, Severity: issue.Severity,
+					Detail: issue.Detail,
+					ev:     issue.Evidence,
+				}
+				if issue.Check == graphql.CheckIntrospection {
+					r.Schema = schema
+				}
+				if emitUnique(r) {
+					atomic.AddInt64(&count, 1)
+				}
+			}
+		}(host)
+	}
+
+	wg.Wait()
+	total := atomic.LoadInt64(&count)
+	fmt.Printf("[+] GraphQL checks done — %d endpoints, %d issues found\n", endpoints, total)
+	return total
+}
+
 // ─── HTTP Request Smuggling Detection ───────────────────────────────────
 
 // runSmugglingChecks performs CL.TE and TE.CL detection using raw TCP sockets.
@@ -1275,20 +1540,22 @@ func (w *WebWorkflow) runSmugglingChecks(liveHosts []string, emitUnique func(web
 				hostname)
 
 			// Test CL.TE
-			if w.testSmuggling(addr, isHTTPS, hostname, cltePayload) {
+			if ev := w.testSmuggling(addr, isHTTPS, hostname, cltePayload); ev != nil {
 				if emitUnique(webResult{
 					Phase: "smuggling", Value: h, Severity: "critical",
 					Detail: "Potential CL.TE HTTP request smuggling",
+					ev:     ev,
 				}) {
 					atomic.AddInt64(&count, 1)
 				}
 			}
 
 			// Test TE.CL
-			if w.testSmuggling(addr, isHTTPS, hostname, teclPayload) {
+			if ev := w.testSmuggling(addr, isHTTPS, hostname, teclPayload); ev != nil {
 				if emitUnique(webResult{
 					Phase: "smuggling", Value: h, Severity: "critical",
 					Detail: "Potential TE.CL HTTP request smuggling",
+					ev:     ev,
 				}) {
 					atomic.AddInt64(&count, 1)
 				}
@@ -1303,8 +1570,9 @@ func (w *WebWorkflow) runSmugglingChecks(liveHosts []string, emitUnique func(web
 }
 
 // testSmuggling sends a raw HTTP payload and checks for anomalous response behavior.
-// Returns true if the response suggests smuggling vulnerability.
-func (w *WebWorkflow) testSmuggling(addr string, isHTTPS bool, hostname, payload string) bool {
+// Returns the payload and both responses as evidence if the response suggests
+// smuggling vulnerability, nil otherwise.
+func (w *WebWorkflow) testSmuggling(addr string, isHTTPS bool, hostname, payload string) *evidence.Record {
 	dialer := &net.Dialer{Timeout: 5 * time.Second}
 
 	var conn net.Conn
@@ -1319,7 +1587,7 @@ func (w *WebWorkflow) testSmuggling(addr string, isHTTPS bool, hostname, payload
 		conn, err = dialer.Dial("tcp", addr)
 	}
 	if err != nil {
-		return false
+		return nil
 	}
 	defer conn.Close()
 
@@ -1329,7 +1597,7 @@ func (w *WebWorkflow) testSmuggling(addr string, isHTTPS bool, hostname, payload
 	// Send the smuggling payload
 	_, err = conn.Write([]byte(payload))
 	if err != nil {
-		return false
+		return nil
 	}
 
 	// Read response — look for anomalies
@@ -1338,8 +1606,9 @@ func (w *WebWorkflow) testSmuggling(addr string, isHTTPS bool, hostname, payload
 	// Read first response
 	resp1, err := http.ReadResponse(reader, nil)
 	if err != nil {
-		return false
+		return nil
 	}
+	dump1, _ := httputil.DumpResponse(resp1, false)
 	resp1.Body.Close()
 
 	// Try to read a second response (shouldn't exist in normal case).
@@ -1348,9 +1617,11 @@ func (w *WebWorkflow) testSmuggling(addr string, isHTTPS bool, hostname, payload
 	conn.SetDeadline(time.Now().Add(3 * time.Second))
 	resp2, err := http.ReadResponse(reader, nil)
 	if err == nil && resp2 != nil {
+		dump2, _ := httputil.DumpResponse(resp2, false)
 		resp2.Body.Close()
-		return true // got a second response → smuggling likely
+		// got a second response → smuggling likely
+		return evidence.Raw(payload, string(dump1)+"\r\n--- second response on the same connection ---\r\n"+string(dump2))
 	}
 
-	return false
+	return nil
 }
@@ -7,16 +7,27 @@ import (
 	_ "github.com/FOUEN/narmol/internal/runner"
 	_ "github.com/FOUEN/narmol/internal/workflows/active"
 	_ "github.com/FOUEN/narmol/internal/workflows/alive"
+	_ "github.com/FOUEN/narmol/internal/workflows/apispec"
+	_ "github.com/FOUEN/narmol/internal/workflows/buckets"
 	_ "github.com/FOUEN/narmol/internal/workflows/crawl"
+	_ "github.com/FOUEN/narmol/internal/workflows/dnsaudit"
+	_ "github.com/FOUEN/narmol/internal/workflows/dnsbrute"
 	_ "github.com/FOUEN/narmol/internal/workflows/full"
+	_ "github.com/FOUEN/narmol/internal/workflows/fuzz"
 	_ "github.com/FOUEN/narmol/internal/workflows/gitexpose"
 	_ "github.com/FOUEN/narmol/internal/workflows/headers"
+	_ "github.com/FOUEN/narmol/internal/workflows/ipsweep"
+	_ "github.com/FOUEN/narmol/internal/workflows/jsanalyze"
+	_ "github.com/FOUEN/narmol/internal/workflows/params"
+	_ "github.com/FOUEN/narmol/internal/workflows/ports"
 	_ "github.com/FOUEN/narmol/internal/workflows/recon"
 	_ "github.com/FOUEN/narmol/internal/workflows/secrets"
 	_ "github.com/FOUEN/narmol/internal/workflows/subdomains"
 	_ "github.com/FOUEN/narmol/internal/workflows/takeover"
 	_ "github.com/FOUEN/narmol/internal/workflows/techdetect"
 	_ "github.com/FOUEN/narmol/internal/workflows/urls"
+	_ "github.com/FOUEN/narmol/internal/workflows/vhosts"
+	_ "github.com/FOUEN/narmol/internal/workflows/waf"
 	_ "github.com/FOUEN/narmol/internal/workflows/web"
 )
 